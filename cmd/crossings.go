@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/H3Cki/Plotrader/config"
+	"github.com/H3Cki/Plotrader/config/inboundcfg"
+	"github.com/H3Cki/Plotrader/core/domain/geometry"
+	"github.com/H3Cki/Plotrader/core/inbound"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+)
+
+var (
+	plotAProp = "a"
+	plotBProp = "b"
+	fromProp  = "from"
+	toProp    = "to"
+)
+
+var CrossingsCommand = &cli.Command{
+	Name:   "crossings",
+	Usage:  "find the points where two plots cross",
+	Action: runCrossingsCommand,
+	Flags: []cli.Flag{
+		&cli.StringFlag{Name: plotAProp, Usage: "first plot spec as json", Required: true},
+		&cli.StringFlag{Name: plotBProp, Usage: "second plot spec as json", Required: true},
+		&cli.StringFlag{Name: fromProp, Usage: "start of the search window", Required: true},
+		&cli.StringFlag{Name: toProp, Usage: "end of the search window", Required: true},
+	},
+}
+
+func runCrossingsCommand(ctx *cli.Context) error {
+	logger := ctx.App.Metadata["Logger"].(*zap.Logger)
+
+	appConfig := config.AppConfig{
+		AppName:    ctx.App.Name,
+		AppVersion: ctx.App.Version,
+		Env:        ctx.App.Metadata["Env"].(string),
+	}
+
+	app, err := config.NewApp(appConfig,
+		config.WithLogger(logger.Sugar()),
+		inboundcfg.WithPlotService,
+	)
+	if err != nil {
+		return errors.Wrap(err, "error creating app")
+	}
+
+	a := geometry.PlotSpec{}
+	if err := json.Unmarshal([]byte(ctx.String(plotAProp)), &a); err != nil {
+		return errors.Wrap(err, "error unmarshalling plot a")
+	}
+
+	b := geometry.PlotSpec{}
+	if err := json.Unmarshal([]byte(ctx.String(plotBProp)), &b); err != nil {
+		return errors.Wrap(err, "error unmarshalling plot b")
+	}
+
+	resp, err := app.PlotService.FindCrossings(ctx.Context, inbound.FindCrossingsRequest{
+		A:    a,
+		B:    b,
+		From: ctx.String(fromProp),
+		To:   ctx.String(toProp),
+	})
+	if err != nil {
+		return err
+	}
+
+	respBytes, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(ctx.App.Writer, string(respBytes))
+	return nil
+}
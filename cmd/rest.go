@@ -13,6 +13,8 @@ var (
 	addrProp   = "addr"
 	dbNameProp = "db-name"
 	dbURIProp  = "db-uri"
+	eiDirProp  = "exchange-info-dir"
+	riskProp   = "risk-config"
 )
 
 var RESTCommand = &cli.Command{
@@ -23,6 +25,8 @@ var RESTCommand = &cli.Command{
 		&cli.StringFlag{Name: addrProp, Usage: "http rest server listen addr", EnvVars: []string{"ADDR"}, Value: "0.0.0.0:8080"},
 		&cli.StringFlag{Name: dbNameProp, Usage: "name of the database", EnvVars: []string{"DB_NAME"}, Value: "plotrader.db"},
 		&cli.StringFlag{Name: dbURIProp, Usage: "uri of the database", EnvVars: []string{"DB_URI"}, Value: "localhost"},
+		&cli.StringFlag{Name: eiDirProp, Usage: "directory of the cached exchange infos", EnvVars: []string{"EXCHANGE_INFO_DIR"}, Value: "data/exchange_infos"},
+		&cli.StringFlag{Name: riskProp, Usage: "path of the json risk limits of the orders", EnvVars: []string{"RISK_CONFIG"}},
 	},
 }
 
@@ -46,11 +50,21 @@ func runRESTCommand(ctx *cli.Context) error {
 		URI:    ctx.String(dbURIProp),
 	}
 
+	exchangesCfg := outboundcfg.ExchangesConfig{
+		ExchangeInfoDir: ctx.String(eiDirProp),
+	}
+
+	followCfg := inboundcfg.FollowConfig{
+		RiskFile: ctx.String(riskProp),
+	}
+
 	app, err := config.NewApp(appConfig,
 		config.WithLogger(logger.Sugar()),
 		outboundcfg.WithWebhookPublisher,
 		outboundcfg.WithMongo(repoCfg),
-		inboundcfg.WithUpdaterService,
+		outboundcfg.WithExchanges(exchangesCfg),
+		inboundcfg.WithUpdaterService(followCfg),
+		inboundcfg.WithPlotService,
 		inboundcfg.WithREST(restCfg),
 	)
 	if err != nil {
@@ -17,10 +17,12 @@ type App struct {
 
 	// application
 	FollowService inbound.FollowService
+	PlotService   inbound.PlotService
 
 	// infrastructure
 	Publisher  outbound.Publisher
 	Repository outbound.Repository
+	Exchanges  outbound.ExchangeFactory
 
 	// presentation
 	HTTPServer http.Server
@@ -1,16 +1,48 @@
 package inboundcfg
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+
 	"github.com/H3Cki/Plotrader/config"
 	"github.com/H3Cki/Plotrader/core/application/followsvc"
+	"github.com/H3Cki/Plotrader/core/application/plotsvc"
 	"github.com/H3Cki/Plotrader/presentation/rest"
 )
 
-func WithUpdaterService(app *config.App) error {
-	app.FollowService = followsvc.New(followsvc.Config{
-		Logger:     app.Logger,
-		Publisher:  app.Publisher,
-		Repository: app.Repository,
+type FollowConfig struct {
+	// RiskFile is the path of the json risk config, orders aren't limited if empty
+	RiskFile string
+}
+
+func WithUpdaterService(cfg FollowConfig) config.Option {
+	return func(app *config.App) error {
+		risk := followsvc.RiskConfig{}
+		if cfg.RiskFile != "" {
+			data, err := os.ReadFile(cfg.RiskFile)
+			if err != nil {
+				return fmt.Errorf("error reading risk config: %w", err)
+			}
+			if err := json.Unmarshal(data, &risk); err != nil {
+				return fmt.Errorf("error unmarshaling risk config: %w", err)
+			}
+		}
+
+		app.FollowService = followsvc.New(followsvc.Config{
+			Logger:     app.Logger,
+			Publisher:  app.Publisher,
+			Repository: app.Repository,
+			Exchanges:  app.Exchanges,
+			Risk:       risk,
+		})
+		return nil
+	}
+}
+
+func WithPlotService(app *config.App) error {
+	app.PlotService = plotsvc.New(plotsvc.Config{
+		Logger: app.Logger,
 	})
 	return nil
 }
@@ -21,7 +53,7 @@ type RESTConfig struct {
 
 func WithREST(cfg RESTConfig) config.Option {
 	return func(app *config.App) error {
-		app.HTTPServer = rest.New(app.FollowService, cfg.Addr)
+		app.HTTPServer = rest.New(app.FollowService, app.PlotService, cfg.Addr)
 		return nil
 	}
 }
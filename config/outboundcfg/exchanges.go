@@ -0,0 +1,72 @@
+package outboundcfg
+
+import (
+	"encoding/json"
+
+	"github.com/H3Cki/Plotrader/config"
+	"github.com/H3Cki/Plotrader/core/outbound"
+	"github.com/H3Cki/Plotrader/infractructure/exchanges/binancefutures"
+	"github.com/H3Cki/Plotrader/infractructure/exchanges/binancespot"
+	"github.com/H3Cki/Plotrader/infractructure/exchanges/bybit"
+	"github.com/H3Cki/Plotrader/infractructure/exchanges/okx"
+	"github.com/H3Cki/Plotrader/infractructure/floader"
+	"github.com/go-playground/validator/v10"
+)
+
+var validate = validator.New()
+
+type ExchangesConfig struct {
+	// ExchangeInfoDir is the directory of the cached exchange infos, data/exchange_infos if empty
+	ExchangeInfoDir string
+}
+
+// WithExchanges registers the exchange adapters under the names used by follow requests
+func WithExchanges(cfg ExchangesConfig) config.Option {
+	return func(app *config.App) error {
+		dir := cfg.ExchangeInfoDir
+		if dir == "" {
+			dir = "data/exchange_infos"
+		}
+
+		registry := outbound.NewExchangeRegistry()
+		futuresPool := binancefutures.NewClientPool()
+
+		registerExchange(registry, "BINANCE_FUTURES", dir, func(ucfg binancefutures.UserConfig, eier outbound.FileLoader[binancefutures.ExchangeInfo]) outbound.Exchange {
+			return binancefutures.New(app.Logger, binancefutures.Config{ExchangeInfoer: eier, UserConfig: ucfg, ClientPool: futuresPool})
+		})
+		registerExchange(registry, "BINANCE_SPOT", dir, func(ucfg binancespot.UserConfig, eier outbound.FileLoader[binancespot.ExchangeInfo]) outbound.Exchange {
+			return binancespot.New(app.Logger, binancespot.Config{ExchangeInfoer: eier, UserConfig: ucfg})
+		})
+		registerExchange(registry, "BYBIT_LINEAR", dir, func(ucfg bybit.UserConfig, eier outbound.FileLoader[bybit.ExchangeInfo]) outbound.Exchange {
+			return bybit.New(app.Logger, bybit.Config{ExchangeInfoer: eier, UserConfig: ucfg})
+		})
+		registerExchange(registry, "OKX_SWAP", dir, func(ucfg okx.UserConfig, eier outbound.FileLoader[okx.ExchangeInfo]) outbound.Exchange {
+			return okx.New(app.Logger, okx.Config{ExchangeInfoer: eier, UserConfig: ucfg})
+		})
+
+		app.Exchanges = registry
+		return nil
+	}
+}
+
+// registerExchange registers an adapter with the user config U and the exchange info EI cached in dir,
+// the user config is validated before the exchange is created.
+func registerExchange[U, EI any](registry *outbound.ExchangeRegistry, name, dir string, newExchange func(U, outbound.FileLoader[EI]) outbound.Exchange) {
+	registry.Register(name, func(config []byte) (outbound.Exchange, error) {
+		var ucfg U
+		if err := json.Unmarshal(config, &ucfg); err != nil {
+			return nil, err
+		}
+
+		if err := validate.Struct(ucfg); err != nil {
+			return nil, err
+		}
+
+		eier, err := floader.NewPrefixed[EI](dir)
+		if err != nil {
+			return nil, err
+		}
+
+		return newExchange(ucfg, eier), nil
+	})
+}
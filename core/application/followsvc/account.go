@@ -0,0 +1,172 @@
+package followsvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/Plotrader/core/outbound"
+)
+
+// ErrInsufficientMargin is returned when the opening orders of a follow need more margin than the account has available
+var ErrInsufficientMargin = errors.New("insufficient margin")
+
+// positions are the open positions of the pair of a follow, nil if the exchange can't read the account
+type positions []domain.Position
+
+// readPositions returns the open positions of the pair if the exchange is an outbound.AccountReader
+func readPositions(ctx context.Context, exchange outbound.Exchange, pair domain.Pair) (positions, error) {
+	reader, ok := exchange.(outbound.AccountReader)
+	if !ok {
+		return nil, nil
+	}
+
+	p, err := reader.Positions(ctx, outbound.PositionsRequest{
+		Pair: pair,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return append(positions{}, p...), nil
+}
+
+// amount returns the absolute amount of the position on the side,
+// positions of accounts in one-way mode have no side and match both sides.
+func (p positions) amount(side domain.PositionSide) float64 {
+	amount := 0.0
+	for _, position := range p {
+		if side == "" || position.PositionSide == "" || position.PositionSide == side {
+			amount += math.Abs(position.Amount)
+		}
+	}
+	return amount
+}
+
+// closeAmount returns the amount of the position a close position order closes,
+// ok is false if the order isn't closing the position or the positions are unknown.
+func (p positions) closeAmount(order domain.Order) (amount float64, ok bool) {
+	if !order.ClosePosition || p == nil {
+		return 0, false
+	}
+	return p.amount(order.PositionSide), true
+}
+
+// protective returns true if the order reduces or closes the position instead of opening it
+func protective(order domain.Order) bool {
+	return order.Type.Closing() || order.ReduceOnly || order.ClosePosition
+}
+
+// opened returns true if an opening order of the position side was filled
+func opened(orders []domain.Order, side domain.PositionSide) bool {
+	for _, order := range orders {
+		if protective(order) || order.ExchangeOrder == nil || order.ExchangeOrder.Status != domain.OrderStatusDone {
+			continue
+		}
+		if side == "" || order.PositionSide == "" || order.PositionSide == side {
+			return true
+		}
+	}
+	return false
+}
+
+// cancelClosedProtection cancels the protective orders of a position the follow opened once it's closed,
+// manually or by another protective order, so they don't open a new position or fail on a missing one.
+func (s *Service) cancelClosedProtection(ctx context.Context, orders []domain.Order, p positions, exchange outbound.Exchange) ([]domain.Order, error) {
+	if p == nil {
+		return nil, nil
+	}
+
+	placed := []domain.Order{}
+	unplaced := []domain.Order{}
+	for _, order := range orders {
+		if !protective(order) || order.Status == domain.OrderStatusCanceled || order.Status == domain.OrderStatusDone {
+			continue
+		}
+		if !opened(orders, order.PositionSide) || p.amount(order.PositionSide) != 0 {
+			continue
+		}
+
+		s.logger.Infof("position of order %s was closed, canceling the order", order.Name)
+		order.Status = domain.OrderStatusCanceled
+		if order.ExchangeOrder != nil && order.ExchangeOrder.Status == domain.OrderStatusActive {
+			placed = append(placed, order)
+			continue
+		}
+		unplaced = append(unplaced, order)
+	}
+
+	canceled, err := s.cancelOrders(ctx, placed, exchange)
+	errs := []error{err}
+	for _, order := range unplaced {
+		canceled = append(canceled, order)
+		if err := s.repo.UpdateOrder(ctx, outbound.UpdateOrderRequest{
+			Order: order,
+		}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return canceled, errors.Join(errs...)
+}
+
+// checkMargin rejects follows whose opening orders need more margin than the available balance of the quote asset
+// if the exchange can read the account. The margin is the notional of the orders divided by the leverage of the follow,
+// follows that keep the leverage of the account need the whole notional.
+func checkMargin(ctx context.Context, exchange outbound.Exchange, follow domain.Follow, orders []domain.Order) error {
+	reader, ok := exchange.(outbound.AccountReader)
+	if !ok {
+		return nil
+	}
+
+	now := time.Now()
+	notional := 0.0
+	for _, order := range orders {
+		if protective(order) {
+			continue
+		}
+		if order.QuoteQuantity != 0 {
+			notional += order.QuoteQuantity
+			continue
+		}
+
+		prices, err := orderPrices(order, now)
+		price := prices.exec()
+		// orders out of range of their plots are valued at the mark price
+		if err != nil || price == 0 {
+			price, err = exchange.MarkPrice(ctx, outbound.MarkPriceRequest{
+				Pair: follow.Pair,
+			})
+			if err != nil {
+				return fmt.Errorf("error getting mark price: %w", err)
+			}
+		}
+		notional += order.BaseQuantity * price
+	}
+
+	if notional == 0 {
+		return nil
+	}
+
+	balances, err := reader.Balances(ctx, outbound.BalancesRequest{
+		Asset: follow.Pair.Quote,
+	})
+	if err != nil {
+		return fmt.Errorf("error getting balance: %w", err)
+	}
+
+	available := 0.0
+	for _, balance := range balances {
+		available += balance.Available
+	}
+
+	margin := notional / float64(max(follow.Leverage, 1))
+	if margin > available {
+		return fmt.Errorf("%w: orders need %f %s, %f available", ErrInsufficientMargin, margin, follow.Pair.Quote, available)
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package followsvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/Plotrader/core/domain/geometry"
+	"github.com/H3Cki/Plotrader/core/outbound"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// fakeAccount places orders like fakeOrderer and reads a fixed account
+type fakeAccount struct {
+	fakeOrderer
+	positions []domain.Position
+	available float64
+	mark      float64
+}
+
+func (f *fakeAccount) Positions(context.Context, outbound.PositionsRequest) ([]domain.Position, error) {
+	return f.positions, nil
+}
+
+func (f *fakeAccount) Balances(_ context.Context, req outbound.BalancesRequest) ([]domain.Balance, error) {
+	return []domain.Balance{{Asset: req.Asset, Wallet: f.available, Available: f.available}}, nil
+}
+
+func (f *fakeAccount) OpenOrders(context.Context, outbound.OpenOrdersRequest) ([]domain.ExchangeOrder, error) {
+	return nil, nil
+}
+
+func (f *fakeAccount) MarkPrice(context.Context, outbound.MarkPriceRequest) (float64, error) {
+	return f.mark, nil
+}
+
+func TestPositions_amount(t *testing.T) {
+	hedge := positions{
+		{PositionSide: domain.PositionSideLong, Amount: 2},
+		{PositionSide: domain.PositionSideShort, Amount: -3},
+	}
+	assert.Equal(t, 2.0, hedge.amount(domain.PositionSideLong))
+	assert.Equal(t, 3.0, hedge.amount(domain.PositionSideShort))
+
+	// one-way positions have no side
+	oneWay := positions{{Amount: -1.5}}
+	assert.Equal(t, 1.5, oneWay.amount(domain.PositionSideShort))
+	assert.Equal(t, 0.0, positions{}.amount(domain.PositionSideLong))
+
+	_, ok := positions(nil).closeAmount(domain.Order{ClosePosition: true})
+	assert.False(t, ok)
+	amount, ok := hedge.closeAmount(domain.Order{ClosePosition: true, PositionSide: domain.PositionSideLong})
+	assert.True(t, ok)
+	assert.Equal(t, 2.0, amount)
+}
+
+func TestCheckMargin(t *testing.T) {
+	ctx := context.Background()
+	follow := domain.Follow{Pair: domain.Pair{Base: "BTC", Quote: "USDT"}}
+	orders := []domain.Order{
+		{Name: "entry", Type: domain.OrderTypeLimit, Plot: flatLine(t, 100), BaseQuantity: 2},
+		{Name: "quote", Type: domain.OrderTypeLimit, Plot: flatLine(t, 100), QuoteQuantity: 50},
+		// protective orders don't need margin
+		{Name: "sl", Type: domain.OrderTypeStopLoss, Plot: flatLine(t, 90), ClosePosition: true},
+	}
+
+	// exchanges that can't read the account aren't checked
+	assert.NoError(t, checkMargin(ctx, &fakeOrderer{}, follow, orders))
+
+	assert.NoError(t, checkMargin(ctx, &fakeAccount{available: 250}, follow, orders))
+	assert.ErrorIs(t, checkMargin(ctx, &fakeAccount{available: 249}, follow, orders), ErrInsufficientMargin)
+
+	follow.Leverage = 5
+	assert.NoError(t, checkMargin(ctx, &fakeAccount{available: 50}, follow, orders))
+
+	// orders out of range of their plots are valued at the mark price
+	past := geometry.NewLimit(flatLine(t, 1), time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour))
+	outOfRange := []domain.Order{{Name: "entry", Type: domain.OrderTypeLimit, Plot: past, BaseQuantity: 1}}
+	assert.ErrorIs(t, checkMargin(ctx, &fakeAccount{available: 10, mark: 100}, follow, outOfRange), ErrInsufficientMargin)
+}
+
+func TestService_createExchangeOrders_ClosePosition(t *testing.T) {
+	orders := []domain.Order{
+		{ID: "tp", Name: "tp", Type: domain.OrderTypeLimit, Side: domain.OrderSideSell, PositionSide: domain.PositionSideLong, Plot: flatLine(t, 110), ClosePosition: true},
+	}
+	repo := newMemoryRepo(domain.Follow{ID: "follow"}, orders...)
+	s := &Service{logger: zap.NewNop().Sugar(), repo: repo}
+	exchange := &fakeAccount{}
+
+	// there's no position to close yet
+	created, err := s.createExchangeOrders(context.Background(), orders, exchange, positions{}, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, created)
+
+	p := positions{{PositionSide: domain.PositionSideLong, Amount: 0.5}}
+	created, err = s.createExchangeOrders(context.Background(), orders, exchange, p, nil)
+	assert.NoError(t, err)
+	assert.Len(t, created, 1)
+	assert.Equal(t, 0.5, exchange.creates[0].BaseQuantity)
+	assert.True(t, exchange.creates[0].ReduceOnly)
+	assert.False(t, exchange.creates[0].ClosePosition)
+
+	// the order follows the amount of the position
+	p[0].Amount = 0.75
+	_, err = s.modifyExchangeOrders(context.Background(), created, exchange, p, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.75, exchange.modifies[0].BaseQuantity)
+}
+
+func TestService_cancelClosedProtection(t *testing.T) {
+	active := &domain.ExchangeOrder{ID: int64(2), Status: domain.OrderStatusActive}
+	orders := []domain.Order{
+		{ID: "entry", Name: "entry", Type: domain.OrderTypeLimit, PositionSide: domain.PositionSideLong, ExchangeOrder: &domain.ExchangeOrder{ID: int64(1), Status: domain.OrderStatusDone}},
+		{ID: "sl", Name: "sl", Type: domain.OrderTypeStopLoss, PositionSide: domain.PositionSideLong, ClosePosition: true, ExchangeOrder: active},
+		{ID: "tp", Name: "tp", Type: domain.OrderTypeTakeProfit, PositionSide: domain.PositionSideLong, BaseQuantity: 1},
+		// the other side keeps its protection
+		{ID: "short", Name: "short", Type: domain.OrderTypeStopLoss, PositionSide: domain.PositionSideShort, ClosePosition: true},
+	}
+	repo := newMemoryRepo(domain.Follow{ID: "follow"}, orders...)
+	s := &Service{logger: zap.NewNop().Sugar(), repo: repo}
+	exchange := &fakeAccount{}
+
+	// the position is still open
+	canceled, err := s.cancelClosedProtection(context.Background(), orders, positions{{PositionSide: domain.PositionSideLong, Amount: 1}}, exchange)
+	assert.NoError(t, err)
+	assert.Empty(t, canceled)
+
+	// unknown positions aren't treated as closed
+	canceled, err = s.cancelClosedProtection(context.Background(), orders, nil, exchange)
+	assert.NoError(t, err)
+	assert.Empty(t, canceled)
+
+	canceled, err = s.cancelClosedProtection(context.Background(), orders, positions{}, exchange)
+	assert.NoError(t, err)
+	assert.Len(t, canceled, 2)
+	assert.Len(t, exchange.cancels, 1)
+	assert.Equal(t, active, exchange.cancels[0].EO)
+
+	sl, _ := repo.GetOrder(context.Background(), outbound.GetOrderRequest{OrderID: "sl"})
+	assert.Equal(t, domain.OrderStatusCanceled, sl.Status)
+	assert.Equal(t, domain.OrderStatusCanceled, sl.ExchangeOrder.Status)
+
+	// the unplaced order is never placed
+	tp, _ := repo.GetOrder(context.Background(), outbound.GetOrderRequest{OrderID: "tp"})
+	assert.Equal(t, domain.OrderStatusCanceled, tp.Status)
+	created, err := s.createExchangeOrders(context.Background(), []domain.Order{tp}, exchange, positions{}, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, created)
+}
@@ -0,0 +1,114 @@
+package followsvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/Plotrader/core/outbound"
+)
+
+// streamEvents consumes the exchange events if the exchange can stream them,
+// order updates are applied to the follow orders right away and the handler is run with mu locked
+// so relations and plots depending on fills react without waiting for the next interval.
+// The follow fails and its loop is stopped if the handler fails.
+func (s *Service) streamEvents(ctx context.Context, followID string, exchange outbound.Exchange, mu *sync.Mutex, handler func(time.Time) error) error {
+	streamer, ok := exchange.(outbound.EventStreamer)
+	if !ok {
+		return nil
+	}
+
+	events, err := streamer.StreamEvents(ctx)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for event := range events {
+			if event.Order == nil {
+				continue
+			}
+
+			mu.Lock()
+			err := s.handleOrderEvent(ctx, followID, event, handler)
+			if err != nil {
+				s.logger.Errorf("error handling order event of follow %s: %v", followID, err)
+				s.failFollow(ctx, followID, err)
+			}
+			mu.Unlock()
+
+			if err != nil {
+				// the loop may have ended on its own already
+				_ = s.stopLoop(followID)
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// handleOrderEvent applies the order event and runs the handler if the status of the order changed,
+// only errors of the handler are returned, the event is skipped if it can't be applied.
+func (s *Service) handleOrderEvent(ctx context.Context, followID string, event outbound.ExchangeEvent, handler func(time.Time) error) error {
+	changed, err := s.applyOrderEvent(ctx, followID, *event.Order)
+	if err != nil {
+		s.logger.Errorf("error applying order event to follow %s: %v", followID, err)
+		return nil
+	}
+	if !changed {
+		return nil
+	}
+
+	if err := handler(event.Time); err != nil && !errors.Is(err, errFollowEnded) {
+		return err
+	}
+	return nil
+}
+
+// applyOrderEvent updates the order of the follow the exchange order belongs to,
+// returns true if the status of the order changed.
+func (s *Service) applyOrderEvent(ctx context.Context, followID string, eo domain.ExchangeOrder) (bool, error) {
+	follow, err := s.repo.GetFollow(ctx, outbound.GetFollowRequest{
+		FollowID: followID,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	for _, orderID := range follow.OrderIDs {
+		order, err := s.repo.GetOrder(ctx, outbound.GetOrderRequest{
+			OrderID: orderID,
+		})
+		if err != nil {
+			return false, err
+		}
+
+		// IDs can change their type after a round trip through the repository
+		if order.ExchangeOrder == nil || fmt.Sprint(order.ExchangeOrder.ID) != fmt.Sprint(eo.ID) {
+			continue
+		}
+
+		changed := order.ExchangeOrder.Status != eo.Status
+		order.ExchangeOrder = &eo
+		order.Status = eo.Status
+
+		return changed, s.repo.UpdateOrder(ctx, outbound.UpdateOrderRequest{
+			Order: order,
+		})
+	}
+
+	return false, nil
+}
+
+// lockedHandler prevents the interval loop, the event stream and status changes from running at the same time
+func lockedHandler(mu *sync.Mutex, handler func(time.Time) error) func(time.Time) error {
+	return func(t time.Time) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return handler(t)
+	}
+}
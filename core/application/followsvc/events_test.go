@@ -0,0 +1,139 @@
+package followsvc
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/Plotrader/core/outbound"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type memoryRepo struct {
+	outbound.Repository
+	mu      sync.Mutex
+	follows map[string]domain.Follow
+	orders  map[string]domain.Order
+}
+
+func newMemoryRepo(follow domain.Follow, orders ...domain.Order) *memoryRepo {
+	repo := &memoryRepo{
+		follows: map[string]domain.Follow{follow.ID: follow},
+		orders:  map[string]domain.Order{},
+	}
+	for _, o := range orders {
+		repo.orders[o.ID] = o
+	}
+	return repo
+}
+
+func (r *memoryRepo) GetFollow(_ context.Context, req outbound.GetFollowRequest) (domain.Follow, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.follows[req.FollowID], nil
+}
+
+func (r *memoryRepo) GetOrder(_ context.Context, req outbound.GetOrderRequest) (domain.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.orders[req.OrderID], nil
+}
+
+func (r *memoryRepo) UpdateFollow(_ context.Context, req outbound.UpdateFollowRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.follows[req.Follow.ID] = req.Follow
+	return nil
+}
+
+func (r *memoryRepo) UpdateOrder(_ context.Context, req outbound.UpdateOrderRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.orders[req.Order.ID] = req.Order
+	return nil
+}
+
+type fakeStreamer struct {
+	fakeExchange
+	events chan outbound.ExchangeEvent
+}
+
+func (f *fakeStreamer) StreamEvents(context.Context) (<-chan outbound.ExchangeEvent, error) {
+	return f.events, nil
+}
+
+func TestService_streamEvents(t *testing.T) {
+	follow := domain.Follow{ID: "follow", OrderIDs: []string{"entry", "tp"}}
+	repo := newMemoryRepo(follow,
+		domain.Order{ID: "entry", Status: domain.OrderStatusActive, ExchangeOrder: &domain.ExchangeOrder{ID: int64(1), Status: domain.OrderStatusActive}},
+		domain.Order{ID: "tp", Status: domain.OrderStatusProcessing},
+	)
+	s := &Service{logger: zap.NewNop().Sugar(), repo: repo}
+
+	handled := make(chan time.Time, 10)
+	handler := func(t time.Time) error {
+		handled <- t
+		return nil
+	}
+
+	streamer := &fakeStreamer{events: make(chan outbound.ExchangeEvent)}
+	err := s.streamEvents(context.Background(), follow.ID, streamer, &sync.Mutex{}, handler)
+	assert.NoError(t, err)
+
+	fillTime := time.Unix(1700000000, 0)
+	streamer.events <- outbound.ExchangeEvent{Account: &outbound.AccountUpdate{}}
+	// partial fill keeps the order active
+	streamer.events <- outbound.ExchangeEvent{Order: &domain.ExchangeOrder{ID: int64(1), Status: domain.OrderStatusActive}}
+	// order of another follow
+	streamer.events <- outbound.ExchangeEvent{Order: &domain.ExchangeOrder{ID: int64(2), Status: domain.OrderStatusDone}}
+	streamer.events <- outbound.ExchangeEvent{Time: fillTime, Order: &domain.ExchangeOrder{ID: int64(1), Status: domain.OrderStatusDone, FillPrice: 100}}
+	close(streamer.events)
+
+	select {
+	case got := <-handled:
+		assert.Equal(t, fillTime, got)
+	case <-time.After(time.Second):
+		t.Fatal("handler wasn't called")
+	}
+	assert.Empty(t, handled)
+
+	entry, _ := repo.GetOrder(context.Background(), outbound.GetOrderRequest{OrderID: "entry"})
+	assert.Equal(t, domain.OrderStatusDone, entry.Status)
+	assert.Equal(t, 100.0, entry.ExchangeOrder.FillPrice)
+}
+
+func TestService_streamEvents_HandlerError(t *testing.T) {
+	follow := domain.Follow{ID: "follow", Status: domain.FollowStatusActive, OrderIDs: []string{"entry"}}
+	repo := newMemoryRepo(follow,
+		domain.Order{ID: "entry", Status: domain.OrderStatusActive, ExchangeOrder: &domain.ExchangeOrder{ID: int64(1), Status: domain.OrderStatusActive}},
+	)
+	s := &Service{logger: zap.NewNop().Sugar(), repo: repo, publisher: &fakePublisher{}, mu: &sync.Mutex{}, loops: map[string]*intervalLoop{}}
+
+	mu := &sync.Mutex{}
+	loop := s.newIntervalLoop(s.logger, follow.ID, time.Hour, mu, nil)
+
+	streamer := &fakeStreamer{events: make(chan outbound.ExchangeEvent, 1)}
+	err := s.streamEvents(context.Background(), follow.ID, streamer, mu, func(time.Time) error { return assert.AnError })
+	assert.NoError(t, err)
+
+	streamer.events <- outbound.ExchangeEvent{Order: &domain.ExchangeOrder{ID: int64(1), Status: domain.OrderStatusDone}}
+
+	// the follow fails with the cause and its loop is stopped
+	select {
+	case <-loop.stopC:
+	case <-time.After(time.Second):
+		t.Fatal("loop wasn't stopped")
+	}
+	got, _ := repo.GetFollow(context.Background(), outbound.GetFollowRequest{FollowID: follow.ID})
+	assert.Equal(t, domain.FollowStatusFailed, got.Status)
+	assert.Equal(t, assert.AnError.Error(), got.Transitions[0].Reason)
+}
+
+func TestService_streamEvents_Unsupported(t *testing.T) {
+	s := &Service{logger: zap.NewNop().Sugar()}
+	err := s.streamEvents(context.Background(), "follow", &fakeExchange{}, &sync.Mutex{}, nil)
+	assert.NoError(t, err)
+}
@@ -1,35 +1,117 @@
 package followsvc
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/H3Cki/Plotrader/core/domain"
 	"github.com/H3Cki/Plotrader/core/inbound"
 	"github.com/H3Cki/Plotrader/core/outbound"
-	"github.com/H3Cki/Plotrader/infractructure/exchanges/binancefutures"
-	"github.com/H3Cki/Plotrader/infractructure/floader"
-	"go.uber.org/zap"
 )
 
-var (
-	exDirPath = "data/exchange_infos"
-)
+// newExchange creates the exchange of the request with the exchange factory
+func (s *Service) newExchange(ex inbound.Exchange) (outbound.Exchange, error) {
+	cfg, err := ex.ConfigJSON()
+	if err != nil {
+		return nil, err
+	}
+	return s.exchanges.NewExchange(ex.Name, cfg)
+}
+
+// configurePosition applies the leverage and margin type of the follow,
+// it fails if they're specified and the exchange can't configure them.
+func configurePosition(ctx context.Context, exchange outbound.Exchange, follow domain.Follow) error {
+	if follow.Leverage == 0 && follow.MarginType == "" {
+		return nil
+	}
+
+	configurer, ok := exchange.(outbound.PositionConfigurer)
+	if !ok {
+		return fmt.Errorf("leverage and margin type: %w", outbound.ErrUnsupported)
+	}
+
+	return configurer.ConfigurePosition(ctx, outbound.ConfigurePositionRequest{
+		Pair:       follow.Pair,
+		Leverage:   follow.Leverage,
+		MarginType: follow.MarginType,
+	})
+}
+
+// createOrders creates the orders with a single batch call if the exchange supports it, one by one otherwise
+func createOrders(ctx context.Context, exchange outbound.Exchange, reqs []outbound.CreateExchangeOrderRequest) []outbound.BatchResult {
+	if len(reqs) == 0 {
+		return nil
+	}
+
+	if batcher, ok := exchange.(outbound.BatchCreator); ok {
+		return batcher.CreateOrders(ctx, reqs)
+	}
+
+	results := make([]outbound.BatchResult, len(reqs))
+	for i, req := range reqs {
+		eo, err := exchange.CreateOrder(ctx, req)
+		results[i] = outbound.BatchResult{EO: eo, Err: err}
+	}
+	return results
+}
+
+// modifyOrders modifies the orders with a single batch call if the exchange supports it, one by one otherwise
+func modifyOrders(ctx context.Context, exchange outbound.Exchange, reqs []outbound.ModifyExchangeOrderRequest) []outbound.BatchResult {
+	if len(reqs) == 0 {
+		return nil
+	}
+
+	if batcher, ok := exchange.(outbound.BatchModifier); ok {
+		return batcher.ModifyOrders(ctx, reqs)
+	}
+
+	results := make([]outbound.BatchResult, len(reqs))
+	for i, req := range reqs {
+		eo, err := exchange.ModifyOrder(ctx, req)
+		results[i] = outbound.BatchResult{EO: eo, Err: err}
+	}
+	return results
+}
+
+// cancelOrders cancels the orders with a single batch call if the exchange supports it, one by one otherwise
+func cancelOrders(ctx context.Context, exchange outbound.Exchange, reqs []outbound.CancelExchangeOrdersRequest) []outbound.BatchResult {
+	if len(reqs) == 0 {
+		return nil
+	}
+
+	if batcher, ok := exchange.(outbound.BatchCanceler); ok {
+		return batcher.CancelOrders(ctx, reqs)
+	}
+
+	results := make([]outbound.BatchResult, len(reqs))
+	for i, req := range reqs {
+		eo, err := exchange.CancelOrder(ctx, req)
+		results[i] = outbound.BatchResult{EO: eo, Err: err}
+	}
+	return results
+}
+
+// markPricer fetches the mark price of the pair from the exchange once and reuses it,
+// it's meant to be created for every loop run.
+type markPricer struct {
+	ctx      context.Context
+	exchange outbound.Exchange
+	pair     domain.Pair
+	price    *float64
+}
+
+func (m *markPricer) MarkPrice() (float64, error) {
+	if m.price != nil {
+		return *m.price, nil
+	}
+
+	price, err := m.exchange.MarkPrice(m.ctx, outbound.MarkPriceRequest{
+		Pair: m.pair,
+	})
+	if err != nil {
+		return 0, err
+	}
 
-func parseExchange(logger *zap.SugaredLogger, ex inbound.Exchange) (outbound.Exchange, error) {
-	switch ex.Name {
-	case "BINANCE_FUTURES":
-		ucfg := binancefutures.UserConfig{}
-		if err := ex.UnmarshalConfig(&ucfg); err != nil {
-			return nil, err
-		}
-		eier, err := floader.NewPrefixed[binancefutures.ExchangeInfo](exDirPath)
-		if err != nil {
-			return nil, err
-		}
-		cfg := binancefutures.Config{
-			ExchangeInfoer: eier,
-			UserConfig:     ucfg,
-		}
-		return binancefutures.New(logger, cfg), nil
-	}
-	return nil, fmt.Errorf("unknown exchange: %s", ex.Name)
+	m.price = &price
+	return price, nil
 }
@@ -0,0 +1,278 @@
+package followsvc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/Plotrader/core/domain/geometry"
+	"github.com/H3Cki/Plotrader/core/inbound"
+	"github.com/H3Cki/Plotrader/core/outbound"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type fakeExchange struct {
+	outbound.Exchange
+}
+
+type fakeConfigurer struct {
+	fakeExchange
+	reqs []outbound.ConfigurePositionRequest
+}
+
+func (f *fakeConfigurer) ConfigurePosition(_ context.Context, req outbound.ConfigurePositionRequest) error {
+	f.reqs = append(f.reqs, req)
+	return nil
+}
+
+func TestConfigurePosition(t *testing.T) {
+	ctx := context.Background()
+	pair := domain.Pair{Base: "BTC", Quote: "USDT"}
+
+	// nothing to configure
+	assert.NoError(t, configurePosition(ctx, &fakeExchange{}, domain.Follow{Pair: pair}))
+
+	// unsupported capability
+	err := configurePosition(ctx, &fakeExchange{}, domain.Follow{Pair: pair, Leverage: 5})
+	assert.ErrorIs(t, err, outbound.ErrUnsupported)
+
+	configurer := &fakeConfigurer{}
+	err = configurePosition(ctx, configurer, domain.Follow{Pair: pair, Leverage: 5, MarginType: domain.MarginTypeIsolated})
+	assert.NoError(t, err)
+	assert.Equal(t, []outbound.ConfigurePositionRequest{
+		{Pair: pair, Leverage: 5, MarginType: domain.MarginTypeIsolated},
+	}, configurer.reqs)
+}
+
+func TestService_newExchange(t *testing.T) {
+	registry := outbound.NewExchangeRegistry()
+	configs := []string{}
+	registry.Register("FAKE", func(config []byte) (outbound.Exchange, error) {
+		configs = append(configs, string(config))
+		return &fakeExchange{}, nil
+	})
+
+	s := New(Config{Exchanges: registry})
+
+	_, err := s.newExchange(inbound.Exchange{Name: "FAKE", Config: map[string]any{"API_KEY": "key"}})
+	assert.NoError(t, err)
+
+	t.Setenv("FAKE_CONFIG", `{"API_KEY":"env"}`)
+	_, err = s.newExchange(inbound.Exchange{Name: "FAKE", ConfigEnv: "FAKE_CONFIG"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{`{"API_KEY":"key"}`, `{"API_KEY":"env"}`}, configs)
+
+	_, err = s.newExchange(inbound.Exchange{Name: "UNKNOWN"})
+	assert.ErrorIs(t, err, outbound.ErrUnknownExchange)
+}
+
+// fakeOrderer places orders one by one, orders priced at failPrice fail
+type fakeOrderer struct {
+	fakeExchange
+	failPrice float64
+	creates   []outbound.CreateExchangeOrderRequest
+	modifies  []outbound.ModifyExchangeOrderRequest
+	cancels   []outbound.CancelExchangeOrdersRequest
+}
+
+func (f *fakeOrderer) CreateOrder(_ context.Context, req outbound.CreateExchangeOrderRequest) (*domain.ExchangeOrder, error) {
+	f.creates = append(f.creates, req)
+	if req.Price == f.failPrice {
+		return nil, errors.New("rejected")
+	}
+	return &domain.ExchangeOrder{ID: int64(len(f.creates)), ClientID: req.ClientOrderID, Status: domain.OrderStatusActive, Price: req.Price}, nil
+}
+
+func (f *fakeOrderer) ModifyOrder(_ context.Context, req outbound.ModifyExchangeOrderRequest) (*domain.ExchangeOrder, error) {
+	f.modifies = append(f.modifies, req)
+	eo := *req.EO
+	eo.Price = req.Price
+	return &eo, nil
+}
+
+func (f *fakeOrderer) CancelOrder(_ context.Context, req outbound.CancelExchangeOrdersRequest) (*domain.ExchangeOrder, error) {
+	f.cancels = append(f.cancels, req)
+	eo := *req.EO
+	eo.Status = domain.OrderStatusCanceled
+	return &eo, nil
+}
+
+// fakeBatcher supports the batch capabilities, it records the size of every batch
+type fakeBatcher struct {
+	fakeOrderer
+	batches []int
+}
+
+func (f *fakeBatcher) CreateOrders(ctx context.Context, reqs []outbound.CreateExchangeOrderRequest) []outbound.BatchResult {
+	f.batches = append(f.batches, len(reqs))
+	results := []outbound.BatchResult{}
+	for _, req := range reqs {
+		eo, err := f.fakeOrderer.CreateOrder(ctx, req)
+		results = append(results, outbound.BatchResult{EO: eo, Err: err})
+	}
+	return results
+}
+
+func (f *fakeBatcher) ModifyOrders(ctx context.Context, reqs []outbound.ModifyExchangeOrderRequest) []outbound.BatchResult {
+	f.batches = append(f.batches, len(reqs))
+	results := []outbound.BatchResult{}
+	for _, req := range reqs {
+		eo, err := f.fakeOrderer.ModifyOrder(ctx, req)
+		results = append(results, outbound.BatchResult{EO: eo, Err: err})
+	}
+	return results
+}
+
+func (f *fakeBatcher) CancelOrders(ctx context.Context, reqs []outbound.CancelExchangeOrdersRequest) []outbound.BatchResult {
+	f.batches = append(f.batches, len(reqs))
+	results := []outbound.BatchResult{}
+	for _, req := range reqs {
+		eo, err := f.fakeOrderer.CancelOrder(ctx, req)
+		results = append(results, outbound.BatchResult{EO: eo, Err: err})
+	}
+	return results
+}
+
+func TestBatchFallback(t *testing.T) {
+	ctx := context.Background()
+	creates := []outbound.CreateExchangeOrderRequest{{Price: 1}, {Price: 2}, {Price: 3}}
+	eo := &domain.ExchangeOrder{ID: int64(1), Status: domain.OrderStatusActive}
+
+	// exchanges without batch endpoints get one call per order, a failed order doesn't stop the others
+	orderer := &fakeOrderer{failPrice: 2}
+	results := createOrders(ctx, orderer, creates)
+	assert.Len(t, orderer.creates, 3)
+	assert.NoError(t, results[0].Err)
+	assert.Error(t, results[1].Err)
+	assert.Equal(t, 3.0, results[2].EO.Price)
+
+	modified := modifyOrders(ctx, orderer, []outbound.ModifyExchangeOrderRequest{{EO: eo, Price: 5}})
+	assert.Equal(t, 5.0, modified[0].EO.Price)
+
+	canceled := cancelOrders(ctx, orderer, []outbound.CancelExchangeOrdersRequest{{EO: eo}})
+	assert.Equal(t, domain.OrderStatusCanceled, canceled[0].EO.Status)
+
+	batcher := &fakeBatcher{}
+	createOrders(ctx, batcher, creates)
+	modifyOrders(ctx, batcher, []outbound.ModifyExchangeOrderRequest{{EO: eo}, {EO: eo}})
+	cancelOrders(ctx, batcher, []outbound.CancelExchangeOrdersRequest{{EO: eo}})
+	// empty batches aren't sent
+	createOrders(ctx, batcher, nil)
+	assert.Equal(t, []int{3, 2, 1}, batcher.batches)
+}
+
+func TestService_createExchangeOrders(t *testing.T) {
+	now := time.Now()
+	line := func(price float64) geometry.Plot {
+		l, err := geometry.NewLine(geometry.Point{Date: now.Add(-time.Hour), Price: price}, geometry.Point{Date: now.Add(time.Hour), Price: price})
+		assert.NoError(t, err)
+		return l
+	}
+
+	orders := []domain.Order{
+		{ID: "a", Name: "a", Type: domain.OrderTypeLimit, Plot: line(100), BaseQuantity: 1},
+		{ID: "b", Name: "b", Type: domain.OrderTypeLimit, Plot: line(200), BaseQuantity: 1},
+		// already placed
+		{ID: "c", Name: "c", Type: domain.OrderTypeLimit, Plot: line(300), BaseQuantity: 1, ExchangeOrder: &domain.ExchangeOrder{ID: int64(9), Status: domain.OrderStatusActive, Price: 250}},
+		{ID: "d", Name: "d", Type: domain.OrderTypeLimit, Plot: line(400), BaseQuantity: 1},
+	}
+	repo := newMemoryRepo(domain.Follow{ID: "follow"}, orders...)
+	s := &Service{logger: zap.NewNop().Sugar(), repo: repo}
+	exchange := &fakeBatcher{fakeOrderer: fakeOrderer{failPrice: 200}}
+
+	// the orders are created in a single batch, the failed one is reported while the others are kept
+	created, err := s.createExchangeOrders(context.Background(), orders, exchange, nil, nil)
+	assert.ErrorContains(t, err, "error creating order b")
+	assert.Equal(t, []int{3}, exchange.batches)
+	assert.Len(t, created, 2)
+	assert.Equal(t, 100.0, created[0].ExchangeOrder.Price)
+	assert.Equal(t, 400.0, created[1].ExchangeOrder.Price)
+
+	d, _ := repo.GetOrder(context.Background(), outbound.GetOrderRequest{OrderID: "d"})
+	assert.NotNil(t, d.ExchangeOrder)
+
+	// the open order is modified in a batch and all placed orders are canceled in another
+	modified, err := s.modifyExchangeOrders(context.Background(), replaceOrders(orders, created), exchange, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, modified, 3)
+	assert.Equal(t, 300.0, modified[1].ExchangeOrder.Price)
+
+	_, err = s.cancelOrders(context.Background(), replaceOrders(orders, modified), exchange)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 3, 3}, exchange.batches)
+}
+
+func flatLine(t *testing.T, price float64) geometry.Plot {
+	now := time.Now()
+	l, err := geometry.NewLine(geometry.Point{Date: now.Add(-time.Hour), Price: price}, geometry.Point{Date: now.Add(time.Hour), Price: price})
+	assert.NoError(t, err)
+	return l
+}
+
+func TestService_createExchangeOrders_PriceProtection(t *testing.T) {
+	protected := func(price float64, state geometry.PlotState) geometry.Plot {
+		return geometry.NewProtectorWithConfig(flatLine(t, price), geometry.Protection{MaxPrice: 150}, geometry.Env{State: state}, "protector_0")
+	}
+	live := &domain.ExchangeOrder{ID: int64(9), Status: domain.OrderStatusActive, Price: 140}
+	orders := []domain.Order{
+		{ID: "a", Name: "a", Type: domain.OrderTypeLimit, Plot: protected(100, geometry.PlotState{}), BaseQuantity: 1},
+		{ID: "b", Name: "b", Type: domain.OrderTypeLimit, Plot: protected(200, geometry.PlotState{}), BaseQuantity: 1},
+		{ID: "sl", Name: "sl", Type: domain.OrderTypeStopLoss, Plot: protected(200, geometry.PlotState{}), ClosePosition: true, ExchangeOrder: live},
+	}
+	repo := newMemoryRepo(domain.Follow{ID: "follow"}, orders...)
+	s := &Service{logger: zap.NewNop().Sugar(), repo: repo}
+	exchange := &fakeOrderer{failPrice: -1}
+
+	// rejected orders are skipped without failing the others
+	created, err := s.createExchangeOrders(context.Background(), orders, exchange, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, exchange.creates, 1)
+	assert.Len(t, created, 2)
+
+	b, _ := repo.GetOrder(context.Background(), outbound.GetOrderRequest{OrderID: "b"})
+	assert.Nil(t, b.ExchangeOrder)
+	assert.Contains(t, b.Rejection, geometry.ErrPriceProtection.Error())
+
+	// the exchange order of a rejected modification is left as it is
+	modified, err := s.modifyExchangeOrders(context.Background(), orders[2:], exchange, nil, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, exchange.modifies)
+	assert.Equal(t, live, modified[0].ExchangeOrder)
+
+	rejected := rejections(replaceOrders(replaceOrders(orders, created), modified))
+	assert.Equal(t, []string{"b", "sl"}, []string{rejected[0].Name, rejected[1].Name})
+}
+
+func TestService_createExchangeOrders_ClientOrderID(t *testing.T) {
+	now := time.Now()
+	plot, err := geometry.NewLine(geometry.Point{Date: now.Add(-time.Hour), Price: 100}, geometry.Point{Date: now.Add(time.Hour), Price: 100})
+	assert.NoError(t, err)
+
+	orders := []domain.Order{{ID: "a", Name: "a", Type: domain.OrderTypeLimit, Plot: plot, BaseQuantity: 1}}
+	repo := newMemoryRepo(domain.Follow{ID: "follow"}, orders...)
+	s := &Service{logger: zap.NewNop().Sugar(), repo: repo}
+	exchange := &fakeOrderer{failPrice: 100}
+
+	// a failed placement is retried with the same client order ID
+	_, err = s.createExchangeOrders(context.Background(), orders, exchange, nil, nil)
+	assert.Error(t, err)
+	exchange.failPrice = 0
+	created, err := s.createExchangeOrders(context.Background(), orders, exchange, nil, nil)
+	assert.NoError(t, err)
+
+	assert.Len(t, exchange.creates, 2)
+	assert.Equal(t, orders[0].ClientOrderID(), exchange.creates[0].ClientOrderID)
+	assert.Equal(t, exchange.creates[0].ClientOrderID, exchange.creates[1].ClientOrderID)
+	assert.Equal(t, exchange.creates[1].ClientOrderID, created[0].ExchangeOrder.ClientID)
+
+	// the next placement of the order gets a new ID
+	assert.Equal(t, 1, created[0].Placements)
+	assert.NotEqual(t, orders[0].ClientOrderID(), created[0].ClientOrderID())
+	assert.Len(t, created[0].ClientOrderID(), 32)
+	stored, _ := repo.GetOrder(context.Background(), outbound.GetOrderRequest{OrderID: "a"})
+	assert.Equal(t, 1, stored.Placements)
+}
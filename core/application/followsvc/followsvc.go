@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/Plotrader/core/domain/geometry"
 	"github.com/H3Cki/Plotrader/core/inbound"
 	"github.com/H3Cki/Plotrader/core/outbound"
 	"github.com/go-playground/validator/v10"
@@ -22,6 +23,10 @@ type Config struct {
 	Logger     *zap.SugaredLogger
 	Publisher  outbound.Publisher
 	Repository outbound.Repository
+	// Exchanges creates the exchanges of follow requests
+	Exchanges outbound.ExchangeFactory
+	// Risk limits the orders of the follows, everything is allowed by default
+	Risk RiskConfig
 }
 
 type Service struct {
@@ -29,6 +34,8 @@ type Service struct {
 	loops     map[string]*intervalLoop
 	publisher outbound.Publisher
 	repo      outbound.Repository
+	exchanges outbound.ExchangeFactory
+	risk      *riskEngine
 	mu        *sync.Mutex
 }
 
@@ -39,6 +46,8 @@ func New(cfg Config) *Service {
 		publisher: cfg.Publisher,
 		loops:     map[string]*intervalLoop{},
 		repo:      cfg.Repository,
+		exchanges: cfg.Exchanges,
+		risk:      newRiskEngine(cfg.Risk),
 		mu:        &sync.Mutex{},
 	}
 }
@@ -63,31 +72,111 @@ func (s *Service) StopFollow(ctx context.Context, req inbound.StopFollowRequest)
 	return s.stopFollow(ctx, req)
 }
 
-func (s *Service) createFollow(ctx context.Context, req inbound.CreateFollowRequest) (inbound.CreateFollowResponse, error) {
+func (s *Service) PauseFollow(ctx context.Context, req inbound.PauseFollowRequest) error {
+	return s.pauseFollow(ctx, req)
+}
+
+func (s *Service) ResumeFollow(ctx context.Context, req inbound.ResumeFollowRequest) error {
+	return s.resumeFollow(ctx, req)
+}
+
+func (s *Service) ListFollowTransitions(ctx context.Context, req inbound.ListFollowTransitionsRequest) (inbound.ListFollowTransitionsResponse, error) {
+	if err := validate.Struct(req); err != nil {
+		return inbound.ListFollowTransitionsResponse{}, err
+	}
+	follow, err := s.repo.GetFollow(ctx, outbound.GetFollowRequest{
+		FollowID: req.FollowID,
+	})
+	if err != nil {
+		return inbound.ListFollowTransitionsResponse{}, err
+	}
+	return inbound.ListFollowTransitionsResponse{
+		Transitions: follow.Transitions,
+	}, nil
+}
+
+func (s *Service) createFollow(ctx context.Context, req inbound.CreateFollowRequest) (_ inbound.CreateFollowResponse, err error) {
 	follow, orders, exchange, err := s.parseFollowReq(ctx, req)
 	if err != nil {
 		return inbound.CreateFollowResponse{}, err
 	}
 
+	if err := checkMargin(ctx, exchange, follow, orders); err != nil {
+		return inbound.CreateFollowResponse{}, err
+	}
+
+	account, err := accountKey(req.Exchange)
+	if err != nil {
+		return inbound.CreateFollowResponse{}, err
+	}
+
+	if err := s.risk.register(account, follow, exchange); err != nil {
+		return inbound.CreateFollowResponse{}, err
+	}
+	// the follow is released by the loop once it's running
+	defer func() {
+		if err != nil {
+			s.risk.release(follow.ID)
+		}
+	}()
+
+	guard := &riskGuard{
+		engine:   s.risk,
+		account:  account,
+		followID: follow.ID,
+		pair:     follow.Pair,
+		exchange: exchange,
+	}
+
+	if err := configurePosition(ctx, exchange, follow); err != nil {
+		return inbound.CreateFollowResponse{}, fmt.Errorf("error configuring position: %w", err)
+	}
+
 	if err := s.setupRepoFollow(ctx, follow, orders); err != nil {
 		return inbound.CreateFollowResponse{}, err
 	}
 
-	handler := s.loopHandler(ctx, follow.ID, exchange)
+	// the loop is registered before the first run, so status changes wait for the handler from the start
+	mu := &sync.Mutex{}
+	unlocked := s.loopHandler(ctx, follow.ID, exchange, guard)
+	handler := lockedHandler(mu, unlocked)
+	loop := s.newIntervalLoop(s.logger, follow.ID, follow.Interval, mu, handler)
 
-	if err := handler(time.Now()); err != nil {
+	mu.Lock()
+	err = unlocked(time.Now())
+	if err != nil && !errors.Is(err, errFollowEnded) {
+		s.failFollow(ctx, follow.ID, err)
+	}
+	mu.Unlock()
+	if err != nil {
+		s.removeLoop(follow.ID)
+		// the orders finished in the first run, there's nothing to follow
+		if errors.Is(err, errFollowEnded) {
+			s.risk.release(follow.ID)
+			return inbound.CreateFollowResponse{FollowID: follow.ID}, nil
+		}
 		return inbound.CreateFollowResponse{}, err
 	}
 
-	loop := s.newIntervalLoop(s.logger, follow.ID, follow.Interval, handler)
+	streamCtx, stopStream := context.WithCancel(ctx)
+	if err := s.streamEvents(streamCtx, follow.ID, exchange, mu, unlocked); err != nil {
+		// the follow still works without the stream, order updates are picked up every interval
+		s.logger.Errorf("error streaming exchange events of follow %s: %v", follow.ID, err)
+	}
 
 	go func() {
 		defer func() {
+			stopStream()
+			s.risk.release(follow.ID)
+			s.removeLoop(follow.ID)
 			s.logger.Info("loop goroutine finished")
 		}()
-		if err := loop.loop(); err != nil {
+		err := loop.loop()
+		if err != nil && !errors.Is(err, errFollowEnded) {
 			s.logger.Error(err)
-			return
+			mu.Lock()
+			s.failFollow(ctx, follow.ID, err)
+			mu.Unlock()
 		}
 	}()
 
@@ -98,11 +187,12 @@ func (s *Service) createFollow(ctx context.Context, req inbound.CreateFollowRequ
 
 func (s *Service) stopFollow(ctx context.Context, req inbound.StopFollowRequest) error {
 	errs := []error{}
-	exchange, err := parseExchange(s.logger, req.Exchange) //todo use hash to validate
+	exchange, err := s.newExchange(req.Exchange) //todo use hash to validate
 	if err != nil {
 		return err
 	}
 
+	unlock := s.lockFollow(req.FollowID)
 	err = s.stopLoop(req.FollowID)
 	if err != nil {
 		errs = append(errs, err)
@@ -112,14 +202,13 @@ func (s *Service) stopFollow(ctx context.Context, req inbound.StopFollowRequest)
 		FollowID: req.FollowID,
 	})
 	if err != nil {
+		unlock()
 		errs = append(errs, err)
 		return errors.Join(errs...)
 	}
 
-	follow.Status = domain.FollowStatusStopped
-	err = s.repo.UpdateFollow(ctx, outbound.UpdateFollowRequest{
-		Follow: follow,
-	})
+	follow, err = s.transition(ctx, follow, domain.FollowStatusStopped, "stopped")
+	unlock()
 	if err != nil {
 		errs = append(errs, err)
 	}
@@ -130,14 +219,15 @@ func (s *Service) stopFollow(ctx context.Context, req inbound.StopFollowRequest)
 
 	orders := []domain.Order{}
 	for _, orderID := range follow.OrderIDs {
-		order, err := s.getOrder(ctx, orderID, exchange)
+		order, err := s.getOrder(ctx, orderID, geometry.Env{})
 		if err != nil {
 			errs = append(errs, err)
 		}
 		orders = append(orders, order)
 	}
 
-	return s.cancelOrders(ctx, orders, exchange)
+	_, err = s.cancelOrders(ctx, orders, exchange)
+	return errors.Join(append(errs, err)...)
 }
 
 func (s *Service) stopLoop(followID string) error {
@@ -147,46 +237,43 @@ func (s *Service) stopLoop(followID string) error {
 	if !ok {
 		return fmt.Errorf("follow %s not found in active loops", followID)
 	}
+	delete(s.loops, followID)
 	close(loop.stopC)
 	return nil
 }
 
-// cancelOrders cancels all orders one by one sequentially and returns a joined error
-func (s *Service) cancelOrders(ctx context.Context, orders []domain.Order, exchange outbound.Exchange) error {
-	errs := []error{}
+// cancelOrders cancels the exchange orders, in batches if the exchange supports it,
+// and returns the canceled orders and a joined error.
+func (s *Service) cancelOrders(ctx context.Context, orders []domain.Order, exchange outbound.Exchange) ([]domain.Order, error) {
+	placed := []domain.Order{}
+	reqs := []outbound.CancelExchangeOrdersRequest{}
 	for _, order := range orders {
 		if order.ExchangeOrder == nil {
 			continue
 		}
-		err := s.cancelOrder(ctx, order, exchange)
-		if err != nil {
-			errs = append(errs, err)
-		}
-	}
-	return errors.Join(errs...)
-}
-
-// cancelOrder cancels the ExchangeOrder and sets OrderStatus to CANCELED
-// if ExchangeOrder is nil only the status is changed
-func (s *Service) cancelOrder(ctx context.Context, order domain.Order, exchange outbound.Exchange) error {
-	if order.ExchangeOrder == nil {
-		order.Status = domain.OrderStatusCanceled
-		return s.repo.UpdateOrder(ctx, outbound.UpdateOrderRequest{
-			Order: order,
+		placed = append(placed, order)
+		reqs = append(reqs, outbound.CancelExchangeOrdersRequest{
+			EO: order.ExchangeOrder,
 		})
 	}
 
-	canceledEO, err := exchange.CancelOrder(ctx, outbound.CancelExchangeOrdersRequest{
-		EO: order.ExchangeOrder,
-	})
-	if err != nil {
-		return err
+	canceled := []domain.Order{}
+	errs := []error{}
+	for i, res := range cancelOrders(ctx, exchange, reqs) {
+		if res.Err != nil {
+			errs = append(errs, fmt.Errorf("error canceling order %s: %w", placed[i].Name, res.Err))
+			continue
+		}
+		order := placed[i]
+		order.ExchangeOrder = res.EO
+		canceled = append(canceled, order)
+		if err := s.repo.UpdateOrder(ctx, outbound.UpdateOrderRequest{
+			Order: order,
+		}); err != nil {
+			errs = append(errs, err)
+		}
 	}
-
-	order.ExchangeOrder = canceledEO
-	return s.repo.UpdateOrder(ctx, outbound.UpdateOrderRequest{
-		Order: order,
-	})
+	return canceled, errors.Join(errs...)
 }
 
 func (s *Service) setupRepoFollow(ctx context.Context, follow domain.Follow, orders []domain.Order) (err error) {
@@ -220,7 +307,7 @@ func (s *Service) setupRepoFollow(ctx context.Context, follow domain.Follow, ord
 	return nil
 }
 
-func (s *Service) loopHandler(ctx context.Context, followID string, exchange outbound.Exchange) func(t time.Time) error {
+func (s *Service) loopHandler(ctx context.Context, followID string, exchange outbound.Exchange, guard *riskGuard) func(t time.Time) error {
 	return func(t time.Time) error {
 		s.logger.Debug("running loop handler")
 		follow, err := s.repo.GetFollow(ctx, outbound.GetFollowRequest{
@@ -230,16 +317,50 @@ func (s *Service) loopHandler(ctx context.Context, followID string, exchange out
 			return fmt.Errorf("error getting follow from repo: %v", err)
 		}
 
-		orders, err := s.getOrders(ctx, follow.OrderIDs, exchange)
+		switch {
+		case follow.Status.Ended():
+			return errFollowEnded
+		case follow.Status == domain.FollowStatusPaused:
+			return nil
+		}
+
+		siblings := &followOrders{}
+		env := geometry.Env{
+			Orders: siblings,
+			Market: &markPricer{ctx: ctx, exchange: exchange, pair: follow.Pair},
+		}
+		orders, err := s.getOrders(ctx, follow.OrderIDs, env)
 		if err != nil {
 			return fmt.Errorf("error getting follow orders: %v", err)
 		}
+		siblings.orders = orders
+
+		// refresh exchange orders so plots can depend on their state
+		synced, err := s.syncExchangeOrders(ctx, orders, exchange)
+		orders = replaceOrders(orders, synced)
+		if err != nil {
+			return fmt.Errorf("error syncing exchange orders: %w", err)
+		}
+
+		// the account is read after the sync so filled orders are reflected in the positions
+		positions, err := readPositions(ctx, exchange, follow.Pair)
+		if err != nil {
+			return fmt.Errorf("error reading positions: %w", err)
+		}
+
+		canceled, err := s.cancelClosedProtection(ctx, orders, positions, exchange)
+		orders = replaceOrders(orders, canceled)
+		if err != nil {
+			return fmt.Errorf("error canceling orders of closed position: %w", err)
+		}
+
+		guard.begin(orders)
 
 		// create exchange orders
-		created, err := s.createExchangeOrders(ctx, orders, exchange)
+		created, err := s.createExchangeOrders(ctx, orders, exchange, positions, guard)
 		orders = replaceOrders(orders, created)
 		if err != nil {
-			cancelErr := s.cancelOrders(ctx, orders, exchange)
+			_, cancelErr := s.cancelOrders(ctx, orders, exchange)
 			return fmt.Errorf("%w: %w", err, cancelErr)
 		}
 
@@ -250,106 +371,260 @@ func (s *Service) loopHandler(ctx context.Context, followID string, exchange out
 			})
 		})
 
-		modifiedOrders, err := s.modifyExchangeOrders(ctx, ordersToModify, exchange)
+		modifiedOrders, err := s.modifyExchangeOrders(ctx, ordersToModify, exchange, positions, guard)
 		orders = replaceOrders(orders, modifiedOrders)
 		if err != nil {
-			cancelErr := s.cancelOrders(ctx, orders, exchange)
+			_, cancelErr := s.cancelOrders(ctx, orders, exchange)
 			return fmt.Errorf("%w: %w", err, cancelErr)
 		}
 
+		if follow.Status == domain.FollowStatusPending {
+			if follow, err = s.transition(ctx, follow, domain.FollowStatusActive, "started"); err != nil {
+				return err
+			}
+		}
+
+		// orders blocked by the risk limits mark the follow until they're within the limits again
+		switch violation := guard.takeViolation(); {
+		case violation != nil && follow.Status != domain.FollowStatusRiskError:
+			s.logger.Warnf("follow %s blocked an order: %s", follow.ID, violation)
+			follow.RiskViolation = violation
+			follow, err = s.transition(ctx, follow, domain.FollowStatusRiskError, violation.Error())
+		case violation == nil && follow.Status == domain.FollowStatusRiskError:
+			follow.RiskViolation = nil
+			follow, err = s.transition(ctx, follow, domain.FollowStatusActive, "orders are within the risk limits")
+		}
+		if err != nil {
+			return err
+		}
+
+		if completed(orders) {
+			if _, err := s.transition(ctx, follow, domain.FollowStatusCompleted, "all orders are finished"); err != nil {
+				return err
+			}
+			return errFollowEnded
+		}
+
 		return s.publisher.PublishFollowUpdate(ctx, outbound.FollowUpdate{
-			Follow: follow,
+			Follow:     follow,
+			Rejections: rejections(orders),
 		})
 	}
 }
 
-func (s *Service) createExchangeOrders(ctx context.Context, orders []domain.Order, exchange outbound.Exchange) ([]domain.Order, error) {
-	created := []domain.Order{}
+// createExchangeOrders places the orders that aren't on the exchange yet in a single batch,
+// orders out of range of their plots are created in a later run.
+// Orders with prices rejected by their protection aren't created, they're returned with the rejection.
+// Close position orders are sized to the positions if they're known and created once there is a position to close.
+// Opening orders are checked by the guard and the ones violating the risk limits aren't created.
+func (s *Service) createExchangeOrders(ctx context.Context, orders []domain.Order, exchange outbound.Exchange, p positions, guard *riskGuard) ([]domain.Order, error) {
+	now := time.Now()
+	pending, rejected := []domain.Order{}, []domain.Order{}
+	reqs := []outbound.CreateExchangeOrderRequest{}
 	for _, order := range orders {
 		if order.ExchangeOrder != nil && order.ExchangeOrder.Status != "" {
 			continue
 		}
-		order, err := s.createExchangeOrder(ctx, order, exchange)
+		// protective orders of closed positions aren't placed
+		if order.Status == domain.OrderStatusCanceled {
+			continue
+		}
+		req, err := createOrderRequest(order, now)
+		if errors.Is(err, geometry.ErrPlotOutOfRange) {
+			continue
+		}
+		if errors.Is(err, geometry.ErrPriceProtection) {
+			rejected = append(rejected, s.rejectOrder(ctx, order, err))
+			continue
+		}
 		if err != nil {
-			return created, err
+			return nil, err
+		}
+		if amount, ok := p.closeAmount(order); ok {
+			if amount == 0 {
+				continue
+			}
+			// reduce only orders of the position's amount close it on exchanges without close position orders
+			req.BaseQuantity, req.ReduceOnly, req.ClosePosition = amount, true, false
+		}
+		allowed, err := guard.allow(ctx, order, req.BaseQuantity, req.Price, req.StopPrice)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			continue
+		}
+		pending = append(pending, order)
+		reqs = append(reqs, req)
+	}
+
+	created := rejected
+	errs := []error{}
+	for i, res := range createOrders(ctx, exchange, reqs) {
+		if res.Err != nil {
+			errs = append(errs, fmt.Errorf("error creating order %s: %w", pending[i].Name, res.Err))
+			continue
 		}
+		order := pending[i]
+		order.ExchangeOrder = res.EO
+		order.Placements++
+		order.Rejection = ""
 		created = append(created, order)
 		if err := s.repo.UpdateOrder(ctx, outbound.UpdateOrderRequest{
 			Order: order,
 		}); err != nil {
-			return created, err
+			errs = append(errs, err)
 		}
-
 	}
-	return created, nil
+	return created, errors.Join(errs...)
 }
 
-func (s *Service) createExchangeOrder(ctx context.Context, order domain.Order, exchange outbound.Exchange) (domain.Order, error) {
-	price, err := order.Plot.At(time.Now())
-	if err != nil {
-		return domain.Order{}, nil
+// rejectOrder records the price protection rejection on the order and saves it with its plot state
+func (s *Service) rejectOrder(ctx context.Context, order domain.Order, err error) domain.Order {
+	s.logger.Warnf("price of order %s rejected: %v", order.Name, err)
+	order.Rejection = err.Error()
+	if err := s.repo.UpdateOrder(ctx, outbound.UpdateOrderRequest{
+		Order: order,
+	}); err != nil {
+		s.logger.Errorf("error updating rejected order %s: %v", order.Name, err)
 	}
+	return order
+}
 
-	eo, err := exchange.CreateOrder(ctx, outbound.CreateExchangeOrderRequest{
-		Pair:         order.Pair,
-		Type:         order.Type,
-		Side:         order.Side,
-		BaseQuantity: baseQuantity(price, order.BaseQuantity, order.QuoteQuantity),
-		Price:        price,
-		StopPrice:    0,
-	})
+func createOrderRequest(order domain.Order, t time.Time) (outbound.CreateExchangeOrderRequest, error) {
+	prices, err := orderPrices(order, t)
 	if err != nil {
-		return domain.Order{}, err
-	}
-
-	order.ExchangeOrder = eo
-	return order, nil
+		return outbound.CreateExchangeOrderRequest{}, fmt.Errorf("error calculating price of order %s: %w", order.Name, err)
+	}
+
+	return outbound.CreateExchangeOrderRequest{
+		Pair:           order.Pair,
+		Type:           order.Type,
+		Side:           order.Side,
+		PositionSide:   order.PositionSide,
+		BaseQuantity:   baseQuantity(prices.exec(), order.BaseQuantity, order.QuoteQuantity),
+		Price:          prices.price,
+		StopPrice:      prices.stopPrice,
+		StopLimitPrice: prices.stopLimitPrice,
+		ReduceOnly:     order.ReduceOnly,
+		ClosePosition:  order.ClosePosition,
+		TimeInForce:    order.TimeInForce,
+		WorkingType:    order.WorkingType,
+		ClientOrderID:  order.ClientOrderID(),
+	}, nil
 }
 
-func (s *Service) modifyExchangeOrders(ctx context.Context, orders []domain.Order, exchange outbound.Exchange) ([]domain.Order, error) {
-	modified := []domain.Order{}
+// modifyExchangeOrders moves the open exchange orders to the current prices of their plots in a single batch,
+// close position orders follow the amount of their positions if they're known.
+// Modifications violating the risk limits are skipped and the orders are left as they are,
+// like the orders with prices rejected by their protection which are returned with the rejection.
+func (s *Service) modifyExchangeOrders(ctx context.Context, orders []domain.Order, exchange outbound.Exchange, p positions, guard *riskGuard) ([]domain.Order, error) {
+	now := time.Now()
+	open, rejected := []domain.Order{}, []domain.Order{}
+	reqs := []outbound.ModifyExchangeOrderRequest{}
 	for _, order := range orders {
-		if order.ExchangeOrder == nil {
-			return modified, fmt.Errorf("unexpected nil exchange order for order %s", order.ID)
+		// orders that are not placed yet or no longer open are not modified
+		if order.ExchangeOrder == nil || order.ExchangeOrder.Status != domain.OrderStatusActive {
+			continue
 		}
-		order, err := s.modifyExchangeOrder(ctx, order, exchange)
+		req, err := modifyOrderRequest(order, now)
+		if errors.Is(err, geometry.ErrPlotOutOfRange) {
+			continue
+		}
+		if errors.Is(err, geometry.ErrPriceProtection) {
+			rejected = append(rejected, s.rejectOrder(ctx, order, err))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if amount, ok := p.closeAmount(order); ok {
+			// orders of closed positions are canceled instead
+			if amount == 0 {
+				continue
+			}
+			req.BaseQuantity = amount
+		}
+		allowed, err := guard.allow(ctx, order, req.BaseQuantity, req.Price, req.StopPrice)
 		if err != nil {
-			return modified, err
+			return nil, err
+		}
+		if !allowed {
+			continue
+		}
+		open = append(open, order)
+		reqs = append(reqs, req)
+	}
+
+	modified := rejected
+	errs := []error{}
+	for i, res := range modifyOrders(ctx, exchange, reqs) {
+		if res.Err != nil {
+			errs = append(errs, fmt.Errorf("error modifying order %s: %w", open[i].Name, res.Err))
+			continue
+		}
+		order := open[i]
+		order.ExchangeOrder = res.EO
+		order.Rejection = ""
+		// the exchange recreated the order to modify it
+		if res.EO.ClientID != "" && res.EO.ClientID == reqs[i].ClientOrderID {
+			order.Placements++
 		}
 		modified = append(modified, order)
 		if err := s.repo.UpdateOrder(ctx, outbound.UpdateOrderRequest{
 			Order: order,
 		}); err != nil {
-			return modified, err
+			errs = append(errs, err)
 		}
 	}
-	return modified, nil
+	return modified, errors.Join(errs...)
 }
 
-func (s *Service) modifyExchangeOrder(ctx context.Context, order domain.Order, exchange outbound.Exchange) (domain.Order, error) {
-	price, err := order.Plot.At(time.Now())
+func modifyOrderRequest(order domain.Order, t time.Time) (outbound.ModifyExchangeOrderRequest, error) {
+	prices, err := orderPrices(order, t)
 	if err != nil {
-		return domain.Order{}, nil
+		return outbound.ModifyExchangeOrderRequest{}, fmt.Errorf("error calculating price of order %s: %w", order.Name, err)
 	}
 
-	eo, err := exchange.ModifyOrder(ctx, outbound.ModifyExchangeOrderRequest{
-		EO:           order.ExchangeOrder,
-		BaseQuantity: baseQuantity(price, order.BaseQuantity, order.QuoteQuantity),
-		Price:        price,
-		StopPrice:    0,
-	})
-	if err != nil {
-		return domain.Order{}, nil
-	}
+	return outbound.ModifyExchangeOrderRequest{
+		EO:             order.ExchangeOrder,
+		BaseQuantity:   baseQuantity(prices.exec(), order.BaseQuantity, order.QuoteQuantity),
+		Price:          prices.price,
+		StopPrice:      prices.stopPrice,
+		StopLimitPrice: prices.stopLimitPrice,
+		ClientOrderID:  order.ClientOrderID(),
+	}, nil
+}
 
-	order.ExchangeOrder = eo
-	return order, err
+// syncExchangeOrders fetches the current state of open exchange orders and updates them in the repo
+func (s *Service) syncExchangeOrders(ctx context.Context, orders []domain.Order, exchange outbound.Exchange) ([]domain.Order, error) {
+	synced := []domain.Order{}
+	for _, order := range orders {
+		if order.ExchangeOrder == nil || order.ExchangeOrder.Status != domain.OrderStatusActive {
+			continue
+		}
+		eo, err := exchange.GetOrder(ctx, outbound.GetExchangeOrderRequest{
+			EO: *order.ExchangeOrder,
+		})
+		if err != nil {
+			return synced, err
+		}
+		order.ExchangeOrder = eo
+		order.Status = eo.Status
+		synced = append(synced, order)
+		if err := s.repo.UpdateOrder(ctx, outbound.UpdateOrderRequest{
+			Order: order,
+		}); err != nil {
+			return synced, err
+		}
+	}
+	return synced, nil
 }
 
-func (s *Service) getOrders(ctx context.Context, orderIDs []string, exchange outbound.Exchange) ([]domain.Order, error) {
+func (s *Service) getOrders(ctx context.Context, orderIDs []string, env geometry.Env) ([]domain.Order, error) {
 	orders := []domain.Order{}
 	for _, orderID := range orderIDs {
-		order, err := s.getOrder(ctx, orderID, exchange)
+		order, err := s.getOrder(ctx, orderID, env)
 		if err != nil {
 			return orders, nil
 		}
@@ -358,39 +633,90 @@ func (s *Service) getOrders(ctx context.Context, orderIDs []string, exchange out
 	return orders, nil
 }
 
-func (s *Service) getOrder(ctx context.Context, orderID string, exchange outbound.Exchange) (domain.Order, error) {
+// getOrder gets the order from the repo and parses its plot in the env,
+// the state and the protection of the order are set by getOrder.
+func (s *Service) getOrder(ctx context.Context, orderID string, env geometry.Env) (domain.Order, error) {
 	order, err := s.repo.GetOrder(ctx, outbound.GetOrderRequest{
 		OrderID: orderID,
 	})
 	if err != nil {
 		return domain.Order{}, nil
 	}
-	plot, err := order.PlotSpec.Parse()
+	if order.PlotState == nil {
+		order.PlotState = geometry.PlotState{}
+	}
+	env.State = order.PlotState
+	env.Protection = &order.Protection
+	plot, err := order.PlotSpec.ParseEnv(env)
 	if err != nil {
 		return domain.Order{}, nil
 	}
 	order.Plot = plot
+
+	order.LimitPlot, err = parseOptionalPlot(order.LimitPlotSpec, &order.LimitPlotState, env)
+	if err != nil {
+		return domain.Order{}, nil
+	}
+
+	order.StopPlot, err = parseOptionalPlot(order.StopPlotSpec, &order.StopPlotState, env)
+	if err != nil {
+		return domain.Order{}, nil
+	}
+
 	return order, nil
 }
 
-func (s *Service) newIntervalLoop(logger *zap.SugaredLogger, followID string, interval time.Duration, f func(time.Time) error) *intervalLoop {
+// parseOptionalPlot parses additional plots of the order, returns nil if the spec is empty
+func parseOptionalPlot(spec geometry.PlotSpec, state *geometry.PlotState, env geometry.Env) (geometry.Plot, error) {
+	if len(spec) == 0 {
+		return nil, nil
+	}
+	if *state == nil {
+		*state = geometry.PlotState{}
+	}
+	env.State = *state
+	return spec.ParseEnv(env)
+}
+
+func (s *Service) newIntervalLoop(logger *zap.SugaredLogger, followID string, interval time.Duration, mu *sync.Mutex, f func(time.Time) error) *intervalLoop {
 	loop := newIntervalLoop(logger, interval, f)
+	loop.mu = mu
 	s.addLoop(followID, loop)
 	return loop
 }
 
+// lockFollow locks the handler mutex of the follow's loop, so the status can't be changed
+// while the handler runs with the follow it read before. Follows without a loop aren't locked.
+func (s *Service) lockFollow(followID string) (unlock func()) {
+	s.mu.Lock()
+	loop, ok := s.loops[followID]
+	s.mu.Unlock()
+	if !ok {
+		return func() {}
+	}
+
+	loop.mu.Lock()
+	return loop.mu.Unlock
+}
+
 func (s *Service) addLoop(followID string, loop *intervalLoop) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.loops[followID] = loop
 }
 
+func (s *Service) removeLoop(followID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.loops, followID)
+}
+
 func (s *Service) parseFollowReq(ctx context.Context, req inbound.CreateFollowRequest) (domain.Follow, []domain.Order, outbound.Exchange, error) {
 	if err := validate.Struct(req); err != nil {
 		return domain.Follow{}, nil, nil, err
 	}
 
-	exchange, err := parseExchange(s.logger, req.Exchange)
+	exchange, err := s.newExchange(req.Exchange)
 	if err != nil {
 		return domain.Follow{}, nil, nil, fmt.Errorf("error parsing exchange: %v", err)
 	}
@@ -409,33 +735,83 @@ func (s *Service) parseFollowReq(ctx context.Context, req inbound.CreateFollowRe
 		return domain.Follow{}, nil, nil, err
 	}
 
+	// close position orders of exchanges that can read the account are sized to the position
+	_, sized := exchange.(outbound.AccountReader)
+
 	var orderIDs []string
 	var orders []domain.Order
 	for _, cro := range req.Orders {
-		plot, err := cro.PlotSpec.Parse()
+		plotState := geometry.PlotState{}
+		plot, err := cro.PlotSpec.ParseWithState(plotState)
 		if err != nil {
 			return domain.Follow{}, nil, nil, fmt.Errorf("error parsing plot %+v: %w", cro.PlotSpec, err)
 		}
+		limitPlotState := geometry.PlotState{}
+		var limitPlot geometry.Plot
+		if len(cro.LimitPlotSpec) != 0 {
+			if !cro.Type.Closing() || !cro.Type.Limited() {
+				return domain.Follow{}, nil, nil, fmt.Errorf("limit plot is not supported by %s orders", cro.Type)
+			}
+			limitPlot, err = cro.LimitPlotSpec.ParseWithState(limitPlotState)
+			if err != nil {
+				return domain.Follow{}, nil, nil, fmt.Errorf("error parsing limit plot %+v: %w", cro.LimitPlotSpec, err)
+			}
+		}
+		stopPlotState := geometry.PlotState{}
+		var stopPlot geometry.Plot
+		if (len(cro.StopPlotSpec) != 0) != (cro.Type == domain.OrderTypeOCO) {
+			return domain.Follow{}, nil, nil, errors.New("stop plot is required by OCO orders and not supported by other order types")
+		}
+		if len(cro.StopPlotSpec) != 0 {
+			stopPlot, err = cro.StopPlotSpec.ParseWithState(stopPlotState)
+			if err != nil {
+				return domain.Follow{}, nil, nil, fmt.Errorf("error parsing stop plot %+v: %w", cro.StopPlotSpec, err)
+			}
+		}
+		timeInForce, err := parseOrderFlags(cro, sized)
+		if err != nil {
+			return domain.Follow{}, nil, nil, fmt.Errorf("invalid order %s: %w", cro.Name, err)
+		}
+		positionSide, err := parsePositionSide(cro)
+		if err != nil {
+			return domain.Follow{}, nil, nil, fmt.Errorf("invalid order %s: %w", cro.Name, err)
+		}
+		protection := geometry.DefaultProtection
+		if cro.Protection != nil {
+			protection = *cro.Protection
+		}
 		eHash, err := domain.Hash(req.Exchange)
 		if err != nil {
 			return domain.Follow{}, nil, nil, fmt.Errorf("hashing exchange: %w", err)
 		}
 		order := domain.Order{
-			ID:            uuid.NewString(),
-			Name:          cro.Name,
-			Status:        domain.OrderStatusProcessing,
-			Type:          cro.Type,
-			Pair:          pair,
-			Side:          cro.Side,
-			QuoteQuantity: cro.QuoteQuantity,
-			BaseQuantity:  cro.BaseQuantity,
-			ClosePosition: cro.ClosePosition,
-			ReduceOnly:    cro.ClosePosition,
-			Relations:     cro.Relations,
-			PlotSpec:      cro.PlotSpec,
-			Plot:          plot,
-			ExchangeHash:  eHash,
-			ExchangeOrder: nil,
+			ID:             uuid.NewString(),
+			Name:           cro.Name,
+			Status:         domain.OrderStatusProcessing,
+			Type:           cro.Type,
+			Pair:           pair,
+			Side:           cro.Side,
+			PositionSide:   positionSide,
+			QuoteQuantity:  cro.QuoteQuantity,
+			BaseQuantity:   cro.BaseQuantity,
+			ClosePosition:  cro.ClosePosition,
+			ReduceOnly:     cro.ReduceOnly,
+			TimeInForce:    timeInForce,
+			WorkingType:    cro.WorkingType,
+			Relations:      cro.Relations,
+			PlotSpec:       cro.PlotSpec,
+			PlotState:      plotState,
+			Protection:     protection,
+			LimitPlotSpec:  cro.LimitPlotSpec,
+			LimitPlotState: limitPlotState,
+			LimitOffset:    cro.LimitOffset,
+			LimitPlot:      limitPlot,
+			StopPlotSpec:   cro.StopPlotSpec,
+			StopPlotState:  stopPlotState,
+			StopPlot:       stopPlot,
+			Plot:           plot,
+			ExchangeHash:   eHash,
+			ExchangeOrder:  nil,
 		}
 
 		orders = append(orders, order)
@@ -449,12 +825,16 @@ func (s *Service) parseFollowReq(ctx context.Context, req inbound.CreateFollowRe
 
 	follow := domain.Follow{
 		ID:           uuid.NewString(),
-		Status:       domain.FollowStatusPending,
 		ExchangeHash: hash,
 		Pair:         pair,
 		Interval:     interval,
 		WebhookURL:   req.WebhookURL,
 		OrderIDs:     orderIDs,
+		Leverage:     req.Leverage,
+		MarginType:   req.MarginType,
+	}
+	if _, err := follow.Transition(domain.FollowStatusPending, "created", time.Now()); err != nil {
+		return domain.Follow{}, nil, nil, err
 	}
 	if err := validate.Struct(follow); err != nil {
 		return domain.Follow{}, nil, nil, err
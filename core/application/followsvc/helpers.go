@@ -1,10 +1,14 @@
 package followsvc
 
 import (
+	"fmt"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/Plotrader/core/inbound"
+	"github.com/H3Cki/Plotrader/core/outbound"
 	"github.com/pkg/errors"
 )
 
@@ -12,12 +16,37 @@ func orderSideForType(baseSide domain.OrderSide, orderType domain.OrderType) dom
 	switch orderType {
 	case domain.OrderTypeLimit, domain.OrderTypeMarket:
 		return baseSide
-	case domain.OrderTypeTakeProfit, domain.OrderTypeStopLoss:
+	case domain.OrderTypeTakeProfit, domain.OrderTypeStopLoss,
+		domain.OrderTypeTakeProfitLimit, domain.OrderTypeStopLossLimit, domain.OrderTypeOCO:
 		return oppositeSide(baseSide)
 	}
 	panic(baseSide)
 }
 
+// positionSide returns the side of the position the order opens or closes,
+// like in orderSideForType LIMIT and MARKET orders open it and the other order types close it.
+func positionSide(side domain.OrderSide, orderType domain.OrderType) domain.PositionSide {
+	baseSide := side
+	if orderType.Closing() {
+		baseSide = oppositeSide(side)
+	}
+
+	if baseSide == domain.OrderSideBuy {
+		return domain.PositionSideLong
+	}
+	return domain.PositionSideShort
+}
+
+func parsePositionSide(cro inbound.CreateOrderRequest) (domain.PositionSide, error) {
+	switch cro.PositionSide {
+	case "":
+		return positionSide(cro.Side, cro.Type), nil
+	case domain.PositionSideLong, domain.PositionSideShort:
+		return cro.PositionSide, nil
+	}
+	return "", fmt.Errorf("unsupported positionSide: %s", cro.PositionSide)
+}
+
 func oppositeSide(s domain.OrderSide) domain.OrderSide {
 	if s == domain.OrderSideBuy {
 		return domain.OrderSideSell
@@ -25,6 +54,44 @@ func oppositeSide(s domain.OrderSide) domain.OrderSide {
 	return domain.OrderSideBuy
 }
 
+// parseOrderFlags validates the execution flags of the order and returns its time in force, GTC if not specified.
+// Close position orders are limited to triggered market orders unless they're sized to the position of the account.
+func parseOrderFlags(cro inbound.CreateOrderRequest, sized bool) (domain.TimeInForce, error) {
+	if cro.ClosePosition {
+		if !sized && (!cro.Type.Triggered() || cro.Type.Limited()) {
+			return "", fmt.Errorf("closePosition is not supported by %s orders", cro.Type)
+		}
+		if cro.ReduceOnly {
+			return "", errors.New("closePosition can't be used with reduceOnly")
+		}
+		if cro.BaseQuantity != 0 || cro.QuoteQuantity != 0 {
+			return "", errors.New("closePosition can't be used with a quantity")
+		}
+	}
+
+	switch cro.WorkingType {
+	case "":
+	case domain.WorkingTypeMarkPrice, domain.WorkingTypeContractPrice:
+		if !cro.Type.Triggered() {
+			return "", fmt.Errorf("workingType is not supported by %s orders", cro.Type)
+		}
+	default:
+		return "", fmt.Errorf("unsupported workingType: %s", cro.WorkingType)
+	}
+
+	switch cro.TimeInForce {
+	case "":
+		return domain.TimeInForceGTC, nil
+	case domain.TimeInForceGTC, domain.TimeInForceIOC, domain.TimeInForceFOK, domain.TimeInForceGTX:
+		if !cro.Type.Limited() {
+			return "", fmt.Errorf("timeInForce is not supported by %s orders", cro.Type)
+		}
+		return cro.TimeInForce, nil
+	}
+
+	return "", fmt.Errorf("unsupported timeInForce: %s", cro.TimeInForce)
+}
+
 func parsePair(s string) (domain.Pair, error) {
 	bq := strings.Split(s, "-")
 	if len(bq) != 2 {
@@ -37,6 +104,67 @@ func parsePair(s string) (domain.Pair, error) {
 	}, nil
 }
 
+// prices of an exchange order, prices not used by the order type are zero
+type prices struct {
+	price          float64
+	stopPrice      float64
+	stopLimitPrice float64 // stopLimitPrice is the limit price of the stop leg of OCO orders
+}
+
+// exec returns the price the order is expected to be executed at
+func (p prices) exec() float64 {
+	if p.price != 0 {
+		return p.price
+	}
+	return p.stopPrice
+}
+
+// orderPrices calculates the prices of the order at time t,
+// price is zero for triggered market orders and stopPrice is zero for orders without a trigger.
+func orderPrices(order domain.Order, t time.Time) (prices, error) {
+	v, err := order.Plot.At(t)
+	if err != nil {
+		return prices{}, err
+	}
+
+	if order.Type == domain.OrderTypeOCO {
+		stop, err := order.StopPlot.At(t)
+		if err != nil {
+			return prices{}, err
+		}
+		stopLimit, err := stopLimitPrice(order, stop, t)
+		if err != nil {
+			return prices{}, err
+		}
+		return prices{price: v, stopPrice: stop, stopLimitPrice: stopLimit}, nil
+	}
+
+	if !order.Type.Triggered() {
+		return prices{price: v}, nil
+	}
+
+	if !order.Type.Limited() {
+		return prices{stopPrice: v}, nil
+	}
+
+	limit, err := stopLimitPrice(order, v, t)
+	if err != nil {
+		return prices{}, err
+	}
+
+	return prices{price: limit, stopPrice: v}, nil
+}
+
+// stopLimitPrice calculates the limit price of a stop limit order by LimitPlot
+// or by offsetting the stop price by LimitOffset if the order has no LimitPlot.
+func stopLimitPrice(order domain.Order, stopPrice float64, t time.Time) (float64, error) {
+	if order.LimitPlot != nil {
+		return order.LimitPlot.At(t)
+	}
+
+	return stopPrice + stopPrice*order.LimitOffset, nil
+}
+
 func baseQuantity(price, base, quote float64) float64 {
 	if base != 0 {
 		return base
@@ -45,6 +173,38 @@ func baseQuantity(price, base, quote float64) float64 {
 	return quote / price
 }
 
+// followOrders gives plots access to the other orders of the same follow
+type followOrders struct {
+	orders []domain.Order
+}
+
+// FillPrice returns the fill price of the order with given name once its exchange order is done,
+// limit orders without a reported fill price are assumed to be filled at their price.
+func (f *followOrders) FillPrice(orderName string) (float64, bool) {
+	idx := slices.IndexFunc(f.orders, func(o domain.Order) bool {
+		return o.Name == orderName
+	})
+	if idx == -1 {
+		return 0, false
+	}
+
+	order := f.orders[idx]
+	eo := order.ExchangeOrder
+	if eo == nil || eo.Status != domain.OrderStatusDone {
+		return 0, false
+	}
+
+	if eo.FillPrice == 0 {
+		// the price of other orders is a trigger or the price of one of the legs, not where they were filled
+		if order.Type != domain.OrderTypeLimit || eo.Price == 0 {
+			return 0, false
+		}
+		return eo.Price, true
+	}
+
+	return eo.FillPrice, true
+}
+
 func replaceOrders(orig []domain.Order, new []domain.Order) []domain.Order {
 	for _, n := range new {
 		idx := slices.IndexFunc(orig, func(o domain.Order) bool {
@@ -57,3 +217,19 @@ func replaceOrders(orig []domain.Order, new []domain.Order) []domain.Order {
 	}
 	return orig
 }
+
+// rejections returns the orders with prices rejected by their protection
+func rejections(orders []domain.Order) []outbound.OrderRejection {
+	rejected := []outbound.OrderRejection{}
+	for _, order := range orders {
+		if order.Rejection == "" {
+			continue
+		}
+		rejected = append(rejected, outbound.OrderRejection{
+			OrderID: order.ID,
+			Name:    order.Name,
+			Reason:  order.Rejection,
+		})
+	}
+	return rejected
+}
@@ -0,0 +1,140 @@
+package followsvc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/Plotrader/core/domain/geometry"
+	"github.com/H3Cki/Plotrader/core/inbound"
+	"github.com/H3Cki/Plotrader/core/outbound"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFollowOrders_FillPrice(t *testing.T) {
+	orders := &followOrders{orders: []domain.Order{
+		{Name: "entry", ExchangeOrder: &domain.ExchangeOrder{Status: domain.OrderStatusDone, Price: 10, FillPrice: 10.5}},
+		{Name: "limit", Type: domain.OrderTypeLimit, ExchangeOrder: &domain.ExchangeOrder{Status: domain.OrderStatusDone, Price: 20}},
+		{Name: "sl", Type: domain.OrderTypeStopLoss, ExchangeOrder: &domain.ExchangeOrder{Status: domain.OrderStatusDone, StopPrice: 15}},
+		{Name: "oco", Type: domain.OrderTypeOCO, ExchangeOrder: &domain.ExchangeOrder{Status: domain.OrderStatusDone, Price: 25, StopPrice: 15}},
+		{Name: "open", ExchangeOrder: &domain.ExchangeOrder{Status: domain.OrderStatusActive, Price: 30}},
+		{Name: "pending"},
+	}}
+
+	tests := []struct {
+		name   string
+		want   float64
+		wantOk bool
+	}{
+		{"entry", 10.5, true},
+		{"limit", 20, true},
+		{"sl", 0, false},
+		{"oco", 0, false},
+		{"open", 0, false},
+		{"pending", 0, false},
+		{"unknown", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := orders.FillPrice(tt.name)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRejections(t *testing.T) {
+	orders := []domain.Order{
+		{ID: "1", Name: "entry"},
+		{ID: "2", Name: "tp", Rejection: "price protection: price 200.000000 above max price 150.000000"},
+	}
+
+	assert.Equal(t, []outbound.OrderRejection{
+		{OrderID: "2", Name: "tp", Reason: "price protection: price 200.000000 above max price 150.000000"},
+	}, rejections(orders))
+}
+
+func TestOrderPrices(t *testing.T) {
+	now := time.Now()
+	line, err := geometry.NewLine(geometry.Point{Date: now, Price: 100}, geometry.Point{Date: now.Add(time.Hour), Price: 100})
+	assert.NoError(t, err)
+	limitLine, err := geometry.NewLine(geometry.Point{Date: now, Price: 95}, geometry.Point{Date: now.Add(time.Hour), Price: 95})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		order domain.Order
+		want  prices
+	}{
+		{"limit", domain.Order{Type: domain.OrderTypeLimit, Plot: line}, prices{price: 100}},
+		{"stop market", domain.Order{Type: domain.OrderTypeStopLoss, Plot: line}, prices{stopPrice: 100}},
+		{"stop limit offset", domain.Order{Type: domain.OrderTypeStopLossLimit, Plot: line, LimitOffset: -0.01}, prices{price: 99, stopPrice: 100}},
+		{"take profit limit plot", domain.Order{Type: domain.OrderTypeTakeProfitLimit, Plot: line, LimitPlot: limitLine}, prices{price: 95, stopPrice: 100}},
+		{"oco", domain.Order{Type: domain.OrderTypeOCO, Plot: line, StopPlot: limitLine, LimitOffset: -0.01}, prices{price: 100, stopPrice: 95, stopLimitPrice: 94.05}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := orderPrices(tt.order, now)
+			assert.NoError(t, err)
+			assert.InDelta(t, tt.want.price, got.price, 1e-9)
+			assert.InDelta(t, tt.want.stopPrice, got.stopPrice, 1e-9)
+			assert.InDelta(t, tt.want.stopLimitPrice, got.stopLimitPrice, 1e-9)
+		})
+	}
+}
+
+func TestParseOrderFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		cro     inbound.CreateOrderRequest
+		sized   bool
+		want    domain.TimeInForce
+		wantErr bool
+	}{
+		{"default time in force", inbound.CreateOrderRequest{Type: domain.OrderTypeLimit}, false, domain.TimeInForceGTC, false},
+		{"post only", inbound.CreateOrderRequest{Type: domain.OrderTypeLimit, TimeInForce: domain.TimeInForceGTX}, false, domain.TimeInForceGTX, false},
+		{"unknown time in force", inbound.CreateOrderRequest{Type: domain.OrderTypeLimit, TimeInForce: "GTD"}, false, "", true},
+		{"time in force of market stop", inbound.CreateOrderRequest{Type: domain.OrderTypeStopLoss, TimeInForce: domain.TimeInForceIOC}, false, "", true},
+		{"close position", inbound.CreateOrderRequest{Type: domain.OrderTypeStopLoss, ClosePosition: true, WorkingType: domain.WorkingTypeMarkPrice}, false, domain.TimeInForceGTC, false},
+		{"close position of limit", inbound.CreateOrderRequest{Type: domain.OrderTypeLimit, ClosePosition: true}, false, "", true},
+		{"sized close position of limit", inbound.CreateOrderRequest{Type: domain.OrderTypeLimit, ClosePosition: true}, true, domain.TimeInForceGTC, false},
+		{"sized close position with quantity", inbound.CreateOrderRequest{Type: domain.OrderTypeLimit, ClosePosition: true, QuoteQuantity: 1}, true, "", true},
+		{"close position with quantity", inbound.CreateOrderRequest{Type: domain.OrderTypeStopLoss, ClosePosition: true, BaseQuantity: 1}, false, "", true},
+		{"close position and reduce only", inbound.CreateOrderRequest{Type: domain.OrderTypeStopLoss, ClosePosition: true, ReduceOnly: true}, false, "", true},
+		{"working type of limit", inbound.CreateOrderRequest{Type: domain.OrderTypeLimit, WorkingType: domain.WorkingTypeMarkPrice}, false, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseOrderFlags(tt.cro, tt.sized)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParsePositionSide(t *testing.T) {
+	tests := []struct {
+		name    string
+		cro     inbound.CreateOrderRequest
+		want    domain.PositionSide
+		wantErr bool
+	}{
+		{"long entry", inbound.CreateOrderRequest{Type: domain.OrderTypeLimit, Side: domain.OrderSideBuy}, domain.PositionSideLong, false},
+		{"short entry", inbound.CreateOrderRequest{Type: domain.OrderTypeLimit, Side: domain.OrderSideSell}, domain.PositionSideShort, false},
+		{"long stop loss", inbound.CreateOrderRequest{Type: domain.OrderTypeStopLoss, Side: domain.OrderSideSell}, domain.PositionSideLong, false},
+		{"short take profit", inbound.CreateOrderRequest{Type: domain.OrderTypeTakeProfitLimit, Side: domain.OrderSideBuy}, domain.PositionSideShort, false},
+		{"explicit", inbound.CreateOrderRequest{Type: domain.OrderTypeStopLoss, Side: domain.OrderSideBuy, PositionSide: domain.PositionSideLong}, domain.PositionSideLong, false},
+		{"invalid", inbound.CreateOrderRequest{Type: domain.OrderTypeLimit, Side: domain.OrderSideBuy, PositionSide: "BOTH"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePositionSide(tt.cro)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
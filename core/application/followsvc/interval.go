@@ -28,7 +28,8 @@ type intervalLoop struct {
 	execTimes []time.Duration
 	f         func(time.Time) error
 	stopC     chan struct{}
-	mu        *sync.Mutex
+	// mu is held while the handler runs, status changes of the follow take it to wait for the handler
+	mu *sync.Mutex
 }
 
 func newIntervalLoop(logger *zap.SugaredLogger, interval time.Duration, f func(time.Time) error) *intervalLoop {
@@ -0,0 +1,303 @@
+package followsvc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/Plotrader/core/inbound"
+	"github.com/H3Cki/Plotrader/core/outbound"
+)
+
+// RiskConfig configures the limits checked before orders are created or modified
+type RiskConfig struct {
+	Limits domain.RiskLimits `json:"limits"`
+	// Accounts override the limits of exchange accounts, they're keyed by the exchange name
+	// and the API key of the account joined by a colon, e.g. BINANCE_FUTURES:<API_KEY>.
+	Accounts map[string]domain.RiskLimits `json:"accounts"`
+}
+
+// riskEngine enforces the limits across the follows of the service, the notionals of the open orders
+// of every follow are tracked in memory per exchange account.
+type riskEngine struct {
+	cfg     RiskConfig
+	mu      sync.Mutex
+	follows map[string]*followExposure
+	// dayStarts are the wallet balances at the first check of the UTC day, by account and asset
+	dayStarts map[string]walletMark
+	now       func() time.Time
+}
+
+type followExposure struct {
+	account string
+	pair    domain.Pair
+	// notionals of the open orders of the follow by order ID
+	notionals map[string]float64
+}
+
+type walletMark struct {
+	day    time.Time
+	wallet float64
+}
+
+func newRiskEngine(cfg RiskConfig) *riskEngine {
+	return &riskEngine{
+		cfg:       cfg,
+		follows:   map[string]*followExposure{},
+		dayStarts: map[string]walletMark{},
+		now:       time.Now,
+	}
+}
+
+// limits returns the global limits with the overrides of the account
+func (r *riskEngine) limits(account string) domain.RiskLimits {
+	return r.cfg.Limits.Override(r.cfg.Accounts[account])
+}
+
+// register checks the limits of a new follow on the exchange and counts it as open until it's released
+func (r *riskEngine) register(account string, follow domain.Follow, exchange outbound.Exchange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	limits := r.limits(account)
+
+	if !limits.Allows(follow.Pair) {
+		return &domain.RiskViolation{Limit: "symbols", Reason: fmt.Sprintf("%s-%s is not allowed", follow.Pair.Base, follow.Pair.Quote)}
+	}
+
+	// exchanges that can't configure positions trade without leverage
+	_, leveraged := exchange.(outbound.PositionConfigurer)
+	if leveraged && limits.MaxLeverage != 0 && (follow.Leverage == 0 || follow.Leverage > limits.MaxLeverage) {
+		return &domain.RiskViolation{Limit: "maxLeverage", Reason: fmt.Sprintf("leverage %d is not between 1 and %d", follow.Leverage, limits.MaxLeverage)}
+	}
+
+	if limits.MaxOpenFollows != 0 {
+		open := 0
+		for _, f := range r.follows {
+			if f.account == account {
+				open++
+			}
+		}
+		if open >= limits.MaxOpenFollows {
+			return &domain.RiskViolation{Limit: "maxOpenFollows", Reason: fmt.Sprintf("the account has %d open follows", open)}
+		}
+	}
+
+	r.follows[follow.ID] = &followExposure{
+		account:   account,
+		pair:      follow.Pair,
+		notionals: map[string]float64{},
+	}
+	return nil
+}
+
+// release stops counting the follow and the notional of its orders
+func (r *riskEngine) release(followID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.follows, followID)
+}
+
+// sync releases the notional of the orders of the follow that aren't open on the exchange anymore
+func (r *riskEngine) sync(followID string, orders []domain.Order) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, ok := r.follows[followID]
+	if !ok {
+		return
+	}
+
+	for _, order := range orders {
+		if order.ExchangeOrder == nil || order.ExchangeOrder.Status != domain.OrderStatusActive {
+			delete(f.notionals, order.ID)
+		}
+	}
+}
+
+// checkOrder checks the notional of an order of the follow before it's created or modified,
+// the open orders of the account count towards the symbol and account limits. The notional
+// of the order replaces its previous notional once it passes.
+func (r *riskEngine) checkOrder(followID, orderID string, notional float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, ok := r.follows[followID]
+	if !ok {
+		return fmt.Errorf("follow %s isn't registered by the risk engine", followID)
+	}
+
+	limits := r.limits(f.account)
+
+	if limits.MaxOrderNotional != 0 && notional > limits.MaxOrderNotional {
+		return &domain.RiskViolation{Limit: "maxOrderNotional", Reason: fmt.Sprintf("order notional %f exceeds %f", notional, limits.MaxOrderNotional)}
+	}
+
+	symbol, account := notional, notional
+	for id, other := range r.follows {
+		if other.account != f.account {
+			continue
+		}
+		for otherOrderID, n := range other.notionals {
+			if id == followID && otherOrderID == orderID {
+				continue
+			}
+			account += n
+			if other.pair == f.pair {
+				symbol += n
+			}
+		}
+	}
+
+	if limits.MaxSymbolNotional != 0 && symbol > limits.MaxSymbolNotional {
+		return &domain.RiskViolation{Limit: "maxSymbolNotional", Reason: fmt.Sprintf("symbol notional %f exceeds %f", symbol, limits.MaxSymbolNotional)}
+	}
+
+	if limits.MaxAccountNotional != 0 && account > limits.MaxAccountNotional {
+		return &domain.RiskViolation{Limit: "maxAccountNotional", Reason: fmt.Sprintf("account notional %f exceeds %f", account, limits.MaxAccountNotional)}
+	}
+
+	f.notionals[orderID] = notional
+	return nil
+}
+
+// checkLoss checks how much the wallet balance of the asset dropped since the first check of the UTC day,
+// deposits and withdrawals count as profits and losses. Exchanges that can't read the account aren't checked.
+func (r *riskEngine) checkLoss(ctx context.Context, account, asset string, exchange outbound.Exchange) error {
+	limits := r.limits(account)
+	reader, ok := exchange.(outbound.AccountReader)
+	if limits.DailyLossLimit == 0 || !ok {
+		return nil
+	}
+
+	balances, err := reader.Balances(ctx, outbound.BalancesRequest{
+		Asset: asset,
+	})
+	if err != nil {
+		return fmt.Errorf("error getting balance: %w", err)
+	}
+
+	wallet := 0.0
+	for _, balance := range balances {
+		wallet += balance.Wallet
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := account + ":" + asset
+	day := r.now().UTC().Truncate(24 * time.Hour)
+	start, ok := r.dayStarts[key]
+	if !ok || !start.day.Equal(day) {
+		r.dayStarts[key] = walletMark{day: day, wallet: wallet}
+		return nil
+	}
+
+	if loss := start.wallet - wallet; loss > limits.DailyLossLimit {
+		return &domain.RiskViolation{Limit: "dailyLossLimit", Reason: fmt.Sprintf("lost %f %s today, the limit is %f", loss, asset, limits.DailyLossLimit)}
+	}
+
+	return nil
+}
+
+// riskGuard checks the orders of a follow before they're sent to the exchange, orders that reduce
+// the position are never blocked so follows keep protecting their positions after a violation.
+type riskGuard struct {
+	engine   *riskEngine
+	account  string
+	followID string
+	pair     domain.Pair
+	exchange outbound.Exchange
+
+	// state of the current run of the handler
+	lossChecked bool
+	markPrice   float64
+	violation   *domain.RiskViolation
+}
+
+// begin starts a run of the handler, the notional of orders that aren't open anymore is released
+func (g *riskGuard) begin(orders []domain.Order) {
+	if g == nil {
+		return
+	}
+	g.lossChecked, g.markPrice, g.violation = false, 0, nil
+	g.engine.sync(g.followID, orders)
+}
+
+// allow returns false if creating or modifying the order with the quantity and prices violates a limit,
+// the first violation of the run is kept for the handler to report.
+func (g *riskGuard) allow(ctx context.Context, order domain.Order, quantity, price, stopPrice float64) (bool, error) {
+	// only orders that can't open a position are exempt, closing types without reduce only can
+	if g == nil || order.ReduceOnly || order.ClosePosition {
+		return true, nil
+	}
+
+	err := g.check(ctx, order, quantity, price, stopPrice)
+	violation, ok := err.(*domain.RiskViolation)
+	if !ok {
+		return err == nil, err
+	}
+
+	if g.violation == nil {
+		g.violation = violation
+	}
+	return false, nil
+}
+
+// takeViolation returns the first violation of the run and clears it
+func (g *riskGuard) takeViolation() *domain.RiskViolation {
+	if g == nil {
+		return nil
+	}
+	violation := g.violation
+	g.violation = nil
+	return violation
+}
+
+func (g *riskGuard) check(ctx context.Context, order domain.Order, quantity, price, stopPrice float64) error {
+	if !g.lossChecked {
+		if err := g.engine.checkLoss(ctx, g.account, g.pair.Quote, g.exchange); err != nil {
+			return err
+		}
+		g.lossChecked = true
+	}
+
+	if price == 0 {
+		price = stopPrice
+	}
+	// market orders are valued at the mark price
+	if price == 0 {
+		if g.markPrice == 0 {
+			mark, err := g.exchange.MarkPrice(ctx, outbound.MarkPriceRequest{
+				Pair: g.pair,
+			})
+			if err != nil {
+				return fmt.Errorf("error getting mark price: %w", err)
+			}
+			g.markPrice = mark
+		}
+		price = g.markPrice
+	}
+
+	return g.engine.checkOrder(g.followID, order.ID, quantity*price)
+}
+
+// accountKey returns the key of the exchange account of the risk config overrides, the exchange name and the API key
+func accountKey(ex inbound.Exchange) (string, error) {
+	cfg, err := ex.ConfigJSON()
+	if err != nil {
+		return "", err
+	}
+
+	keys := struct {
+		API_KEY string `json:"API_KEY"`
+	}{}
+	if err := json.Unmarshal(cfg, &keys); err != nil {
+		return "", err
+	}
+
+	return ex.Name + ":" + keys.API_KEY, nil
+}
@@ -0,0 +1,154 @@
+package followsvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func violatedLimit(t *testing.T, err error) string {
+	violation, ok := err.(*domain.RiskViolation)
+	if !assert.True(t, ok, "expected a risk violation, got %v", err) {
+		return ""
+	}
+	return violation.Limit
+}
+
+func TestRiskEngine_register(t *testing.T) {
+	r := newRiskEngine(RiskConfig{
+		Limits: domain.RiskLimits{MaxOpenFollows: 1, MaxLeverage: 10, Symbols: []string{"BTC-USDT"}},
+		Accounts: map[string]domain.RiskLimits{
+			"EX:key": {MaxOpenFollows: 2},
+		},
+	})
+	btc := domain.Pair{Base: "BTC", Quote: "USDT"}
+	exchange := &fakeConfigurer{}
+
+	assert.Equal(t, "symbols", violatedLimit(t, r.register("EX:other", domain.Follow{ID: "1", Pair: domain.Pair{Base: "ETH", Quote: "USDT"}, Leverage: 1}, exchange)))
+	assert.Equal(t, "maxLeverage", violatedLimit(t, r.register("EX:other", domain.Follow{ID: "1", Pair: btc}, exchange)))
+	assert.Equal(t, "maxLeverage", violatedLimit(t, r.register("EX:other", domain.Follow{ID: "1", Pair: btc, Leverage: 11}, exchange)))
+
+	assert.NoError(t, r.register("EX:other", domain.Follow{ID: "1", Pair: btc, Leverage: 10}, exchange))
+	assert.Equal(t, "maxOpenFollows", violatedLimit(t, r.register("EX:other", domain.Follow{ID: "2", Pair: btc, Leverage: 1}, exchange)))
+
+	// the account overrides the open follows and keeps the other global limits
+	assert.NoError(t, r.register("EX:key", domain.Follow{ID: "3", Pair: btc, Leverage: 1}, exchange))
+	assert.NoError(t, r.register("EX:key", domain.Follow{ID: "4", Pair: btc, Leverage: 1}, exchange))
+	assert.Equal(t, "maxOpenFollows", violatedLimit(t, r.register("EX:key", domain.Follow{ID: "5", Pair: btc, Leverage: 1}, exchange)))
+
+	r.release("3")
+	assert.NoError(t, r.register("EX:key", domain.Follow{ID: "5", Pair: btc, Leverage: 1}, exchange))
+
+	// exchanges without leverage aren't checked against the leverage limit
+	r.release("1")
+	assert.NoError(t, r.register("EX:other", domain.Follow{ID: "6", Pair: btc}, &fakeOrderer{}))
+}
+
+func TestRiskEngine_checkOrder(t *testing.T) {
+	r := newRiskEngine(RiskConfig{
+		Limits: domain.RiskLimits{MaxOrderNotional: 100, MaxSymbolNotional: 150, MaxAccountNotional: 200},
+	})
+	btc, eth := domain.Pair{Base: "BTC", Quote: "USDT"}, domain.Pair{Base: "ETH", Quote: "USDT"}
+	exchange := &fakeOrderer{}
+	assert.NoError(t, r.register("a", domain.Follow{ID: "btc1", Pair: btc}, exchange))
+	assert.NoError(t, r.register("a", domain.Follow{ID: "btc2", Pair: btc}, exchange))
+	assert.NoError(t, r.register("a", domain.Follow{ID: "eth", Pair: eth}, exchange))
+	assert.NoError(t, r.register("b", domain.Follow{ID: "other", Pair: btc}, exchange))
+
+	assert.Error(t, r.checkOrder("unknown", "o", 1))
+	assert.Equal(t, "maxOrderNotional", violatedLimit(t, r.checkOrder("btc1", "o1", 101)))
+
+	assert.NoError(t, r.checkOrder("btc1", "o1", 100))
+	assert.Equal(t, "maxSymbolNotional", violatedLimit(t, r.checkOrder("btc2", "o2", 51)))
+	assert.NoError(t, r.checkOrder("btc2", "o2", 50))
+	assert.Equal(t, "maxAccountNotional", violatedLimit(t, r.checkOrder("eth", "o3", 51)))
+
+	// other accounts don't count
+	assert.NoError(t, r.checkOrder("other", "o4", 100))
+
+	// modifications replace the notional of the order
+	assert.NoError(t, r.checkOrder("btc1", "o1", 50))
+	assert.NoError(t, r.checkOrder("eth", "o3", 50))
+
+	// orders that aren't open anymore release their notional
+	r.sync("btc1", []domain.Order{{ID: "o1", ExchangeOrder: &domain.ExchangeOrder{Status: domain.OrderStatusDone}}})
+	assert.NoError(t, r.checkOrder("btc2", "o5", 50))
+
+	r.release("btc2")
+	assert.NoError(t, r.checkOrder("eth", "o6", 100))
+}
+
+func TestRiskEngine_checkLoss(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	r := newRiskEngine(RiskConfig{Limits: domain.RiskLimits{DailyLossLimit: 50}})
+	r.now = func() time.Time { return now }
+	exchange := &fakeAccount{available: 1000}
+	ctx := context.Background()
+
+	// exchanges that can't read the account aren't checked
+	assert.NoError(t, r.checkLoss(ctx, "a", "USDT", &fakeOrderer{}))
+
+	assert.NoError(t, r.checkLoss(ctx, "a", "USDT", exchange))
+	exchange.available = 950
+	assert.NoError(t, r.checkLoss(ctx, "a", "USDT", exchange))
+	exchange.available = 949
+	assert.Equal(t, "dailyLossLimit", violatedLimit(t, r.checkLoss(ctx, "a", "USDT", exchange)))
+
+	// the loss starts over the next day
+	now = now.Add(12 * time.Hour)
+	assert.NoError(t, r.checkLoss(ctx, "a", "USDT", exchange))
+}
+
+func TestService_createExchangeOrders_Risk(t *testing.T) {
+	orders := []domain.Order{
+		{ID: "small", Name: "small", Type: domain.OrderTypeLimit, Side: domain.OrderSideBuy, Plot: flatLine(t, 100), BaseQuantity: 1},
+		{ID: "large", Name: "large", Type: domain.OrderTypeLimit, Side: domain.OrderSideBuy, Plot: flatLine(t, 100), BaseQuantity: 2},
+		// reduce only orders are never blocked
+		{ID: "sl", Name: "sl", Type: domain.OrderTypeStopLoss, Side: domain.OrderSideSell, Plot: flatLine(t, 90), BaseQuantity: 5, ReduceOnly: true},
+		// closing types without reduce only can open a position
+		{ID: "tp", Name: "tp", Type: domain.OrderTypeTakeProfit, Side: domain.OrderSideSell, Plot: flatLine(t, 110), BaseQuantity: 5},
+	}
+	follow := domain.Follow{ID: "follow", Pair: domain.Pair{Base: "BTC", Quote: "USDT"}}
+	repo := newMemoryRepo(follow, orders...)
+	s := &Service{logger: zap.NewNop().Sugar(), repo: repo, risk: newRiskEngine(RiskConfig{
+		Limits: domain.RiskLimits{MaxOrderNotional: 150},
+	})}
+	exchange := &fakeAccount{fakeOrderer: fakeOrderer{failPrice: -1}, mark: 50}
+	assert.NoError(t, s.risk.register("a", follow, exchange))
+
+	guard := &riskGuard{engine: s.risk, account: "a", followID: follow.ID, pair: follow.Pair, exchange: exchange}
+	guard.begin(orders)
+
+	created, err := s.createExchangeOrders(context.Background(), orders, exchange, nil, guard)
+	assert.NoError(t, err)
+	names := []string{}
+	for _, order := range created {
+		names = append(names, order.Name)
+	}
+	assert.Equal(t, []string{"small", "sl"}, names)
+
+	violation := guard.takeViolation()
+	if assert.NotNil(t, violation) {
+		assert.Equal(t, "maxOrderNotional", violation.Limit)
+	}
+	assert.Nil(t, guard.takeViolation())
+
+	// orders without prices are valued at the mark price
+	allowed, err := guard.allow(context.Background(), orders[1], 2, 0, 0)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestRiskLimits_Override(t *testing.T) {
+	global := domain.RiskLimits{MaxOrderNotional: 100, MaxLeverage: 10, Symbols: []string{"BTC-USDT"}}
+	limits := global.Override(domain.RiskLimits{MaxLeverage: 5, DailyLossLimit: 20})
+
+	assert.Equal(t, domain.RiskLimits{MaxOrderNotional: 100, MaxLeverage: 5, DailyLossLimit: 20, Symbols: []string{"BTC-USDT"}}, limits)
+	assert.True(t, limits.Allows(domain.Pair{Base: "BTC", Quote: "USDT"}))
+	assert.False(t, limits.Allows(domain.Pair{Base: "ETH", Quote: "USDT"}))
+	assert.True(t, domain.RiskLimits{}.Allows(domain.Pair{Base: "ETH", Quote: "USDT"}))
+}
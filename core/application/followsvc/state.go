@@ -0,0 +1,94 @@
+package followsvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/Plotrader/core/inbound"
+	"github.com/H3Cki/Plotrader/core/outbound"
+)
+
+// errFollowEnded is returned by the loop handler of follows with a final status, it ends the loop without failing the follow
+var errFollowEnded = errors.New("follow ended")
+
+// transition changes the status of the follow, saves it and publishes the transition
+func (s *Service) transition(ctx context.Context, follow domain.Follow, to domain.FollowStatus, reason string) (domain.Follow, error) {
+	transition, err := follow.Transition(to, reason, time.Now())
+	if err != nil {
+		return follow, err
+	}
+
+	if err := s.repo.UpdateFollow(ctx, outbound.UpdateFollowRequest{
+		Follow: follow,
+	}); err != nil {
+		return follow, fmt.Errorf("error updating follow: %w", err)
+	}
+
+	s.logger.Infof("follow %s transitioned from %s to %s: %s", follow.ID, transition.From, transition.To, reason)
+
+	if err := s.publisher.PublishFollowUpdate(ctx, outbound.FollowUpdate{
+		Follow:     follow,
+		Transition: &transition,
+	}); err != nil {
+		return follow, fmt.Errorf("error publishing transition: %w", err)
+	}
+
+	return follow, nil
+}
+
+// transitionFollow transitions the follow stored in the repository
+func (s *Service) transitionFollow(ctx context.Context, followID string, to domain.FollowStatus, reason string) (domain.Follow, error) {
+	follow, err := s.repo.GetFollow(ctx, outbound.GetFollowRequest{
+		FollowID: followID,
+	})
+	if err != nil {
+		return domain.Follow{}, fmt.Errorf("error getting follow from repo: %w", err)
+	}
+	return s.transition(ctx, follow, to, reason)
+}
+
+// failFollow marks the follow as failed after its loop ended with an error
+func (s *Service) failFollow(ctx context.Context, followID string, cause error) {
+	if _, err := s.transitionFollow(ctx, followID, domain.FollowStatusFailed, cause.Error()); err != nil {
+		s.logger.Errorf("error failing follow %s: %v", followID, err)
+	}
+}
+
+func (s *Service) pauseFollow(ctx context.Context, req inbound.PauseFollowRequest) error {
+	if err := validate.Struct(req); err != nil {
+		return err
+	}
+	unlock := s.lockFollow(req.FollowID)
+	defer unlock()
+	_, err := s.transitionFollow(ctx, req.FollowID, domain.FollowStatusPaused, "paused")
+	return err
+}
+
+func (s *Service) resumeFollow(ctx context.Context, req inbound.ResumeFollowRequest) error {
+	if err := validate.Struct(req); err != nil {
+		return err
+	}
+	unlock := s.lockFollow(req.FollowID)
+	defer unlock()
+	_, err := s.transitionFollow(ctx, req.FollowID, domain.FollowStatusActive, "resumed")
+	return err
+}
+
+// completed returns true if none of the orders can be placed or filled anymore
+func completed(orders []domain.Order) bool {
+	for _, order := range orders {
+		if order.Status == domain.OrderStatusCanceled {
+			continue
+		}
+		if order.ExchangeOrder == nil {
+			return false
+		}
+		if order.ExchangeOrder.Status != domain.OrderStatusDone && order.ExchangeOrder.Status != domain.OrderStatusCanceled {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,156 @@
+package followsvc
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/Plotrader/core/inbound"
+	"github.com/H3Cki/Plotrader/core/outbound"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// fakePublisher records the published updates
+type fakePublisher struct {
+	updates []outbound.FollowUpdate
+}
+
+func (f *fakePublisher) PublishFollowUpdate(_ context.Context, update outbound.FollowUpdate) error {
+	f.updates = append(f.updates, update)
+	return nil
+}
+
+func TestFollow_Transition(t *testing.T) {
+	now := time.Now()
+	follow := domain.Follow{}
+
+	for _, to := range []domain.FollowStatus{domain.FollowStatusPending, domain.FollowStatusActive, domain.FollowStatusPaused, domain.FollowStatusActive, domain.FollowStatusStopped} {
+		_, err := follow.Transition(to, "", now)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, domain.FollowStatusStopped, follow.Status)
+	assert.Len(t, follow.Transitions, 5)
+	assert.Equal(t, domain.FollowTransition{From: domain.FollowStatusPaused, To: domain.FollowStatusActive, Time: now}, follow.Transitions[3])
+
+	// final statuses can't change
+	_, err := follow.Transition(domain.FollowStatusActive, "", now)
+	assert.ErrorIs(t, err, domain.ErrInvalidTransition)
+	assert.Equal(t, domain.FollowStatusStopped, follow.Status)
+	assert.Len(t, follow.Transitions, 5)
+
+	assert.False(t, domain.FollowStatusPending.CanTransition(domain.FollowStatusPaused))
+	assert.False(t, domain.FollowStatusPaused.CanTransition(domain.FollowStatusCompleted))
+	assert.True(t, domain.FollowStatusRiskError.CanTransition(domain.FollowStatusActive))
+}
+
+func TestCompleted(t *testing.T) {
+	done := &domain.ExchangeOrder{Status: domain.OrderStatusDone}
+	canceled := &domain.ExchangeOrder{Status: domain.OrderStatusCanceled}
+	active := &domain.ExchangeOrder{Status: domain.OrderStatusActive}
+
+	assert.True(t, completed([]domain.Order{{ExchangeOrder: done}, {ExchangeOrder: canceled}, {Status: domain.OrderStatusCanceled}}))
+	assert.False(t, completed([]domain.Order{{ExchangeOrder: done}, {ExchangeOrder: active}}))
+	// orders that weren't placed yet may still be
+	assert.False(t, completed([]domain.Order{{ExchangeOrder: done}, {}}))
+}
+
+func TestService_pauseFollow(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryRepo(domain.Follow{ID: "follow", Status: domain.FollowStatusActive})
+	publisher := &fakePublisher{}
+	s := &Service{logger: zap.NewNop().Sugar(), repo: repo, publisher: publisher, mu: &sync.Mutex{}}
+
+	assert.NoError(t, s.PauseFollow(ctx, inbound.PauseFollowRequest{FollowID: "follow"}))
+	assert.ErrorIs(t, s.PauseFollow(ctx, inbound.PauseFollowRequest{FollowID: "follow"}), domain.ErrInvalidTransition)
+
+	// paused follows don't touch the exchange
+	handler := s.loopHandler(ctx, "follow", nil, nil)
+	assert.NoError(t, handler(time.Now()))
+
+	assert.NoError(t, s.ResumeFollow(ctx, inbound.ResumeFollowRequest{FollowID: "follow"}))
+
+	resp, err := s.ListFollowTransitions(ctx, inbound.ListFollowTransitionsRequest{FollowID: "follow"})
+	assert.NoError(t, err)
+	if assert.Len(t, resp.Transitions, 2) {
+		assert.Equal(t, domain.FollowStatusPaused, resp.Transitions[0].To)
+		assert.Equal(t, "paused", resp.Transitions[0].Reason)
+		assert.Equal(t, domain.FollowStatusActive, resp.Transitions[1].To)
+	}
+
+	// every transition is published
+	if assert.Len(t, publisher.updates, 2) {
+		assert.Equal(t, resp.Transitions[1], *publisher.updates[1].Transition)
+		assert.Equal(t, domain.FollowStatusActive, publisher.updates[1].Status)
+	}
+
+	// the loop of ended follows stops
+	_, err = s.transitionFollow(ctx, "follow", domain.FollowStatusStopped, "stopped")
+	assert.NoError(t, err)
+	assert.ErrorIs(t, handler(time.Now()), errFollowEnded)
+}
+
+func TestService_pauseFollow_WaitsForHandler(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryRepo(domain.Follow{ID: "follow", Status: domain.FollowStatusActive})
+	s := &Service{logger: zap.NewNop().Sugar(), repo: repo, publisher: &fakePublisher{}, mu: &sync.Mutex{}, loops: map[string]*intervalLoop{}}
+
+	mu := &sync.Mutex{}
+	s.newIntervalLoop(s.logger, "follow", time.Hour, mu, nil)
+
+	// the handler is running with the follow it read before the pause
+	mu.Lock()
+	stale, _ := repo.GetFollow(ctx, outbound.GetFollowRequest{FollowID: "follow"})
+
+	paused := make(chan error)
+	go func() {
+		paused <- s.PauseFollow(ctx, inbound.PauseFollowRequest{FollowID: "follow"})
+	}()
+
+	select {
+	case <-paused:
+		t.Fatal("the follow was paused while the handler was running")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	_, err := s.transition(ctx, stale, domain.FollowStatusRiskError, "risk")
+	assert.NoError(t, err)
+	mu.Unlock()
+
+	// the pause applies to the status the handler saved
+	assert.NoError(t, <-paused)
+	follow, _ := repo.GetFollow(ctx, outbound.GetFollowRequest{FollowID: "follow"})
+	assert.Equal(t, domain.FollowStatusPaused, follow.Status)
+	assert.Len(t, follow.Transitions, 2)
+}
+
+func TestService_failFollow(t *testing.T) {
+	repo := newMemoryRepo(domain.Follow{ID: "follow", Status: domain.FollowStatusActive})
+	s := &Service{logger: zap.NewNop().Sugar(), repo: repo, publisher: &fakePublisher{}}
+
+	s.failFollow(context.Background(), "follow", assert.AnError)
+
+	follow, _ := repo.GetFollow(context.Background(), outbound.GetFollowRequest{FollowID: "follow"})
+	assert.Equal(t, domain.FollowStatusFailed, follow.Status)
+	assert.Equal(t, assert.AnError.Error(), follow.Transitions[0].Reason)
+}
+
+func TestService_stopFollow_CancelOrders(t *testing.T) {
+	ctx := context.Background()
+	registry := outbound.NewExchangeRegistry()
+	registry.Register("FAKE", func([]byte) (outbound.Exchange, error) {
+		return &fakeOrderer{}, nil
+	})
+	repo := newMemoryRepo(domain.Follow{ID: "follow", Status: domain.FollowStatusActive})
+	s := &Service{logger: zap.NewNop().Sugar(), repo: repo, publisher: &fakePublisher{}, exchanges: registry, mu: &sync.Mutex{}, loops: map[string]*intervalLoop{}}
+
+	// errors before canceling the orders aren't lost
+	err := s.StopFollow(ctx, inbound.StopFollowRequest{Exchange: inbound.Exchange{Name: "FAKE"}, FollowID: "follow", CancelOrders: true})
+	assert.ErrorContains(t, err, "not found in active loops")
+
+	follow, err := repo.GetFollow(ctx, outbound.GetFollowRequest{FollowID: "follow"})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.FollowStatusStopped, follow.Status)
+}
@@ -0,0 +1,68 @@
+package plotsvc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/H3Cki/Plotrader/core/domain/geometry"
+	"github.com/H3Cki/Plotrader/core/inbound"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+var validate = validator.New()
+
+type Config struct {
+	Logger *zap.SugaredLogger
+}
+
+type Service struct {
+	logger *zap.SugaredLogger
+}
+
+func New(cfg Config) *Service {
+	return &Service{
+		logger: cfg.Logger,
+	}
+}
+
+func (s *Service) ListPlotTypes(ctx context.Context, req inbound.ListPlotTypesRequest) (inbound.ListPlotTypesResponse, error) {
+	return inbound.ListPlotTypesResponse{
+		PlotTypes: geometry.PlotTypes(),
+	}, nil
+}
+
+func (s *Service) FindCrossings(ctx context.Context, req inbound.FindCrossingsRequest) (inbound.FindCrossingsResponse, error) {
+	if err := validate.Struct(req); err != nil {
+		return inbound.FindCrossingsResponse{}, err
+	}
+
+	a, err := req.A.Parse()
+	if err != nil {
+		return inbound.FindCrossingsResponse{}, fmt.Errorf("error parsing plot a: %w", err)
+	}
+
+	b, err := req.B.Parse()
+	if err != nil {
+		return inbound.FindCrossingsResponse{}, fmt.Errorf("error parsing plot b: %w", err)
+	}
+
+	from, err := geometry.ParseTime(req.From)
+	if err != nil {
+		return inbound.FindCrossingsResponse{}, err
+	}
+
+	to, err := geometry.ParseTime(req.To)
+	if err != nil {
+		return inbound.FindCrossingsResponse{}, err
+	}
+
+	crossings, err := geometry.Crossings(a, b, from, to)
+	if err != nil {
+		return inbound.FindCrossingsResponse{}, err
+	}
+
+	return inbound.FindCrossingsResponse{
+		Crossings: crossings,
+	}, nil
+}
@@ -0,0 +1,17 @@
+package domain
+
+type Balance struct {
+	Asset  string  `json:"asset"`
+	Wallet float64 `json:"wallet"`
+	// Available is the part of the balance that can be used by new orders, account updates of event streams don't include it
+	Available float64 `json:"available"`
+}
+
+type Position struct {
+	Symbol string `json:"symbol"`
+	// PositionSide is empty for accounts in one-way position mode
+	PositionSide PositionSide `json:"positionSide"`
+	// Amount is negative for short positions in one-way position mode
+	Amount     float64 `json:"amount"`
+	EntryPrice float64 `json:"entryPrice"`
+}
@@ -2,7 +2,9 @@ package domain
 
 import (
 	"crypto/md5"
+	"errors"
 	"fmt"
+	"slices"
 	"time"
 )
 
@@ -11,7 +13,51 @@ type FollowStatus string
 var (
 	FollowStatusPending FollowStatus = "PENDING"
 	FollowStatusActive  FollowStatus = "ACTIVE"
-	FollowStatusStopped FollowStatus = "STOPPED"
+	// FollowStatusPaused follows keep their orders on the exchange but don't update them
+	FollowStatusPaused FollowStatus = "PAUSED"
+	// FollowStatusRiskError is the status of follows with orders blocked by a risk limit
+	FollowStatusRiskError FollowStatus = "RISK_ERROR"
+	// FollowStatusCompleted follows have no open orders left
+	FollowStatusCompleted FollowStatus = "COMPLETED"
+	FollowStatusStopped   FollowStatus = "STOPPED"
+	FollowStatusFailed    FollowStatus = "FAILED"
+)
+
+var ErrInvalidTransition = errors.New("invalid follow status transition")
+
+// followTransitions are the statuses follows can transition to from every status,
+// new follows transition from the empty status.
+var followTransitions = map[FollowStatus][]FollowStatus{
+	"":                    {FollowStatusPending},
+	FollowStatusPending:   {FollowStatusActive, FollowStatusStopped, FollowStatusFailed},
+	FollowStatusActive:    {FollowStatusPaused, FollowStatusRiskError, FollowStatusCompleted, FollowStatusStopped, FollowStatusFailed},
+	FollowStatusPaused:    {FollowStatusActive, FollowStatusStopped, FollowStatusFailed},
+	FollowStatusRiskError: {FollowStatusActive, FollowStatusPaused, FollowStatusCompleted, FollowStatusStopped, FollowStatusFailed},
+}
+
+// CanTransition returns true if follows with the status can transition to the other status
+func (s FollowStatus) CanTransition(to FollowStatus) bool {
+	return slices.Contains(followTransitions[s], to)
+}
+
+// Ended returns true if the status is final
+func (s FollowStatus) Ended() bool {
+	return s == FollowStatusCompleted || s == FollowStatusStopped || s == FollowStatusFailed
+}
+
+// FollowTransition is a change of the status of a follow
+type FollowTransition struct {
+	From   FollowStatus `json:"from"`
+	To     FollowStatus `json:"to"`
+	Reason string       `json:"reason"`
+	Time   time.Time    `json:"time"`
+}
+
+type MarginType string
+
+var (
+	MarginTypeIsolated MarginType = "ISOLATED"
+	MarginTypeCrossed  MarginType = "CROSSED"
 )
 
 type ExchangeOrder struct {
@@ -20,8 +66,21 @@ type ExchangeOrder struct {
 	Type         string
 	Symbol       string
 	Side         string
+	PositionSide string
 	Price        float64
-	BaseQuantity float64
+	StopPrice    float64
+	// StopLimitPrice is the limit price of the stop leg of OCO orders
+	StopLimitPrice float64
+	BaseQuantity   float64
+	FillPrice      float64
+
+	ReduceOnly    bool
+	ClosePosition bool
+	TimeInForce   string
+	WorkingType   string
+
+	// ClientID is the client order ID the order was placed with, empty if the exchange doesn't return one
+	ClientID string
 }
 
 type Follow struct {
@@ -32,6 +91,30 @@ type Follow struct {
 	Interval     time.Duration `json:"interval"`
 	WebhookURL   string        `json:"webhookURL"`
 	OrderIDs     []string      `json:"orderIDs"`
+	// Leverage and MarginType are applied to the pair before the first order is placed, zero values keep the account settings
+	Leverage   int        `json:"leverage"`
+	MarginType MarginType `json:"marginType"`
+	// RiskViolation is the limit blocking orders of follows with the risk error status
+	RiskViolation *RiskViolation `json:"riskViolation,omitempty"`
+	// Transitions is the history of the status, oldest first
+	Transitions []FollowTransition `json:"transitions"`
+}
+
+// Transition changes the status of the follow and records the transition in its history
+func (f *Follow) Transition(to FollowStatus, reason string, t time.Time) (FollowTransition, error) {
+	if !f.Status.CanTransition(to) {
+		return FollowTransition{}, fmt.Errorf("%w: %s to %s", ErrInvalidTransition, f.Status, to)
+	}
+
+	transition := FollowTransition{
+		From:   f.Status,
+		To:     to,
+		Reason: reason,
+		Time:   t,
+	}
+	f.Status = to
+	f.Transitions = append(f.Transitions, transition)
+	return transition, nil
 }
 
 type Pair struct {
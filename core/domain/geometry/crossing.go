@@ -0,0 +1,170 @@
+package geometry
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+var (
+	// crossingSamples is the number of segments the window is split into when searching for crossings numerically
+	crossingSamples = 1000
+	// crossingPrecision is the max error of crossing times found numerically
+	crossingPrecision = time.Second
+)
+
+// Crossing is a point where two plots cross
+type Crossing struct {
+	Date  time.Time `json:"date"`
+	Price float64   `json:"price"`
+}
+
+// Crossings returns the points in the [from, to] window where the plots cross, sorted by date.
+// Crossings of Line and LogLine pairs are calculated analytically, other plots are sampled and
+// the crossings are found with bisection, so a plot crossing and recrossing within a single sample may be missed.
+func Crossings(a, b Plot, from, to time.Time) ([]Crossing, error) {
+	if to.Before(from) {
+		return nil, errors.New("window end is before its start")
+	}
+
+	if xs, ok := analyticCrossings(a, b); ok {
+		crossings := []Crossing{}
+		for _, x := range xs {
+			if x < timeToFloat64(from) || x > timeToFloat64(to) {
+				continue
+			}
+			c, err := crossingAt(a, floatToTime(x))
+			if err != nil {
+				return nil, err
+			}
+			crossings = append(crossings, c)
+		}
+		return crossings, nil
+	}
+
+	return numericCrossings(a, b, from, to)
+}
+
+// analyticCrossings returns the crossings of two lines as seconds since epoch, ok is false if the plots are not
+// a pair of lines of the same kind. Parallel lines never cross, identical lines are not considered crossing.
+func analyticCrossings(a, b Plot) (xs []float64, ok bool) {
+	switch la := a.(type) {
+	case *Line:
+		lb, isLine := b.(*Line)
+		if !isLine {
+			return nil, false
+		}
+		if la.A == lb.A {
+			return nil, true
+		}
+		return []float64{(lb.B - la.B) / (la.A - lb.A)}, true
+	case *LogLine:
+		lb, isLogLine := b.(*LogLine)
+		if !isLogLine {
+			return nil, false
+		}
+		if la.M == lb.M {
+			return nil, true
+		}
+		// log10(Ka) + Ma(x - Xa) = log10(Kb) + Mb(x - Xb)
+		return []float64{(math.Log10(lb.K) - math.Log10(la.K) + la.M*la.Xoffset - lb.M*lb.Xoffset) / (la.M - lb.M)}, true
+	}
+	return nil, false
+}
+
+func numericCrossings(a, b Plot, from, to time.Time) ([]Crossing, error) {
+	step := to.Sub(from) / time.Duration(crossingSamples)
+	if step < crossingPrecision {
+		step = crossingPrecision
+	}
+
+	crossings := []Crossing{}
+	var prevT time.Time
+	var prevDiff float64
+	prevOk := false
+
+	for t := from; !t.After(to); t = t.Add(step) {
+		diff, ok, err := plotDiff(a, b, t)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case !ok:
+		case diff == 0:
+			c, err := crossingAt(a, t)
+			if err != nil {
+				return nil, err
+			}
+			crossings = append(crossings, c)
+		case prevOk && prevDiff != 0 && (diff > 0) != (prevDiff > 0):
+			c, err := bisectCrossing(a, b, prevT, t, prevDiff)
+			if err != nil {
+				return nil, err
+			}
+			crossings = append(crossings, c)
+		}
+
+		prevT, prevDiff, prevOk = t, diff, ok
+	}
+
+	return crossings, nil
+}
+
+// bisectCrossing narrows down the crossing between lo and hi, loDiff is the difference of the plots at lo
+func bisectCrossing(a, b Plot, lo, hi time.Time, loDiff float64) (Crossing, error) {
+	for hi.Sub(lo) > crossingPrecision {
+		mid := lo.Add(hi.Sub(lo) / 2)
+		diff, ok, err := plotDiff(a, b, mid)
+		if err != nil {
+			return Crossing{}, err
+		}
+		if !ok {
+			// one of the plots has a gap, there is no crossing inside of it
+			break
+		}
+		if diff == 0 {
+			return crossingAt(a, mid)
+		}
+		if (diff > 0) == (loDiff > 0) {
+			lo, loDiff = mid, diff
+		} else {
+			hi = mid
+		}
+	}
+	return crossingAt(a, hi)
+}
+
+// plotDiff returns a(t) - b(t), ok is false if any of the plots is out of range
+func plotDiff(a, b Plot, t time.Time) (float64, bool, error) {
+	va, err := a.At(t)
+	if errors.Is(err, ErrPlotOutOfRange) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	vb, err := b.At(t)
+	if errors.Is(err, ErrPlotOutOfRange) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	return va - vb, true, nil
+}
+
+func crossingAt(p Plot, t time.Time) (Crossing, error) {
+	price, err := p.At(t)
+	if err != nil {
+		return Crossing{}, err
+	}
+	return Crossing{Date: t, Price: price}, nil
+}
+
+func floatToTime(x float64) time.Time {
+	sec, frac := math.Modf(x)
+	return time.Unix(int64(sec), int64(frac*1e9))
+}
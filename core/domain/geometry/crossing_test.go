@@ -0,0 +1,111 @@
+package geometry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/domain/geometry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrossings(t *testing.T) {
+	base := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	day := func(d float64) time.Time { return base.Add(time.Duration(d * float64(24*time.Hour))) }
+	line := func(d0, p0, d1, p1 float64) *geometry.Line {
+		l, _ := geometry.NewLine(geometry.Point{day(d0), p0}, geometry.Point{day(d1), p1})
+		return l
+	}
+	logLine := func(d0, p0, d1, p1 float64) *geometry.LogLine {
+		l, _ := geometry.NewLogLine(geometry.Point{day(d0), p0}, geometry.Point{day(d1), p1})
+		return l
+	}
+
+	// descending resistance and ascending support meeting at day 10, price 50
+	resistance := line(0, 100, 10, 50)
+	support := line(0, 0, 10, 50)
+
+	tests := []struct {
+		name     string
+		a, b     geometry.Plot
+		from, to time.Time
+		want     []time.Time
+	}{
+		{
+			name: "lines",
+			a:    resistance,
+			b:    support,
+			from: day(0),
+			to:   day(30),
+			want: []time.Time{day(10)},
+		},
+		{
+			name: "lines - outside window",
+			a:    resistance,
+			b:    support,
+			from: day(0),
+			to:   day(5),
+			want: []time.Time{},
+		},
+		{
+			name: "parallel lines",
+			a:    line(0, 100, 10, 50),
+			b:    line(0, 90, 10, 40),
+			from: day(0),
+			to:   day(30),
+			want: []time.Time{},
+		},
+		{
+			name: "log lines",
+			a:    logLine(0, 1, 10, 1000),
+			b:    logLine(0, 100, 10, 1000),
+			from: day(0),
+			to:   day(30),
+			want: []time.Time{day(10)},
+		},
+		{
+			name: "composite",
+			a:    &geometry.Max{Plots: []geometry.Plot{resistance}},
+			b:    support,
+			from: day(0),
+			to:   day(30),
+			want: []time.Time{day(10)},
+		},
+		{
+			name: "composite - multiple crossings",
+			a:    &geometry.Min{Plots: []geometry.Plot{line(0, 0, 10, 100), line(0, 200, 10, 100)}},
+			b:    line(0, 60, 1, 60),
+			from: day(0),
+			to:   day(30),
+			want: []time.Time{day(6), day(14)},
+		},
+		{
+			name: "composite - gap",
+			a:    &geometry.Limit{Plot: resistance, To: day(8)},
+			b:    support,
+			from: day(0),
+			to:   day(30),
+			want: []time.Time{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crossings, err := geometry.Crossings(tt.a, tt.b, tt.from, tt.to)
+			assert.NoError(t, err)
+			assert.Len(t, crossings, len(tt.want))
+
+			for i, c := range crossings {
+				assert.WithinDuration(t, tt.want[i], c.Date, time.Second)
+
+				vb, err := tt.b.At(c.Date)
+				assert.NoError(t, err)
+				assert.InDelta(t, vb, c.Price, 0.01)
+			}
+		})
+	}
+}
+
+func TestCrossings_InvalidWindow(t *testing.T) {
+	_, err := geometry.Crossings(&geometry.Line{}, &geometry.Line{A: 1}, time.Unix(10, 0), time.Unix(0, 0))
+	assert.Error(t, err)
+}
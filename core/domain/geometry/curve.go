@@ -0,0 +1,273 @@
+package geometry
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// SpanMode tells curves what to return outside of the span of their points
+type SpanMode string
+
+var (
+	// SpanModeError makes the curve out of range before the first and after the last point
+	SpanModeError SpanMode = "error"
+	// SpanModeClamp returns the price of the nearest end point
+	SpanModeClamp SpanMode = "clamp"
+	// SpanModeExtrapolate extends the curve along the tangent at the nearest end point
+	SpanModeExtrapolate SpanMode = "extrapolate"
+)
+
+func parseSpanMode(s SpanMode) (SpanMode, error) {
+	switch s {
+	case "":
+		return SpanModeError, nil
+	case SpanModeError, SpanModeClamp, SpanModeExtrapolate:
+		return s, nil
+	}
+	return "", fmt.Errorf("unknown span mode: %s", s)
+}
+
+// curveEnds holds the data needed to evaluate a curve outside of its span,
+// x is the time in seconds and y is the price or its log10.
+type curveEnds struct {
+	X0, Y0, M0 float64
+	Xn, Yn, Mn float64
+	Span       SpanMode
+}
+
+// outside returns the value of the curve at x if x is outside of the span, ok is false otherwise
+func (c curveEnds) outside(x float64) (y float64, ok bool, err error) {
+	var xEnd, yEnd, m float64
+	switch {
+	case x < c.X0:
+		xEnd, yEnd, m = c.X0, c.Y0, c.M0
+	case x > c.Xn:
+		xEnd, yEnd, m = c.Xn, c.Yn, c.Mn
+	default:
+		return 0, false, nil
+	}
+
+	switch c.Span {
+	case SpanModeClamp:
+		return yEnd, true, nil
+	case SpanModeExtrapolate:
+		return yEnd + m*(x-xEnd), true, nil
+	}
+	return 0, true, ErrPlotOutOfRange
+}
+
+// curveCoordinates converts the points to sorted x, y coordinates, y is log10 of the price if log is true.
+// Returns error if there are fewer than minPoints points or any two points share the same date.
+func curveCoordinates(points []Point, minPoints int, log bool) ([]float64, []float64, error) {
+	if len(points) < minPoints {
+		return nil, nil, fmt.Errorf("at least %d points are required, got: %d", minPoints, len(points))
+	}
+
+	points = sortPoints(append([]Point{}, points...)...)
+
+	xs := make([]float64, len(points))
+	ys := make([]float64, len(points))
+	for i, p := range points {
+		if i > 0 && p.Date.Equal(points[i-1].Date) {
+			return nil, nil, errors.New("points have to have distinct dates")
+		}
+		if log && p.Price <= 0 {
+			return nil, nil, fmt.Errorf("non-positive price %f on a logarithmic scale", p.Price)
+		}
+
+		xs[i] = timeToFloat64(p.Date)
+		ys[i] = p.Price
+		if log {
+			ys[i] = math.Log10(p.Price)
+		}
+	}
+
+	return xs, ys, nil
+}
+
+func curveValue(y float64, log bool) float64 {
+	if log {
+		return math.Pow(10, y)
+	}
+	return y
+}
+
+// Spline is a monotone cubic spline going through all of its points (Fritsch-Carlson),
+// it never overshoots the points so it doesn't create highs and lows that aren't on the chart.
+type Spline struct {
+	Xs, Ys, Tangents []float64
+	Ends             curveEnds
+	Log              bool
+}
+
+// NewSpline is a constructor for Spline, requires at least 2 points with distinct dates
+func NewSpline(points []Point, span SpanMode) (*Spline, error) {
+	return newSpline(points, span, false)
+}
+
+// NewLogSpline is a Spline drawn on a logarithmic price scale
+func NewLogSpline(points []Point, span SpanMode) (*Spline, error) {
+	return newSpline(points, span, true)
+}
+
+func newSpline(points []Point, span SpanMode, log bool) (*Spline, error) {
+	span, err := parseSpanMode(span)
+	if err != nil {
+		return nil, fmt.Errorf("error creating spline: %w", err)
+	}
+
+	xs, ys, err := curveCoordinates(points, 2, log)
+	if err != nil {
+		return nil, fmt.Errorf("error creating spline: %w", err)
+	}
+
+	n := len(xs)
+	deltas := make([]float64, n-1)
+	for k := 0; k < n-1; k++ {
+		deltas[k] = (ys[k+1] - ys[k]) / (xs[k+1] - xs[k])
+	}
+
+	tangents := make([]float64, n)
+	tangents[0] = deltas[0]
+	tangents[n-1] = deltas[n-2]
+	for k := 1; k < n-1; k++ {
+		if deltas[k-1]*deltas[k] > 0 {
+			tangents[k] = (deltas[k-1] + deltas[k]) / 2
+		}
+	}
+
+	for k := 0; k < n-1; k++ {
+		if deltas[k] == 0 {
+			tangents[k] = 0
+			tangents[k+1] = 0
+			continue
+		}
+
+		a := tangents[k] / deltas[k]
+		b := tangents[k+1] / deltas[k]
+		if h := a*a + b*b; h > 9 {
+			tau := 3 / math.Sqrt(h)
+			tangents[k] = tau * a * deltas[k]
+			tangents[k+1] = tau * b * deltas[k]
+		}
+	}
+
+	return &Spline{
+		Xs:       xs,
+		Ys:       ys,
+		Tangents: tangents,
+		Ends: curveEnds{
+			X0: xs[0], Y0: ys[0], M0: tangents[0],
+			Xn: xs[n-1], Yn: ys[n-1], Mn: tangents[n-1],
+			Span: span,
+		},
+		Log: log,
+	}, nil
+}
+
+func (s *Spline) At(t time.Time) (float64, error) {
+	x := timeToFloat64(t)
+
+	if y, ok, err := s.Ends.outside(x); ok {
+		if err != nil {
+			return 0, err
+		}
+		return curveValue(y, s.Log), nil
+	}
+
+	k := 0
+	for k < len(s.Xs)-2 && x > s.Xs[k+1] {
+		k++
+	}
+
+	// cubic hermite on the [k, k+1] segment
+	h := s.Xs[k+1] - s.Xs[k]
+	u := (x - s.Xs[k]) / h
+	u2, u3 := u*u, u*u*u
+
+	y := (2*u3-3*u2+1)*s.Ys[k] +
+		(u3-2*u2+u)*h*s.Tangents[k] +
+		(-2*u3+3*u2)*s.Ys[k+1] +
+		(u3-u2)*h*s.Tangents[k+1]
+
+	return curveValue(y, s.Log), nil
+}
+
+// Bezier is a bezier curve defined by its control points, it goes through the first and the last point
+// and is pulled towards the ones in between.
+type Bezier struct {
+	Xs, Ys []float64
+	Ends   curveEnds
+	Log    bool
+}
+
+// NewBezier is a constructor for Bezier, requires at least 2 control points with distinct dates
+func NewBezier(points []Point, span SpanMode) (*Bezier, error) {
+	return newBezier(points, span, false)
+}
+
+// NewLogBezier is a Bezier drawn on a logarithmic price scale
+func NewLogBezier(points []Point, span SpanMode) (*Bezier, error) {
+	return newBezier(points, span, true)
+}
+
+func newBezier(points []Point, span SpanMode, log bool) (*Bezier, error) {
+	span, err := parseSpanMode(span)
+	if err != nil {
+		return nil, fmt.Errorf("error creating bezier: %w", err)
+	}
+
+	xs, ys, err := curveCoordinates(points, 2, log)
+	if err != nil {
+		return nil, fmt.Errorf("error creating bezier: %w", err)
+	}
+
+	// the curve is tangent to the first and the last segment of the control polygon
+	n := len(xs)
+	return &Bezier{
+		Xs: xs,
+		Ys: ys,
+		Ends: curveEnds{
+			X0: xs[0], Y0: ys[0], M0: (ys[1] - ys[0]) / (xs[1] - xs[0]),
+			Xn: xs[n-1], Yn: ys[n-1], Mn: (ys[n-1] - ys[n-2]) / (xs[n-1] - xs[n-2]),
+			Span: span,
+		},
+		Log: log,
+	}, nil
+}
+
+func (b *Bezier) At(t time.Time) (float64, error) {
+	x := timeToFloat64(t)
+
+	if y, ok, err := b.Ends.outside(x); ok {
+		if err != nil {
+			return 0, err
+		}
+		return curveValue(y, b.Log), nil
+	}
+
+	// control points are sorted by date so x(u) is monotone, find u for which x(u) = x
+	lo, hi := 0.0, 1.0
+	for i := 0; i < 100 && hi-lo > 1e-12; i++ {
+		mid := (lo + hi) / 2
+		if deCasteljau(b.Xs, mid) < x {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	return curveValue(deCasteljau(b.Ys, (lo+hi)/2), b.Log), nil
+}
+
+func deCasteljau(coords []float64, u float64) float64 {
+	tmp := append([]float64{}, coords...)
+	for n := len(tmp) - 1; n > 0; n-- {
+		for i := 0; i < n; i++ {
+			tmp[i] = (1-u)*tmp[i] + u*tmp[i+1]
+		}
+	}
+	return tmp[0]
+}
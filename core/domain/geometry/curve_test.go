@@ -0,0 +1,168 @@
+package geometry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/domain/geometry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSpline(t *testing.T) {
+	tests := []struct {
+		name      string
+		points    []geometry.Point
+		span      geometry.SpanMode
+		expectErr bool
+	}{
+		{"one point", []geometry.Point{{time.Unix(0, 0), 1}}, "", true},
+		{"same dates", []geometry.Point{{time.Unix(0, 0), 1}, {time.Unix(0, 0), 2}}, "", true},
+		{"unknown span", []geometry.Point{{time.Unix(0, 0), 1}, {time.Unix(1, 0), 2}}, "wrap", true},
+		{"ok", []geometry.Point{{time.Unix(0, 0), 1}, {time.Unix(1, 0), 2}}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := geometry.NewSpline(tt.points, tt.span)
+			assert.Equal(t, tt.expectErr, err != nil)
+
+			_, err = geometry.NewBezier(tt.points, tt.span)
+			assert.Equal(t, tt.expectErr, err != nil)
+		})
+	}
+
+	_, err := geometry.NewLogSpline([]geometry.Point{{time.Unix(0, 0), 0}, {time.Unix(1, 0), 2}}, "")
+	assert.Error(t, err)
+}
+
+func TestSpline_At(t *testing.T) {
+	points := []geometry.Point{
+		{time.Unix(0, 0), 0},
+		{time.Unix(10, 0), 10},
+		{time.Unix(20, 0), 10},
+		{time.Unix(30, 0), 0},
+	}
+
+	tests := []struct {
+		name        string
+		span        geometry.SpanMode
+		x           time.Time
+		want        float64
+		expectedErr error
+	}{
+		{name: "at point", x: time.Unix(10, 0), want: 10},
+		{name: "flat segment doesn't overshoot", x: time.Unix(15, 0), want: 10},
+		{name: "last point", x: time.Unix(30, 0), want: 0},
+		{name: "before - error", x: time.Unix(-1, 0), want: 0, expectedErr: geometry.ErrPlotOutOfRange},
+		{name: "after - error", span: geometry.SpanModeError, x: time.Unix(31, 0), want: 0, expectedErr: geometry.ErrPlotOutOfRange},
+		{name: "before - clamp", span: geometry.SpanModeClamp, x: time.Unix(-10, 0), want: 0},
+		{name: "after - clamp", span: geometry.SpanModeClamp, x: time.Unix(40, 0), want: 0},
+		{name: "before - extrapolate", span: geometry.SpanModeExtrapolate, x: time.Unix(-10, 0), want: -10},
+		{name: "after - extrapolate", span: geometry.SpanModeExtrapolate, x: time.Unix(40, 0), want: -10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := geometry.NewSpline(points, tt.span)
+			assert.NoError(t, err)
+
+			got, err := s.At(tt.x)
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.InDelta(t, tt.want, got, 1e-9)
+		})
+	}
+}
+
+func TestSpline_At_Monotone(t *testing.T) {
+	s, err := geometry.NewSpline([]geometry.Point{
+		{time.Unix(0, 0), 1},
+		{time.Unix(10, 0), 2},
+		{time.Unix(11, 0), 20},
+		{time.Unix(30, 0), 21},
+	}, geometry.SpanModeError)
+	assert.NoError(t, err)
+
+	prev := 0.0
+	for sec := int64(0); sec <= 30; sec++ {
+		got, err := s.At(time.Unix(sec, 0))
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, got, prev)
+		prev = got
+	}
+}
+
+func TestLogSpline_At(t *testing.T) {
+	s, err := geometry.NewLogSpline([]geometry.Point{
+		{time.Unix(0, 0), 1},
+		{time.Unix(10, 0), 10},
+		{time.Unix(20, 0), 100},
+	}, geometry.SpanModeExtrapolate)
+	assert.NoError(t, err)
+
+	got, err := s.At(time.Unix(5, 0))
+	assert.NoError(t, err)
+	assert.InDelta(t, 3.16227766, got, 1e-6)
+
+	got, err = s.At(time.Unix(30, 0))
+	assert.NoError(t, err)
+	assert.InDelta(t, 1000, got, 1e-6)
+}
+
+func TestBezier_At(t *testing.T) {
+	points := []geometry.Point{
+		{time.Unix(0, 0), 0},
+		{time.Unix(10, 0), 20},
+		{time.Unix(20, 0), 0},
+	}
+
+	tests := []struct {
+		name        string
+		span        geometry.SpanMode
+		x           time.Time
+		want        float64
+		expectedErr error
+	}{
+		{name: "first point", x: time.Unix(0, 0), want: 0},
+		{name: "middle", x: time.Unix(10, 0), want: 10},
+		{name: "last point", x: time.Unix(20, 0), want: 0},
+		{name: "after - error", x: time.Unix(21, 0), want: 0, expectedErr: geometry.ErrPlotOutOfRange},
+		{name: "before - clamp", span: geometry.SpanModeClamp, x: time.Unix(-10, 0), want: 0},
+		{name: "before - extrapolate", span: geometry.SpanModeExtrapolate, x: time.Unix(-10, 0), want: -20},
+		{name: "after - extrapolate", span: geometry.SpanModeExtrapolate, x: time.Unix(30, 0), want: -20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := geometry.NewBezier(points, tt.span)
+			assert.NoError(t, err)
+
+			got, err := b.At(tt.x)
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.InDelta(t, tt.want, got, 1e-6)
+		})
+	}
+}
+
+func TestPlotSpec_Parse_Curves(t *testing.T) {
+	for _, plotType := range []string{"spline", "spline_log", "bezier", "bezier_log"} {
+		t.Run(plotType, func(t *testing.T) {
+			spec := geometry.PlotSpec{
+				"type": plotType,
+				"args": map[string]any{
+					"points": []map[string]any{
+						{"date": "2023-01-01", "price": 10},
+						{"date": "2023-01-03", "price": 10},
+					},
+					"span": "clamp",
+				},
+			}
+
+			plot, err := spec.Parse()
+			assert.NoError(t, err)
+
+			got, err := plot.At(time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC))
+			assert.NoError(t, err)
+			assert.InDelta(t, 10, got, 1e-9)
+		})
+	}
+}
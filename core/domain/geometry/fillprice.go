@@ -0,0 +1,35 @@
+package geometry
+
+import (
+	"errors"
+	"time"
+)
+
+// FillPrice is a plot returning the fill price of another order of the same follow,
+// it's out of range until that order is filled. Wrap it with an offset to place orders relative to the fill.
+type FillPrice struct {
+	OrderName string
+	Orders    FillPricer
+}
+
+// NewFillPrice is a constructor for FillPrice, returns error if the order name is empty
+func NewFillPrice(orderName string, orders FillPricer) (*FillPrice, error) {
+	if orderName == "" {
+		return nil, errors.New("error creating fill price: empty order name")
+	}
+
+	return &FillPrice{OrderName: orderName, Orders: orders}, nil
+}
+
+func (f *FillPrice) At(time.Time) (float64, error) {
+	if f.Orders == nil {
+		return 0, ErrPlotOutOfRange
+	}
+
+	price, ok := f.Orders.FillPrice(f.OrderName)
+	if !ok {
+		return 0, ErrPlotOutOfRange
+	}
+
+	return price, nil
+}
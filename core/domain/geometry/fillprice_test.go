@@ -0,0 +1,83 @@
+package geometry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/domain/geometry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFillPrice_At(t *testing.T) {
+	tests := []struct {
+		name        string
+		orderName   string
+		orders      geometry.FillPricer
+		want        float64
+		expectedErr error
+	}{
+		{
+			name:        "no orders",
+			orderName:   "entry",
+			orders:      nil,
+			want:        0,
+			expectedErr: geometry.ErrPlotOutOfRange,
+		},
+		{
+			name:        "not filled",
+			orderName:   "entry",
+			orders:      fillPrices{"tp": 10},
+			want:        0,
+			expectedErr: geometry.ErrPlotOutOfRange,
+		},
+		{
+			name:      "filled",
+			orderName: "entry",
+			orders:    fillPrices{"entry": 10},
+			want:      10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fp, err := geometry.NewFillPrice(tt.orderName, tt.orders)
+			assert.NoError(t, err)
+
+			got, err := fp.At(time.Time{})
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPlotSpec_ParseEnv_FillPrice(t *testing.T) {
+	spec := geometry.PlotSpec{
+		"type": "offset_percentage",
+		"args": map[string]any{
+			"value": 0.5,
+			"plot": map[string]any{
+				"type": "fill_price",
+				"args": map[string]any{"order": "entry"},
+			},
+		},
+	}
+
+	orders := fillPrices{}
+	plot, err := spec.ParseEnv(geometry.Env{State: geometry.PlotState{}, Orders: orders})
+	assert.NoError(t, err)
+
+	_, err = plot.At(time.Time{})
+	assert.ErrorIs(t, err, geometry.ErrPlotOutOfRange)
+
+	orders["entry"] = 100
+	got, err := plot.At(time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, 150.0, got)
+}
+
+type fillPrices map[string]float64
+
+func (f fillPrices) FillPrice(orderName string) (float64, bool) {
+	p, ok := f[orderName]
+	return p, ok
+}
@@ -16,6 +16,22 @@ const (
 	KEY_MIN               = "min"
 	KEY_MAX               = "max"
 	KEY_LIMIT             = "limit"
+	KEY_TRAIL             = "trail"
+	KEY_FILL_PRICE        = "fill_price"
+
+	KEY_REGRESSION            = "regression"
+	KEY_REGRESSION_POLYNOMIAL = "regression_polynomial"
+	KEY_REGRESSION_LOG        = "regression_log"
+
+	KEY_SPLINE     = "spline"
+	KEY_SPLINE_LOG = "spline_log"
+	KEY_BEZIER     = "bezier"
+	KEY_BEZIER_LOG = "bezier_log"
+
+	KEY_SESSION = "session"
+	KEY_REPEAT  = "repeat"
+
+	KEY_PROTECTOR = "protector"
 )
 
 var formats = []string{
@@ -44,6 +60,11 @@ var formats = []string{
 	"15:04:05",
 }
 
+// ParseTime parses the time in any of the formats accepted in PlotSpecs
+func ParseTime(s string) (time.Time, error) {
+	return parseTime(s)
+}
+
 func parseTime(s string) (time.Time, error) {
 	for _, format := range formats {
 		t, err := time.Parse(format, s)
@@ -130,8 +151,69 @@ type minMaxPlotJSON struct {
 	Plots []plotJSON
 }
 
-func parsePlotMap(plot map[string]any) (Plot, error) {
-	bytes, err := json.Marshal(plot)
+// trailPlotJSON is a structure holding arguments for Trail
+type trailPlotJSON struct {
+	Direction TrailDirection
+	Plot      plotJSON
+}
+
+// fillPricePlotJSON is a structure holding arguments for FillPrice
+type fillPricePlotJSON struct {
+	Order string
+}
+
+type pointsJSON []pointJSON
+
+func (pj pointsJSON) points() []Point {
+	points := make([]Point, len(pj))
+	for i, p := range pj {
+		points[i] = Point(p)
+	}
+	return points
+}
+
+// regressionPlotJSON is a structure holding arguments for Regression
+type regressionPlotJSON struct {
+	Points     pointsJSON
+	Degree     int
+	Deviations float64
+}
+
+// curvePlotJSON is a structure holding arguments for Spline and Bezier
+type curvePlotJSON struct {
+	Points pointsJSON
+	Span   SpanMode
+}
+
+// sessionPlotJSON is a structure holding arguments for Session
+type sessionPlotJSON struct {
+	Days     []string
+	From, To string
+	Timezone string
+	Plot     plotJSON
+}
+
+// repeatPlotJSON is a structure holding arguments for Repeat
+type repeatPlotJSON struct {
+	Start  string
+	Period string
+	Plot   plotJSON
+}
+
+// Parser parses plots from a single PlotSpec, it's passed to PlotConstructors
+// so they can parse nested plots and access the environment.
+type Parser struct {
+	env  Env
+	keys map[string]int
+}
+
+func newParser(env Env) *Parser {
+	return &Parser{env: env, keys: map[string]int{}}
+}
+
+// Parse parses a nested plot spec
+func (p *Parser) Parse(spec PlotSpec) (Plot, error) {
+	bytes, err := json.Marshal(spec)
 	if err != nil {
 		return nil, errors.Wrap(err, "error marshalling plot")
 	}
@@ -141,107 +223,290 @@ func parsePlotMap(plot map[string]any) (Plot, error) {
 		return nil, errors.Wrap(err, "error unmarshalling plot")
 	}
 
-	parsedPlot, err := parsePlot(pj)
+	return p.parsePlot(pj)
+}
+
+// Env returns the environment parsed plots are evaluated in
+func (p *Parser) Env() Env {
+	return p.env
+}
+
+// StateKey returns a key under which a stateful plot can keep its values in the PlotState,
+// keys are assigned in parsing order so parsing the same spec again yields the same keys.
+func (p *Parser) StateKey(prefix string) string {
+	key := fmt.Sprintf("%s_%d", prefix, p.keys[prefix])
+	p.keys[prefix]++
+	return key
+}
+
+func parsePlotMap(plot map[string]any, env Env) (Plot, error) {
+	parser := newParser(env)
+	parsedPlot, err := parser.Parse(plot)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing plot: %w", err)
 	}
 
-	// if protect {
-	// 	return NewProtector(parsedPlot), nil
-	// }
+	if env.Protection != nil {
+		return NewProtectorWithConfig(parsedPlot, *env.Protection, env, parser.StateKey(KEY_PROTECTOR)), nil
+	}
 
 	return parsedPlot, nil
 }
 
-func parsePlot(pj plotJSON) (Plot, error) {
-	args := pj.Args
+func (p *Parser) parsePlot(pj plotJSON) (Plot, error) {
+	pt, ok := lookupPlotType(pj.Type)
+	if !ok {
+		return nil, fmt.Errorf("unknown plot name %s", pj.Type)
+	}
 
-	switch pj.Type {
-	case KEY_LINE:
-		lineJSON := linePlotJSON{}
-		if err := json.Unmarshal(args, &lineJSON); err != nil {
-			return nil, err
-		}
+	return pt.New(pj.Args, p)
+}
 
-		return NewLine(Point(lineJSON.P0), Point(lineJSON.P1))
-	case KEY_LINE_LOG:
-		lineJSON := linePlotJSON{}
-		if err := json.Unmarshal(args, &lineJSON); err != nil {
+func (p *Parser) parsePlots(pjs []plotJSON) ([]Plot, error) {
+	plots := []Plot{}
+	for _, pj := range pjs {
+		plot, err := p.parsePlot(pj)
+		if err != nil {
 			return nil, err
 		}
 
-		return NewLogLine(Point(lineJSON.P0), Point(lineJSON.P1))
-	case KEY_OFFSET_ABSOLUTE:
-		offsetJSON := offsetPlotJSON{}
-		if err := json.Unmarshal(args, &offsetJSON); err != nil {
-			return nil, err
-		}
+		plots = append(plots, plot)
+	}
 
-		plotToOffset, err := parsePlot(offsetJSON.Plot)
-		if err != nil {
-			return nil, err
-		}
+	return plots, nil
+}
 
-		return NewOffsetPlot(plotToOffset, NewAbsoluteOffset(offsetJSON.Value)), nil
-	case KEY_OFFSET_PERCENTAGE:
-		offsetJSON := offsetPlotJSON{}
-		if err := json.Unmarshal(args, &offsetJSON); err != nil {
-			return nil, err
-		}
+func newLinePlot(args json.RawMessage, p *Parser) (Plot, error) {
+	lineJSON := linePlotJSON{}
+	if err := json.Unmarshal(args, &lineJSON); err != nil {
+		return nil, err
+	}
 
-		plotToOffset, err := parsePlot(offsetJSON.Plot)
-		if err != nil {
-			return nil, err
-		}
+	return NewLine(Point(lineJSON.P0), Point(lineJSON.P1))
+}
 
-		return NewOffsetPlot(plotToOffset, NewPercentageOffset(offsetJSON.Value)), nil
-	case KEY_LIMIT:
-		limitJSON := limitPlotJSON{}
-		if err := json.Unmarshal(args, &limitJSON); err != nil {
-			return nil, err
-		}
+func newLogLinePlot(args json.RawMessage, p *Parser) (Plot, error) {
+	lineJSON := linePlotJSON{}
+	if err := json.Unmarshal(args, &lineJSON); err != nil {
+		return nil, err
+	}
 
-		plotToLimit, err := parsePlot(limitJSON.Plot)
-		if err != nil {
-			return nil, err
-		}
+	return NewLogLine(Point(lineJSON.P0), Point(lineJSON.P1))
+}
 
-		return NewLimit(plotToLimit, limitJSON.Since, limitJSON.Until), nil
-	case KEY_MIN:
-		minmaxJSON := minMaxPlotJSON{}
-		if err := json.Unmarshal(args, &minmaxJSON); err != nil {
-			return nil, err
-		}
+func newAbsoluteOffsetPlot(args json.RawMessage, p *Parser) (Plot, error) {
+	offsetJSON := offsetPlotJSON{}
+	if err := json.Unmarshal(args, &offsetJSON); err != nil {
+		return nil, err
+	}
 
-		plotsToMin := []Plot{}
-		for _, pjMin := range minmaxJSON.Plots {
-			plotToMin, err := parsePlot(pjMin)
-			if err != nil {
-				return nil, err
-			}
+	plotToOffset, err := p.parsePlot(offsetJSON.Plot)
+	if err != nil {
+		return nil, err
+	}
 
-			plotsToMin = append(plotsToMin, plotToMin)
-		}
+	return NewOffsetPlot(plotToOffset, NewAbsoluteOffset(offsetJSON.Value)), nil
+}
+
+func newPercentageOffsetPlot(args json.RawMessage, p *Parser) (Plot, error) {
+	offsetJSON := offsetPlotJSON{}
+	if err := json.Unmarshal(args, &offsetJSON); err != nil {
+		return nil, err
+	}
+
+	plotToOffset, err := p.parsePlot(offsetJSON.Plot)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewOffsetPlot(plotToOffset, NewPercentageOffset(offsetJSON.Value)), nil
+}
+
+func newLimitPlot(args json.RawMessage, p *Parser) (Plot, error) {
+	limitJSON := limitPlotJSON{}
+	if err := json.Unmarshal(args, &limitJSON); err != nil {
+		return nil, err
+	}
+
+	plotToLimit, err := p.parsePlot(limitJSON.Plot)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewLimit(plotToLimit, limitJSON.Since, limitJSON.Until), nil
+}
 
-		return NewMin(plotsToMin)
-	case KEY_MAX:
-		minmaxJSON := minMaxPlotJSON{}
-		if err := json.Unmarshal(args, &minmaxJSON); err != nil {
+func newMinPlot(args json.RawMessage, p *Parser) (Plot, error) {
+	minmaxJSON := minMaxPlotJSON{}
+	if err := json.Unmarshal(args, &minmaxJSON); err != nil {
+		return nil, err
+	}
+
+	plotsToMin, err := p.parsePlots(minmaxJSON.Plots)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMin(plotsToMin)
+}
+
+func newMaxPlot(args json.RawMessage, p *Parser) (Plot, error) {
+	minmaxJSON := minMaxPlotJSON{}
+	if err := json.Unmarshal(args, &minmaxJSON); err != nil {
+		return nil, err
+	}
+
+	plotsToMax, err := p.parsePlots(minmaxJSON.Plots)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMax(plotsToMax)
+}
+
+func newTrailPlot(args json.RawMessage, p *Parser) (Plot, error) {
+	trailJSON := trailPlotJSON{}
+	if err := json.Unmarshal(args, &trailJSON); err != nil {
+		return nil, err
+	}
+
+	plotToTrail, err := p.parsePlot(trailJSON.Plot)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTrail(plotToTrail, trailJSON.Direction, p.StateKey(KEY_TRAIL), p.env.State)
+}
+
+func newFillPricePlot(args json.RawMessage, p *Parser) (Plot, error) {
+	fillPriceJSON := fillPricePlotJSON{}
+	if err := json.Unmarshal(args, &fillPriceJSON); err != nil {
+		return nil, err
+	}
+
+	return NewFillPrice(fillPriceJSON.Order, p.env.Orders)
+}
+
+func newRegressionPlot(args json.RawMessage, p *Parser) (Plot, error) {
+	regressionJSON := regressionPlotJSON{}
+	if err := json.Unmarshal(args, &regressionJSON); err != nil {
+		return nil, err
+	}
+
+	return NewRegression(regressionJSON.Points.points(), 1, regressionJSON.Deviations)
+}
+
+func newPolynomialRegressionPlot(args json.RawMessage, p *Parser) (Plot, error) {
+	regressionJSON := regressionPlotJSON{}
+	if err := json.Unmarshal(args, &regressionJSON); err != nil {
+		return nil, err
+	}
+
+	return NewRegression(regressionJSON.Points.points(), regressionJSON.Degree, regressionJSON.Deviations)
+}
+
+func newLogRegressionPlot(args json.RawMessage, p *Parser) (Plot, error) {
+	regressionJSON := regressionPlotJSON{Degree: 1}
+	if err := json.Unmarshal(args, &regressionJSON); err != nil {
+		return nil, err
+	}
+
+	return NewLogRegression(regressionJSON.Points.points(), regressionJSON.Degree, regressionJSON.Deviations)
+}
+
+func newSplinePlot(args json.RawMessage, p *Parser) (Plot, error) {
+	curveJSON := curvePlotJSON{}
+	if err := json.Unmarshal(args, &curveJSON); err != nil {
+		return nil, err
+	}
+
+	return NewSpline(curveJSON.Points.points(), curveJSON.Span)
+}
+
+func newLogSplinePlot(args json.RawMessage, p *Parser) (Plot, error) {
+	curveJSON := curvePlotJSON{}
+	if err := json.Unmarshal(args, &curveJSON); err != nil {
+		return nil, err
+	}
+
+	return NewLogSpline(curveJSON.Points.points(), curveJSON.Span)
+}
+
+func newBezierPlot(args json.RawMessage, p *Parser) (Plot, error) {
+	curveJSON := curvePlotJSON{}
+	if err := json.Unmarshal(args, &curveJSON); err != nil {
+		return nil, err
+	}
+
+	return NewBezier(curveJSON.Points.points(), curveJSON.Span)
+}
+
+func newLogBezierPlot(args json.RawMessage, p *Parser) (Plot, error) {
+	curveJSON := curvePlotJSON{}
+	if err := json.Unmarshal(args, &curveJSON); err != nil {
+		return nil, err
+	}
+
+	return NewLogBezier(curveJSON.Points.points(), curveJSON.Span)
+}
+
+func newSessionPlot(args json.RawMessage, p *Parser) (Plot, error) {
+	sessionJSON := sessionPlotJSON{}
+	if err := json.Unmarshal(args, &sessionJSON); err != nil {
+		return nil, err
+	}
+
+	days, err := parseWeekdays(sessionJSON.Days)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := parseTimeOfDay(sessionJSON.From)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := parseTimeOfDay(sessionJSON.To)
+	if err != nil {
+		return nil, err
+	}
+
+	location := time.UTC
+	if sessionJSON.Timezone != "" {
+		location, err = time.LoadLocation(sessionJSON.Timezone)
+		if err != nil {
 			return nil, err
 		}
+	}
 
-		plotsToMin := []Plot{}
-		for _, pjMin := range minmaxJSON.Plots {
-			plotToMin, err := parsePlot(pjMin)
-			if err != nil {
-				return nil, err
-			}
+	plotToSession, err := p.parsePlot(sessionJSON.Plot)
+	if err != nil {
+		return nil, err
+	}
 
-			plotsToMin = append(plotsToMin, plotToMin)
-		}
+	return NewSession(plotToSession, days, from, to, location)
+}
 
-		return NewMax(plotsToMin)
+func newRepeatPlot(args json.RawMessage, p *Parser) (Plot, error) {
+	repeatJSON := repeatPlotJSON{}
+	if err := json.Unmarshal(args, &repeatJSON); err != nil {
+		return nil, err
+	}
+
+	start, err := parseTime(repeatJSON.Start)
+	if err != nil {
+		return nil, err
+	}
+
+	period, err := time.ParseDuration(repeatJSON.Period)
+	if err != nil {
+		return nil, err
+	}
+
+	plotToRepeat, err := p.parsePlot(repeatJSON.Plot)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, fmt.Errorf("unknown plot name %s", pj.Type)
+	return NewRepeat(plotToRepeat, start, period)
 }
@@ -0,0 +1,139 @@
+package geometry
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Session is a Plot wrapper that allows it to be valid only during recurring daily windows, like trading sessions.
+// The window is [From, To) measured from the midnight in Location, if From is after To the window spans midnight
+// and belongs to the day it starts on. If From equals To the whole day is valid.
+// Days restricts the windows to certain weekdays, all days are allowed when it's empty.
+type Session struct {
+	Plot     Plot
+	Days     []time.Weekday
+	From, To time.Duration
+	Location *time.Location
+}
+
+// NewSession is a constructor for Session, location defaults to UTC
+func NewSession(plot Plot, days []time.Weekday, from, to time.Duration, location *time.Location) (*Session, error) {
+	day := 24 * time.Hour
+	if from < 0 || from >= day || to < 0 || to >= day {
+		return nil, fmt.Errorf("error creating session: window [%s, %s) exceeds a day", from, to)
+	}
+
+	if location == nil {
+		location = time.UTC
+	}
+
+	return &Session{Plot: plot, Days: days, From: from, To: to, Location: location}, nil
+}
+
+func (s *Session) At(t time.Time) (float64, error) {
+	if !s.inSession(t) {
+		return 0, ErrPlotOutOfRange
+	}
+	return s.Plot.At(t)
+}
+
+func (s *Session) inSession(t time.Time) bool {
+	t = t.In(s.Location)
+	// wall clock time, elapsed time since midnight is off by the shift on DST change days
+	h, m, sec := t.Clock()
+	sinceMidnight := time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(sec)*time.Second + time.Duration(t.Nanosecond())
+
+	switch {
+	case s.From == s.To:
+		return s.onDay(t.Weekday())
+	case s.From < s.To:
+		return s.onDay(t.Weekday()) && sinceMidnight >= s.From && sinceMidnight < s.To
+	}
+
+	// window spans midnight
+	if sinceMidnight >= s.From {
+		return s.onDay(t.Weekday())
+	}
+	yesterday := (t.Weekday() + 6) % 7
+	return sinceMidnight < s.To && s.onDay(yesterday)
+}
+
+func (s *Session) onDay(d time.Weekday) bool {
+	return len(s.Days) == 0 || slices.Contains(s.Days, d)
+}
+
+// Repeat is a Plot wrapper that repeats the wrapped plot every Period,
+// the value at t is the value of the wrapped plot in the [Start, Start+Period) range shifted by a multiple of Period.
+type Repeat struct {
+	Plot   Plot
+	Start  time.Time
+	Period time.Duration
+}
+
+// NewRepeat is a constructor for Repeat, returns error if the period is not positive
+func NewRepeat(plot Plot, start time.Time, period time.Duration) (*Repeat, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("error creating repeat: period must be positive, got: %s", period)
+	}
+
+	return &Repeat{Plot: plot, Start: start, Period: period}, nil
+}
+
+func (r *Repeat) At(t time.Time) (float64, error) {
+	d := t.Sub(r.Start) % r.Period
+	if d < 0 {
+		d += r.Period
+	}
+	return r.Plot.At(r.Start.Add(d))
+}
+
+var weekdayNames = map[string][]time.Weekday{
+	"mon":       {time.Monday},
+	"tue":       {time.Tuesday},
+	"wed":       {time.Wednesday},
+	"thu":       {time.Thursday},
+	"fri":       {time.Friday},
+	"sat":       {time.Saturday},
+	"sun":       {time.Sunday},
+	"monday":    {time.Monday},
+	"tuesday":   {time.Tuesday},
+	"wednesday": {time.Wednesday},
+	"thursday":  {time.Thursday},
+	"friday":    {time.Friday},
+	"saturday":  {time.Saturday},
+	"sunday":    {time.Sunday},
+	"weekdays":  {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	"weekends":  {time.Saturday, time.Sunday},
+}
+
+// parseWeekdays parses day names like "mon", "Monday", "weekdays" or "weekends"
+func parseWeekdays(names []string) ([]time.Weekday, error) {
+	days := []time.Weekday{}
+	for _, name := range names {
+		d, ok := weekdayNames[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown day: %s", name)
+		}
+		days = append(days, d...)
+	}
+	return days, nil
+}
+
+// parseTimeOfDay parses "15:04" or "15:04:05" into a duration since midnight
+func parseTimeOfDay(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, errors.New("empty time of day")
+	}
+
+	for _, format := range []string{"15:04", "15:04:05"} {
+		t, err := time.Parse(format, s)
+		if err == nil {
+			return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unable to parse time of day: %s", s)
+}
@@ -0,0 +1,148 @@
+package geometry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/domain/geometry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSession_At(t *testing.T) {
+	weekdays := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	// 2023-01-02 is a Monday
+	at := func(day, hour, min int) time.Time { return time.Date(2023, 1, day, hour, min, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name        string
+		days        []time.Weekday
+		from, to    time.Duration
+		t           time.Time
+		want        float64
+		expectedErr error
+	}{
+		{name: "weekday - start", days: weekdays, from: 8 * time.Hour, to: 16 * time.Hour, t: at(2, 8, 0), want: 5},
+		{name: "weekday - inside", days: weekdays, from: 8 * time.Hour, to: 16 * time.Hour, t: at(6, 12, 0), want: 5},
+		{name: "weekday - end", days: weekdays, from: 8 * time.Hour, to: 16 * time.Hour, t: at(2, 16, 0), expectedErr: geometry.ErrPlotOutOfRange},
+		{name: "weekday - before", days: weekdays, from: 8 * time.Hour, to: 16 * time.Hour, t: at(2, 7, 59), expectedErr: geometry.ErrPlotOutOfRange},
+		{name: "weekend", days: weekdays, from: 8 * time.Hour, to: 16 * time.Hour, t: at(7, 12, 0), expectedErr: geometry.ErrPlotOutOfRange},
+		{name: "every day", from: 0, to: time.Hour, t: at(7, 0, 30), want: 5},
+		{name: "every day - after", from: 0, to: time.Hour, t: at(7, 1, 0), expectedErr: geometry.ErrPlotOutOfRange},
+		{name: "whole day", days: []time.Weekday{time.Sunday}, t: at(8, 23, 59), want: 5},
+		{name: "over midnight - evening", days: []time.Weekday{time.Friday}, from: 22 * time.Hour, to: 2 * time.Hour, t: at(6, 23, 0), want: 5},
+		{name: "over midnight - morning", days: []time.Weekday{time.Friday}, from: 22 * time.Hour, to: 2 * time.Hour, t: at(7, 1, 0), want: 5},
+		{name: "over midnight - other day", days: []time.Weekday{time.Friday}, from: 22 * time.Hour, to: 2 * time.Hour, t: at(6, 1, 0), expectedErr: geometry.ErrPlotOutOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := geometry.NewSession(&alwaysValid{5}, tt.days, tt.from, tt.to, nil)
+			assert.NoError(t, err)
+
+			got, err := s.At(tt.t)
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSession_At_DST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no tz database:", err)
+	}
+
+	// clocks moved forward at 2am on 2023-03-12, the window follows the wall clock
+	s, err := geometry.NewSession(&alwaysValid{5}, nil, 9*time.Hour, 10*time.Hour, loc)
+	assert.NoError(t, err)
+
+	_, err = s.At(time.Date(2023, 3, 12, 9, 30, 0, 0, loc))
+	assert.NoError(t, err)
+	_, err = s.At(time.Date(2023, 3, 12, 10, 30, 0, 0, loc))
+	assert.ErrorIs(t, err, geometry.ErrPlotOutOfRange)
+}
+
+func TestNewSession(t *testing.T) {
+	_, err := geometry.NewSession(&alwaysValid{}, nil, 0, 24*time.Hour, nil)
+	assert.Error(t, err)
+
+	_, err = geometry.NewSession(&alwaysValid{}, nil, -time.Hour, 0, nil)
+	assert.Error(t, err)
+}
+
+func TestRepeat_At(t *testing.T) {
+	// saw tooth going from 0 to 4 every 4 seconds
+	r, err := geometry.NewRepeat(&geometry.Line{A: 1, B: -100}, time.Unix(100, 0), 4*time.Second)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		sec  int64
+		want float64
+	}{
+		{100, 0},
+		{103, 3},
+		{104, 0},
+		{109, 1},
+		{99, 3},
+		{92, 0},
+	}
+
+	for _, tt := range tests {
+		got, err := r.At(time.Unix(tt.sec, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, got, tt.sec)
+	}
+
+	_, err = geometry.NewRepeat(&alwaysValid{}, time.Time{}, 0)
+	assert.Error(t, err)
+}
+
+func TestPlotSpec_Parse_Session(t *testing.T) {
+	spec := geometry.PlotSpec{
+		"type": "session",
+		"args": map[string]any{
+			"days":     []string{"weekdays"},
+			"from":     "08:00",
+			"to":       "16:00",
+			"timezone": "UTC",
+			"plot": map[string]any{
+				"type": "repeat",
+				"args": map[string]any{
+					"start":  "2023-01-02",
+					"period": "24h",
+					"plot": map[string]any{
+						"type": "line",
+						"args": map[string]any{
+							"p0": map[string]any{"date": "2023-01-02 00:00:00", "price": 0},
+							"p1": map[string]any{"date": "2023-01-02 01:00:00", "price": 1},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	plot, err := spec.Parse()
+	assert.NoError(t, err)
+
+	got, err := plot.At(time.Date(2023, 1, 4, 10, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, got)
+
+	_, err = plot.At(time.Date(2023, 1, 7, 10, 0, 0, 0, time.UTC))
+	assert.ErrorIs(t, err, geometry.ErrPlotOutOfRange)
+
+	for _, days := range [][]string{{"someday"}, {"monkey"}, {"sunflower"}} {
+		_, err = geometry.PlotSpec{
+			"type": "session",
+			"args": map[string]any{"days": days, "from": "08:00", "to": "16:00", "plot": map[string]any{"type": "line"}},
+		}.Parse()
+		assert.Error(t, err, days)
+	}
+
+	_, err = geometry.PlotSpec{
+		"type": "session",
+		"args": map[string]any{"days": []string{"Monday", "fri"}, "from": "08:00", "to": "16:00", "plot": spec["args"].(map[string]any)["plot"]},
+	}.Parse()
+	assert.NoError(t, err)
+}
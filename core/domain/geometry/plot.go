@@ -13,10 +13,46 @@ var (
 
 type PlotSpec map[string]any
 
+// Parse parses the spec with an empty state
 func (p PlotSpec) Parse() (Plot, error) {
-	return parsePlotMap(p)
+	return parsePlotMap(p, Env{State: PlotState{}})
 }
 
+// ParseWithState parses the spec, stateful plots will read and write their state to the provided one
+func (p PlotSpec) ParseWithState(state PlotState) (Plot, error) {
+	return p.ParseEnv(Env{State: state})
+}
+
+// ParseEnv parses the spec, parsed plots will be evaluated in the provided environment
+func (p PlotSpec) ParseEnv(env Env) (Plot, error) {
+	if env.State == nil {
+		return nil, errors.New("nil plot state")
+	}
+	return parsePlotMap(p, env)
+}
+
+// Env is the environment plots are evaluated in, it gives them access to data from outside of their spec
+type Env struct {
+	// State is where stateful plots keep their values
+	State PlotState
+	// Orders provides the fill prices of other orders, plots depending on them are out of range if it's nil
+	Orders FillPricer
+	// Market provides the mark price used by the price protection
+	Market MarkPricer
+	// Protection makes the parsed plot protected with the given bands, the plot is not protected if it's nil
+	Protection *Protection
+}
+
+// FillPricer looks up the fill price of an order by its name,
+// ok is false if the order doesn't exist or it's not filled yet.
+type FillPricer interface {
+	FillPrice(orderName string) (price float64, ok bool)
+}
+
+// PlotState holds the values remembered by stateful plots between evaluations,
+// it has to be persisted along with the PlotSpec it was parsed with.
+type PlotState map[string]float64
+
 type Plot interface {
 	At(time.Time) (float64, error)
 }
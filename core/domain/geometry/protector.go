@@ -9,23 +9,102 @@ import (
 
 var ErrPriceProtection = errors.New("price protection")
 
+// Protection configures the sanity bands a Protector checks the prices against, zero values disable the checks.
+type Protection struct {
+	MinPrice float64 `json:"minPrice"`
+	MaxPrice float64 `json:"maxPrice"`
+	// MaxMarkDeviation is the max distance from the current mark price as a fraction of it, 0.1 is 10%
+	MaxMarkDeviation float64 `json:"maxMarkDeviation"`
+	// MaxJump is the max change from the last accepted price as a fraction of it,
+	// rejected prices aren't remembered so a persistent bad price stays rejected.
+	MaxJump float64 `json:"maxJump"`
+}
+
+// DefaultProtection is applied to orders that don't configure their own protection
+var DefaultProtection = Protection{
+	MaxMarkDeviation: 0.5,
+}
+
+// MarkPricer returns the current mark price of the traded pair
+type MarkPricer interface {
+	MarkPrice() (float64, error)
+}
+
 // Protector checks for most obvious wrong price values like:
-// -Inf, 0, +Inf and returns an error if such price is calculated
+// NaN, -Inf, negative values, 0, +Inf and returns an error if such price is calculated.
+// If configured with a Protection it also checks the price against its bands.
 type Protector struct {
-	of Plot
+	of         Plot
+	protection Protection
+	market     MarkPricer
+	stateKey   string
+	state      PlotState
 }
 
 func NewProtector(of Plot) Plot {
 	return &Protector{of: of}
 }
 
+// NewProtectorWithConfig creates a Protector checking the protection bands, the mark price is taken from env.Market
+// and the last accepted price is kept in env.State under stateKey to check the jumps between evaluations.
+func NewProtectorWithConfig(of Plot, protection Protection, env Env, stateKey string) Plot {
+	return &Protector{
+		of:         of,
+		protection: protection,
+		market:     env.Market,
+		stateKey:   stateKey,
+		state:      env.State,
+	}
+}
+
 func (p *Protector) At(t time.Time) (float64, error) {
 	price, err := p.of.At(t)
 	if err != nil {
 		return 0, err
 	}
-	if price == 0 || math.IsInf(price, 0) {
+	if price <= 0 || math.IsNaN(price) || math.IsInf(price, 0) {
 		return 0, fmt.Errorf("%w: %f", ErrPriceProtection, price)
 	}
+	if err := p.checkBands(price); err != nil {
+		return 0, err
+	}
+	if p.state != nil {
+		p.state[p.stateKey] = price
+	}
 	return price, err
 }
+
+func (p *Protector) checkBands(price float64) error {
+	pr := p.protection
+
+	if pr.MinPrice != 0 && price < pr.MinPrice {
+		return fmt.Errorf("%w: price %f below min price %f", ErrPriceProtection, price, pr.MinPrice)
+	}
+
+	if pr.MaxPrice != 0 && price > pr.MaxPrice {
+		return fmt.Errorf("%w: price %f above max price %f", ErrPriceProtection, price, pr.MaxPrice)
+	}
+
+	if pr.MaxMarkDeviation != 0 {
+		if p.market == nil {
+			return fmt.Errorf("%w: mark price unavailable", ErrPriceProtection)
+		}
+
+		mark, err := p.market.MarkPrice()
+		if err != nil {
+			return fmt.Errorf("error getting mark price: %w", err)
+		}
+
+		if deviation := math.Abs(price-mark) / mark; deviation > pr.MaxMarkDeviation {
+			return fmt.Errorf("%w: price %f is %.2f%% away from mark price %f", ErrPriceProtection, price, deviation*100, mark)
+		}
+	}
+
+	if last, ok := p.state[p.stateKey]; ok && pr.MaxJump != 0 {
+		if jump := math.Abs(price-last) / last; jump > pr.MaxJump {
+			return fmt.Errorf("%w: price %f jumped %.2f%% from %f", ErrPriceProtection, price, jump*100, last)
+		}
+	}
+
+	return nil
+}
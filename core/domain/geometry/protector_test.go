@@ -37,6 +37,18 @@ func TestProtector_At(t *testing.T) {
 			want:        0,
 			expectedErr: geometry.ErrPriceProtection,
 		},
+		{
+			name:        "negative protection",
+			of:          &valuePlot{-1},
+			want:        0,
+			expectedErr: geometry.ErrPriceProtection,
+		},
+		{
+			name:        "nan protection",
+			of:          &valuePlot{zero / zero},
+			want:        0,
+			expectedErr: geometry.ErrPriceProtection,
+		},
 		{
 			name:        "no protection",
 			of:          &valuePlot{1},
@@ -60,6 +72,154 @@ func TestProtector_At(t *testing.T) {
 	}
 }
 
+func TestProtectorWithConfig_At(t *testing.T) {
+	tests := []struct {
+		name        string
+		protection  geometry.Protection
+		market      geometry.MarkPricer
+		last        *float64
+		price       float64
+		want        float64
+		expectedErr error
+	}{
+		{
+			name:  "no bands",
+			price: 100,
+			want:  100,
+		},
+		{
+			name:        "below min",
+			protection:  geometry.Protection{MinPrice: 101},
+			price:       100,
+			expectedErr: geometry.ErrPriceProtection,
+		},
+		{
+			name:        "above max",
+			protection:  geometry.Protection{MaxPrice: 99},
+			price:       100,
+			expectedErr: geometry.ErrPriceProtection,
+		},
+		{
+			name:       "inside min max",
+			protection: geometry.Protection{MinPrice: 99, MaxPrice: 101},
+			price:      100,
+			want:       100,
+		},
+		{
+			name:       "close to mark",
+			protection: geometry.Protection{MaxMarkDeviation: 0.1},
+			market:     markPrice(105),
+			price:      100,
+			want:       100,
+		},
+		{
+			name:        "far from mark",
+			protection:  geometry.Protection{MaxMarkDeviation: 0.1},
+			market:      markPrice(1000),
+			price:       100,
+			expectedErr: geometry.ErrPriceProtection,
+		},
+		{
+			name:        "no mark price",
+			protection:  geometry.Protection{MaxMarkDeviation: 0.1},
+			price:       100,
+			expectedErr: geometry.ErrPriceProtection,
+		},
+		{
+			name:        "mark price error",
+			protection:  geometry.Protection{MaxMarkDeviation: 0.1},
+			market:      errMarkPrice{},
+			price:       100,
+			expectedErr: testErr,
+		},
+		{
+			name:       "small jump",
+			protection: geometry.Protection{MaxJump: 0.05},
+			last:       ptr(98.0),
+			price:      100,
+			want:       100,
+		},
+		{
+			name:        "big jump",
+			protection:  geometry.Protection{MaxJump: 0.05},
+			last:        ptr(50.0),
+			price:       100,
+			expectedErr: geometry.ErrPriceProtection,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := geometry.PlotState{}
+			if tt.last != nil {
+				state["protector_0"] = *tt.last
+			}
+
+			env := geometry.Env{State: state, Market: tt.market}
+			p := geometry.NewProtectorWithConfig(&valuePlot{tt.price}, tt.protection, env, "protector_0")
+			got, err := p.At(time.Time{})
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.Equal(t, tt.want, got)
+
+			if tt.expectedErr == nil {
+				assert.Equal(t, tt.price, state["protector_0"])
+			} else if tt.last != nil {
+				assert.Equal(t, *tt.last, state["protector_0"])
+			}
+		})
+	}
+}
+
+func TestProtector_At_PersistentJump(t *testing.T) {
+	state := geometry.PlotState{"protector_0": 50}
+	p := geometry.NewProtectorWithConfig(&valuePlot{100}, geometry.Protection{MaxJump: 0.05}, geometry.Env{State: state}, "protector_0")
+
+	// the jump is measured from the last accepted price on every tick
+	for i := 0; i < 2; i++ {
+		_, err := p.At(time.Time{})
+		assert.ErrorIs(t, err, geometry.ErrPriceProtection)
+		assert.Equal(t, 50.0, state["protector_0"])
+	}
+}
+
+func TestPlotSpec_ParseEnv_Protection(t *testing.T) {
+	spec := geometry.PlotSpec{
+		"type": "line",
+		"args": map[string]any{
+			"p0": map[string]any{"date": "2023-01-01", "price": 10},
+			"p1": map[string]any{"date": "2023-01-02", "price": -10},
+		},
+	}
+
+	plot, err := spec.ParseEnv(geometry.Env{State: geometry.PlotState{}, Protection: &geometry.Protection{}})
+	assert.NoError(t, err)
+
+	_, err = plot.At(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC))
+	assert.ErrorIs(t, err, geometry.ErrPriceProtection)
+
+	plot, err = spec.Parse()
+	assert.NoError(t, err)
+
+	got, err := plot.At(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, -10.0, got)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+type markPrice float64
+
+func (m markPrice) MarkPrice() (float64, error) {
+	return float64(m), nil
+}
+
+type errMarkPrice struct{}
+
+func (errMarkPrice) MarkPrice() (float64, error) {
+	return 0, testErr
+}
+
 type valuePlot struct {
 	ret float64
 }
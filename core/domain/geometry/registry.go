@@ -0,0 +1,262 @@
+package geometry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Argument types used in PlotArg
+const (
+	ArgTypeNumber  = "number"
+	ArgTypeString  = "string"
+	ArgTypeStrings = "strings"
+	ArgTypeTime    = "time"
+	ArgTypePoint   = "point"
+	ArgTypePoints  = "points"
+	ArgTypePlot    = "plot"
+	ArgTypePlots   = "plots"
+)
+
+// PlotConstructor creates a plot from its unparsed arguments,
+// nested plots should be parsed with the provided Parser.
+type PlotConstructor func(args json.RawMessage, p *Parser) (Plot, error)
+
+// PlotType describes a plot that can be used in a PlotSpec
+type PlotType struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Args        []PlotArg       `json:"args"`
+	New         PlotConstructor `json:"-"`
+}
+
+// PlotArg describes a single argument of a PlotType
+type PlotArg struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Required    bool   `json:"required"`
+	Description string `json:"description,omitempty"`
+}
+
+var registry = struct {
+	mu    sync.RWMutex
+	types map[string]PlotType
+}{types: map[string]PlotType{}}
+
+// RegisterPlotType makes the plot type available in PlotSpecs,
+// returns error if the type is incomplete or its name is already registered.
+func RegisterPlotType(pt PlotType) error {
+	if pt.Name == "" {
+		return errors.New("error registering plot type: empty name")
+	}
+	if pt.New == nil {
+		return fmt.Errorf("error registering plot type %s: nil constructor", pt.Name)
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if _, ok := registry.types[pt.Name]; ok {
+		return fmt.Errorf("error registering plot type %s: already registered", pt.Name)
+	}
+
+	registry.types[pt.Name] = pt
+	return nil
+}
+
+// MustRegisterPlotType is like RegisterPlotType but panics on error, meant to be used in init functions
+func MustRegisterPlotType(pt PlotType) {
+	if err := RegisterPlotType(pt); err != nil {
+		panic(err)
+	}
+}
+
+// PlotTypes returns all registered plot types sorted by name
+func PlotTypes() []PlotType {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	types := make([]PlotType, 0, len(registry.types))
+	for _, pt := range registry.types {
+		types = append(types, pt)
+	}
+
+	sort.Slice(types, func(i, j int) bool { return types[i].Name < types[j].Name })
+	return types
+}
+
+func lookupPlotType(name string) (PlotType, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	pt, ok := registry.types[name]
+	return pt, ok
+}
+
+func init() {
+	plotArg := PlotArg{Name: "plot", Type: ArgTypePlot, Required: true}
+	lineArgs := []PlotArg{
+		{Name: "p0", Type: ArgTypePoint, Required: true},
+		{Name: "p1", Type: ArgTypePoint, Required: true},
+	}
+
+	builtins := []PlotType{
+		{
+			Name:        KEY_LINE,
+			Description: "straight line going through 2 points",
+			Args:        lineArgs,
+			New:         newLinePlot,
+		},
+		{
+			Name:        KEY_LINE_LOG,
+			Description: "straight line going through 2 points on a logarithmic price scale",
+			Args:        lineArgs,
+			New:         newLogLinePlot,
+		},
+		{
+			Name:        KEY_OFFSET_ABSOLUTE,
+			Description: "plot offset by a constant value",
+			Args:        []PlotArg{{Name: "value", Type: ArgTypeNumber, Required: true}, plotArg},
+			New:         newAbsoluteOffsetPlot,
+		},
+		{
+			Name:        KEY_OFFSET_PERCENTAGE,
+			Description: "plot offset by a fraction of its value, 0.01 is 1%",
+			Args:        []PlotArg{{Name: "value", Type: ArgTypeNumber, Required: true}, plotArg},
+			New:         newPercentageOffsetPlot,
+		},
+		{
+			Name:        KEY_LIMIT,
+			Description: "plot valid only in the [since, until) time range",
+			Args: []PlotArg{
+				{Name: "since", Type: ArgTypeTime, Required: true},
+				{Name: "until", Type: ArgTypeTime, Required: true},
+				plotArg,
+			},
+			New: newLimitPlot,
+		},
+		{
+			Name:        KEY_MIN,
+			Description: "minimum value of all plots in range",
+			Args:        []PlotArg{{Name: "plots", Type: ArgTypePlots, Required: true}},
+			New:         newMinPlot,
+		},
+		{
+			Name:        KEY_MAX,
+			Description: "maximum value of all plots in range",
+			Args:        []PlotArg{{Name: "plots", Type: ArgTypePlots, Required: true}},
+			New:         newMaxPlot,
+		},
+		{
+			Name:        KEY_TRAIL,
+			Description: "plot that only moves in one direction and remembers its best value",
+			Args: []PlotArg{
+				{Name: "direction", Type: ArgTypeString, Required: true, Description: "up or down"},
+				plotArg,
+			},
+			New: newTrailPlot,
+		},
+		{
+			Name:        KEY_FILL_PRICE,
+			Description: "fill price of another order in the same follow, out of range until it's filled",
+			Args:        []PlotArg{{Name: "order", Type: ArgTypeString, Required: true, Description: "name of the order"}},
+			New:         newFillPricePlot,
+		},
+	}
+
+	builtins = append(builtins, regressionPlotTypes()...)
+	builtins = append(builtins, curvePlotTypes()...)
+	builtins = append(builtins, periodicPlotTypes()...)
+
+	for _, pt := range builtins {
+		MustRegisterPlotType(pt)
+	}
+}
+
+func regressionPlotTypes() []PlotType {
+	pointsArg := PlotArg{Name: "points", Type: ArgTypePoints, Required: true, Description: "points to fit the curve through"}
+	deviationsArg := PlotArg{Name: "deviations", Type: ArgTypeNumber, Description: "offset by a multiple of the residuals' standard deviation"}
+
+	return []PlotType{
+		{
+			Name:        KEY_REGRESSION,
+			Description: "least squares linear regression",
+			Args:        []PlotArg{pointsArg, deviationsArg},
+			New:         newRegressionPlot,
+		},
+		{
+			Name:        KEY_REGRESSION_POLYNOMIAL,
+			Description: "least squares polynomial regression",
+			Args:        []PlotArg{pointsArg, {Name: "degree", Type: ArgTypeNumber, Required: true}, deviationsArg},
+			New:         newPolynomialRegressionPlot,
+		},
+		{
+			Name:        KEY_REGRESSION_LOG,
+			Description: "least squares regression on a logarithmic price scale",
+			Args:        []PlotArg{pointsArg, {Name: "degree", Type: ArgTypeNumber, Description: "defaults to 1"}, deviationsArg},
+			New:         newLogRegressionPlot,
+		},
+	}
+}
+
+func curvePlotTypes() []PlotType {
+	spanArg := PlotArg{Name: "span", Type: ArgTypeString, Description: "behavior outside of the points: error (default), clamp or extrapolate"}
+
+	return []PlotType{
+		{
+			Name:        KEY_SPLINE,
+			Description: "monotone cubic spline going through all points",
+			Args:        []PlotArg{{Name: "points", Type: ArgTypePoints, Required: true}, spanArg},
+			New:         newSplinePlot,
+		},
+		{
+			Name:        KEY_SPLINE_LOG,
+			Description: "monotone cubic spline going through all points on a logarithmic price scale",
+			Args:        []PlotArg{{Name: "points", Type: ArgTypePoints, Required: true}, spanArg},
+			New:         newLogSplinePlot,
+		},
+		{
+			Name:        KEY_BEZIER,
+			Description: "bezier curve defined by its control points",
+			Args:        []PlotArg{{Name: "points", Type: ArgTypePoints, Required: true, Description: "control points"}, spanArg},
+			New:         newBezierPlot,
+		},
+		{
+			Name:        KEY_BEZIER_LOG,
+			Description: "bezier curve defined by its control points on a logarithmic price scale",
+			Args:        []PlotArg{{Name: "points", Type: ArgTypePoints, Required: true, Description: "control points"}, spanArg},
+			New:         newLogBezierPlot,
+		},
+	}
+}
+
+func periodicPlotTypes() []PlotType {
+	plotArg := PlotArg{Name: "plot", Type: ArgTypePlot, Required: true}
+
+	return []PlotType{
+		{
+			Name:        KEY_SESSION,
+			Description: "plot valid only during a recurring daily [from, to) window",
+			Args: []PlotArg{
+				{Name: "days", Type: ArgTypeStrings, Description: "mon..sun, weekdays or weekends, every day if empty"},
+				{Name: "from", Type: ArgTypeString, Required: true, Description: "time of day, 15:04"},
+				{Name: "to", Type: ArgTypeString, Required: true, Description: "time of day, 15:04, window spans midnight if before from"},
+				{Name: "timezone", Type: ArgTypeString, Description: "IANA time zone, defaults to UTC"},
+				plotArg,
+			},
+			New: newSessionPlot,
+		},
+		{
+			Name:        KEY_REPEAT,
+			Description: "plot repeated every period, the pattern is taken from [start, start+period)",
+			Args: []PlotArg{
+				{Name: "start", Type: ArgTypeTime, Required: true},
+				{Name: "period", Type: ArgTypeString, Required: true, Description: "duration, 4h"},
+				plotArg,
+			},
+			New: newRepeatPlot,
+		},
+	}
+}
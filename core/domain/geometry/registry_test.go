@@ -0,0 +1,83 @@
+package geometry_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/domain/geometry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterPlotType(t *testing.T) {
+	constant := func(args json.RawMessage, p *geometry.Parser) (geometry.Plot, error) {
+		v := struct{ Value float64 }{}
+		if err := json.Unmarshal(args, &v); err != nil {
+			return nil, err
+		}
+		return &valuePlot{v.Value}, nil
+	}
+
+	tests := []struct {
+		name      string
+		pt        geometry.PlotType
+		expectErr bool
+	}{
+		{"empty name", geometry.PlotType{New: constant}, true},
+		{"nil constructor", geometry.PlotType{Name: "test_nil"}, true},
+		{"builtin", geometry.PlotType{Name: geometry.KEY_LINE, New: constant}, true},
+		{"ok", geometry.PlotType{Name: "test_constant", New: constant}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := geometry.RegisterPlotType(tt.pt)
+			assert.Equal(t, tt.expectErr, err != nil)
+		})
+	}
+
+	// registered type can be nested in builtin ones
+	spec := geometry.PlotSpec{
+		"type": "offset_absolute",
+		"args": map[string]any{
+			"value": 1,
+			"plot": map[string]any{
+				"type": "test_constant",
+				"args": map[string]any{"value": 5},
+			},
+		},
+	}
+
+	plot, err := spec.Parse()
+	assert.NoError(t, err)
+
+	got, err := plot.At(time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, 6.0, got)
+}
+
+func TestPlotTypes(t *testing.T) {
+	names := []string{}
+	for _, pt := range geometry.PlotTypes() {
+		names = append(names, pt.Name)
+		assert.NotNil(t, pt.New)
+	}
+
+	assert.IsNonDecreasing(t, names)
+	assert.Subset(t, names, []string{
+		geometry.KEY_LINE,
+		geometry.KEY_LINE_LOG,
+		geometry.KEY_OFFSET_ABSOLUTE,
+		geometry.KEY_OFFSET_PERCENTAGE,
+		geometry.KEY_LIMIT,
+		geometry.KEY_MIN,
+		geometry.KEY_MAX,
+		geometry.KEY_TRAIL,
+		geometry.KEY_FILL_PRICE,
+	})
+}
+
+func TestPlotSpec_Parse_Unknown(t *testing.T) {
+	_, err := geometry.PlotSpec{"type": "unknown"}.Parse()
+	assert.Error(t, err)
+}
@@ -0,0 +1,154 @@
+package geometry
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Regression is a polynomial fitted through points with the least squares method, degree 1 is a linear regression.
+// If Log is true the polynomial is fitted to log10 of the prices, like LogLine.
+// Deviations shifts the curve by a multiple of the residuals' standard deviation, which allows drawing regression channels.
+type Regression struct {
+	// Coefficients of the polynomial, starting from the constant term
+	Coefficients []float64
+	// Xoffset and Xscale normalize the time so the fit is numerically stable
+	Xoffset, Xscale float64
+	StdDev          float64
+	Deviations      float64
+	Log             bool
+}
+
+// NewRegression fits a polynomial of the given degree through the points
+func NewRegression(points []Point, degree int, deviations float64) (*Regression, error) {
+	return newRegression(points, degree, deviations, false)
+}
+
+// NewLogRegression fits a polynomial of the given degree through log10 of the points' prices
+func NewLogRegression(points []Point, degree int, deviations float64) (*Regression, error) {
+	return newRegression(points, degree, deviations, true)
+}
+
+func newRegression(points []Point, degree int, deviations float64, log bool) (*Regression, error) {
+	if degree < 1 {
+		return nil, fmt.Errorf("error creating regression: degree must be at least 1, got: %d", degree)
+	}
+	if len(points) < degree+1 {
+		return nil, fmt.Errorf("error creating regression: at least %d points are required, got: %d", degree+1, len(points))
+	}
+
+	points = sortPoints(append([]Point{}, points...)...)
+
+	r := &Regression{
+		Xoffset:    timeToFloat64(points[0].Date),
+		Xscale:     timeToFloat64(points[len(points)-1].Date) - timeToFloat64(points[0].Date),
+		Deviations: deviations,
+		Log:        log,
+	}
+	if r.Xscale == 0 {
+		return nil, errors.New("error creating regression: all points have the same date")
+	}
+
+	xs := make([]float64, len(points))
+	ys := make([]float64, len(points))
+	for i, p := range points {
+		if log && p.Price <= 0 {
+			return nil, fmt.Errorf("error creating log regression: non-positive price %f", p.Price)
+		}
+		xs[i] = r.x(p.Date)
+		ys[i] = r.y(p.Price)
+	}
+
+	coefficients, err := fitPolynomial(xs, ys, degree)
+	if err != nil {
+		return nil, fmt.Errorf("error creating regression: %w", err)
+	}
+	r.Coefficients = coefficients
+
+	var ssr float64
+	for i := range xs {
+		res := ys[i] - r.polynomial(xs[i])
+		ssr += res * res
+	}
+	r.StdDev = math.Sqrt(ssr / float64(len(xs)))
+
+	return r, nil
+}
+
+func (r *Regression) At(t time.Time) (float64, error) {
+	y := r.polynomial(r.x(t)) + r.Deviations*r.StdDev
+	if r.Log {
+		return math.Pow(10, y), nil
+	}
+	return y, nil
+}
+
+func (r *Regression) x(t time.Time) float64 {
+	return (timeToFloat64(t) - r.Xoffset) / r.Xscale
+}
+
+func (r *Regression) y(price float64) float64 {
+	if r.Log {
+		return math.Log10(price)
+	}
+	return price
+}
+
+func (r *Regression) polynomial(x float64) float64 {
+	y := 0.0
+	for i := len(r.Coefficients) - 1; i >= 0; i-- {
+		y = y*x + r.Coefficients[i]
+	}
+	return y
+}
+
+// fitPolynomial returns the least squares polynomial coefficients, starting from the constant term,
+// by solving the normal equations with gaussian elimination.
+func fitPolynomial(xs, ys []float64, degree int) ([]float64, error) {
+	n := degree + 1
+
+	// augmented matrix of the normal equations
+	m := make([][]float64, n)
+	for i := range m {
+		m[i] = make([]float64, n+1)
+		for k := range xs {
+			xi := math.Pow(xs[k], float64(i))
+			for j := 0; j < n; j++ {
+				m[i][j] += xi * math.Pow(xs[k], float64(j))
+			}
+			m[i][n] += xi * ys[k]
+		}
+	}
+
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(m[row][col]) > math.Abs(m[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(m[pivot][col]) < 1e-12 {
+			return nil, errors.New("not enough distinct points to fit the polynomial")
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+
+		for row := col + 1; row < n; row++ {
+			f := m[row][col] / m[col][col]
+			for j := col; j <= n; j++ {
+				m[row][j] -= f * m[col][j]
+			}
+		}
+	}
+
+	coefficients := make([]float64, n)
+	for i := n - 1; i >= 0; i-- {
+		v := m[i][n]
+		for j := i + 1; j < n; j++ {
+			v -= m[i][j] * coefficients[j]
+		}
+		coefficients[i] = v / m[i][i]
+	}
+
+	return coefficients, nil
+}
@@ -0,0 +1,153 @@
+package geometry_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/domain/geometry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRegression(t *testing.T) {
+	tests := []struct {
+		name      string
+		points    []geometry.Point
+		degree    int
+		expectErr bool
+	}{
+		{
+			name:      "zero degree",
+			points:    []geometry.Point{{time.Unix(0, 0), 1}, {time.Unix(1, 0), 2}},
+			degree:    0,
+			expectErr: true,
+		},
+		{
+			name:      "not enough points",
+			points:    []geometry.Point{{time.Unix(0, 0), 1}, {time.Unix(1, 0), 2}},
+			degree:    2,
+			expectErr: true,
+		},
+		{
+			name:      "same dates",
+			points:    []geometry.Point{{time.Unix(0, 0), 1}, {time.Unix(0, 0), 2}},
+			degree:    1,
+			expectErr: true,
+		},
+		{
+			name:      "not enough distinct dates",
+			points:    []geometry.Point{{time.Unix(0, 0), 1}, {time.Unix(0, 0), 2}, {time.Unix(1, 0), 2}},
+			degree:    2,
+			expectErr: true,
+		},
+		{
+			name:   "ok",
+			points: []geometry.Point{{time.Unix(0, 0), 1}, {time.Unix(1, 0), 2}},
+			degree: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := geometry.NewRegression(tt.points, tt.degree, 0)
+			assert.Equal(t, tt.expectErr, err != nil)
+			assert.Equal(t, tt.expectErr, r == nil)
+		})
+	}
+}
+
+func TestRegression_At(t *testing.T) {
+	day := int64(24 * 60 * 60)
+	base := int64(1700000000)
+	at := func(d int64) time.Time { return time.Unix(base+d*day, 0) }
+
+	tests := []struct {
+		name       string
+		points     []geometry.Point
+		degree     int
+		deviations float64
+		log        bool
+		x          time.Time
+		want       float64
+	}{
+		{
+			name:   "linear - exact",
+			points: []geometry.Point{{at(0), 10}, {at(1), 12}, {at(2), 14}},
+			degree: 1,
+			x:      at(5),
+			want:   20,
+		},
+		{
+			name:   "linear - fitted",
+			points: []geometry.Point{{at(0), 11}, {at(1), 11}, {at(2), 15}, {at(3), 15}},
+			degree: 1,
+			x:      at(4),
+			want:   17,
+		},
+		{
+			name:       "linear - channel",
+			points:     []geometry.Point{{at(0), 11}, {at(1), 9}, {at(2), 11}, {at(3), 9}},
+			degree:     1,
+			deviations: 2,
+			x:          at(1),
+			want:       10.2 + 2*math.Sqrt(0.8),
+		},
+		{
+			name:   "quadratic",
+			points: []geometry.Point{{at(0), 4}, {at(1), 1}, {at(2), 0}, {at(3), 1}, {at(4), 4}},
+			degree: 2,
+			x:      at(6),
+			want:   16,
+		},
+		{
+			name:   "log",
+			points: []geometry.Point{{at(0), 1}, {at(1), 10}, {at(2), 100}},
+			degree: 1,
+			log:    true,
+			x:      at(3),
+			want:   1000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newFunc := geometry.NewRegression
+			if tt.log {
+				newFunc = geometry.NewLogRegression
+			}
+
+			r, err := newFunc(tt.points, tt.degree, tt.deviations)
+			assert.NoError(t, err)
+
+			got, err := r.At(tt.x)
+			assert.NoError(t, err)
+			assert.InDelta(t, tt.want, got, 1e-6)
+		})
+	}
+}
+
+func TestNewLogRegression_NonPositive(t *testing.T) {
+	_, err := geometry.NewLogRegression([]geometry.Point{{time.Unix(0, 0), 1}, {time.Unix(1, 0), 0}}, 1, 0)
+	assert.Error(t, err)
+}
+
+func TestPlotSpec_Parse_Regression(t *testing.T) {
+	spec := geometry.PlotSpec{
+		"type": "regression_log",
+		"args": map[string]any{
+			"points": []map[string]any{
+				{"date": "2023-01-01", "price": 1},
+				{"date": "2023-01-02", "price": 10},
+				{"date": "2023-01-03", "price": 100},
+			},
+		},
+	}
+
+	plot, err := spec.Parse()
+	assert.NoError(t, err)
+
+	got, err := plot.At(time.Date(2023, 1, 4, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.InDelta(t, 1000, got, 1e-6)
+	assert.False(t, math.IsNaN(got))
+}
@@ -0,0 +1,58 @@
+package geometry
+
+import (
+	"fmt"
+	"time"
+)
+
+type TrailDirection string
+
+var (
+	TrailDirectionUp   TrailDirection = "up"
+	TrailDirectionDown TrailDirection = "down"
+)
+
+// Trail is a Plot wrapper that lets the value of the wrapped plot move only in one direction.
+// It remembers the best value seen so far and returns it whenever the wrapped plot moves the other way,
+// up direction is meant for long stops and down direction for short stops.
+type Trail struct {
+	Plot      Plot
+	Direction TrailDirection
+	Key       string
+	State     PlotState
+}
+
+// NewTrail is a constructor for Trail, the best value is kept in state under the given key
+func NewTrail(plot Plot, direction TrailDirection, key string, state PlotState) (*Trail, error) {
+	if direction != TrailDirectionUp && direction != TrailDirectionDown {
+		return nil, fmt.Errorf("error creating trail: unknown direction %s", direction)
+	}
+
+	if state == nil {
+		state = PlotState{}
+	}
+
+	return &Trail{Plot: plot, Direction: direction, Key: key, State: state}, nil
+}
+
+func (tr *Trail) At(t time.Time) (float64, error) {
+	v, err := tr.Plot.At(t)
+	if err != nil {
+		return 0, err
+	}
+
+	best, ok := tr.State[tr.Key]
+	if !ok || tr.better(v, best) {
+		tr.State[tr.Key] = v
+		return v, nil
+	}
+
+	return best, nil
+}
+
+func (tr *Trail) better(v, best float64) bool {
+	if tr.Direction == TrailDirectionUp {
+		return v > best
+	}
+	return v < best
+}
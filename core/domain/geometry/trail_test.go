@@ -0,0 +1,105 @@
+package geometry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/domain/geometry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTrail(t *testing.T) {
+	_, err := geometry.NewTrail(&alwaysValid{1}, "sideways", "key", geometry.PlotState{})
+	assert.Error(t, err)
+
+	tr, err := geometry.NewTrail(&alwaysValid{1}, geometry.TrailDirectionUp, "key", nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, tr.State)
+}
+
+func TestTrail_At(t *testing.T) {
+	// line going up from 0 to 10 and back down to 0
+	peak := &geometry.Line{A: 1, B: 0}
+	valley := &geometry.Line{A: -1, B: 20}
+	plot := &geometry.Min{Plots: []geometry.Plot{peak, valley}}
+
+	tests := []struct {
+		name      string
+		direction geometry.TrailDirection
+		times     []int64
+		want      []float64
+	}{
+		{
+			name:      "up",
+			direction: geometry.TrailDirectionUp,
+			times:     []int64{0, 5, 10, 15, 20},
+			want:      []float64{0, 5, 10, 10, 10},
+		},
+		{
+			name:      "down",
+			direction: geometry.TrailDirectionDown,
+			times:     []int64{10, 15, 20, 25},
+			want:      []float64{10, 5, 0, -5},
+		},
+		{
+			name:      "down - rising",
+			direction: geometry.TrailDirectionDown,
+			times:     []int64{0, 5, 10},
+			want:      []float64{0, 0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := geometry.PlotState{}
+			for i, sec := range tt.times {
+				// recreate the trail each time to make sure only the state carries the best value
+				tr, err := geometry.NewTrail(plot, tt.direction, "trail_0", state)
+				assert.NoError(t, err)
+
+				got, err := tr.At(time.Unix(sec, 0))
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want[i], got)
+			}
+		})
+	}
+}
+
+func TestTrail_At_PropagateError(t *testing.T) {
+	state := geometry.PlotState{}
+	tr, err := geometry.NewTrail(&errPlot{}, geometry.TrailDirectionUp, "trail_0", state)
+	assert.NoError(t, err)
+
+	_, err = tr.At(time.Time{})
+	assert.ErrorIs(t, err, testErr)
+	assert.Empty(t, state)
+}
+
+func TestPlotSpec_ParseWithState_Trail(t *testing.T) {
+	spec := geometry.PlotSpec{
+		"type": "trail",
+		"args": map[string]any{
+			"direction": "up",
+			"plot": map[string]any{
+				"type": "line",
+				"args": map[string]any{
+					"p0": map[string]any{"date": "2023-01-01", "price": 10},
+					"p1": map[string]any{"date": "2023-01-02", "price": 5},
+				},
+			},
+		},
+	}
+
+	state := geometry.PlotState{}
+	plot, err := spec.ParseWithState(state)
+	assert.NoError(t, err)
+
+	v0, err := plot.At(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, v0)
+
+	v1, err := plot.At(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, v1)
+	assert.Equal(t, geometry.PlotState{"trail_0": 10}, state)
+}
@@ -1,18 +1,50 @@
 package domain
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
 	"github.com/H3Cki/Plotrader/core/domain/geometry"
 )
 
 type OrderType string
 
 var (
-	OrderTypeLimit      OrderType = "LIMIT"
-	OrderTypeMarket     OrderType = "MARKET"
-	OrderTypeTakeProfit OrderType = "TAKE_PROFIT"
-	OrderTypeStopLoss   OrderType = "STOP_LOSS"
+	OrderTypeLimit           OrderType = "LIMIT"
+	OrderTypeMarket          OrderType = "MARKET"
+	OrderTypeTakeProfit      OrderType = "TAKE_PROFIT"
+	OrderTypeStopLoss        OrderType = "STOP_LOSS"
+	OrderTypeTakeProfitLimit OrderType = "TAKE_PROFIT_LIMIT"
+	OrderTypeStopLossLimit   OrderType = "STOP_LOSS_LIMIT"
+	// OrderTypeOCO is a limit order and a stop limit order, when one of them executes the other is canceled
+	OrderTypeOCO OrderType = "OCO"
 )
 
+// Triggered returns true if orders of this type are placed with a stop price,
+// the plot of such order calculates the stop price.
+func (t OrderType) Triggered() bool {
+	switch t {
+	case OrderTypeTakeProfit, OrderTypeStopLoss, OrderTypeTakeProfitLimit, OrderTypeStopLossLimit:
+		return true
+	}
+	return false
+}
+
+// Limited returns true if orders of this type are placed with a limit price
+func (t OrderType) Limited() bool {
+	switch t {
+	case OrderTypeLimit, OrderTypeTakeProfitLimit, OrderTypeStopLossLimit, OrderTypeOCO:
+		return true
+	}
+	return false
+}
+
+// Closing returns true if orders of this type close a position instead of opening it
+func (t OrderType) Closing() bool {
+	return t.Triggered() || t == OrderTypeOCO
+}
+
 type OrderSide string
 
 var (
@@ -20,6 +52,32 @@ var (
 	OrderSideSell OrderSide = "SELL"
 )
 
+// PositionSide is the side of the position the order belongs to,
+// exchanges in one-way position mode ignore it.
+type PositionSide string
+
+var (
+	PositionSideLong  PositionSide = "LONG"
+	PositionSideShort PositionSide = "SHORT"
+)
+
+type TimeInForce string
+
+var (
+	TimeInForceGTC TimeInForce = "GTC" // Good till canceled
+	TimeInForceIOC TimeInForce = "IOC" // Immediate or cancel
+	TimeInForceFOK TimeInForce = "FOK" // Fill or kill
+	TimeInForceGTX TimeInForce = "GTX" // Good till crossing, post only
+)
+
+// WorkingType is the price that triggers orders with a stop price
+type WorkingType string
+
+var (
+	WorkingTypeMarkPrice     WorkingType = "MARK_PRICE"
+	WorkingTypeContractPrice WorkingType = "CONTRACT_PRICE"
+)
+
 type OrderStatus string
 
 var (
@@ -31,22 +89,51 @@ var (
 )
 
 type Order struct {
-	ID            string            `json:"id"`
-	Name          string            `json:"name"`
-	Pair          Pair              `json:"pair"`
-	Status        OrderStatus       `json:"status"`
-	Type          OrderType         `json:"type"`
-	Side          OrderSide         `json:"side"`
-	QuoteQuantity float64           `json:"quoteQuantity"`
-	BaseQuantity  float64           `json:"baseQuantity"`
-	ClosePosition bool              `json:"closePosition"`
-	ReduceOnly    bool              `json:"reduceOnly"`
-	Relations     []StatusRelation  `json:"relations"`
-	PlotSpec      geometry.PlotSpec `json:"plotSpec"`
-	Plot          geometry.Plot     `json:"-" bson:"-"`
+	ID            string              `json:"id"`
+	Name          string              `json:"name"`
+	Pair          Pair                `json:"pair"`
+	Status        OrderStatus         `json:"status"`
+	Type          OrderType           `json:"type"`
+	Side          OrderSide           `json:"side"`
+	PositionSide  PositionSide        `json:"positionSide"`
+	QuoteQuantity float64             `json:"quoteQuantity"`
+	BaseQuantity  float64             `json:"baseQuantity"`
+	ClosePosition bool                `json:"closePosition"`
+	ReduceOnly    bool                `json:"reduceOnly"`
+	TimeInForce   TimeInForce         `json:"timeInForce"`
+	WorkingType   WorkingType         `json:"workingType"`
+	Relations     []StatusRelation    `json:"relations"`
+	PlotSpec      geometry.PlotSpec   `json:"plotSpec"`
+	PlotState     geometry.PlotState  `json:"plotState"`
+	Protection    geometry.Protection `json:"protection"`
+	Plot          geometry.Plot       `json:"-" bson:"-"`
+
+	// Limit price of triggered limit orders, calculated by LimitPlot if it's specified
+	// or by offsetting the stop price by LimitOffset otherwise.
+	LimitPlotSpec  geometry.PlotSpec  `json:"limitPlotSpec"`
+	LimitPlotState geometry.PlotState `json:"limitPlotState"`
+	LimitOffset    float64            `json:"limitOffset"`
+	LimitPlot      geometry.Plot      `json:"-" bson:"-"`
+
+	// Stop price of the stop limit leg of OCO orders, the limit leg is calculated by Plot
+	StopPlotSpec  geometry.PlotSpec  `json:"stopPlotSpec"`
+	StopPlotState geometry.PlotState `json:"stopPlotState"`
+	StopPlot      geometry.Plot      `json:"-" bson:"-"`
 
 	ExchangeHash  string         `json:"exchangeHash"`
 	ExchangeOrder *ExchangeOrder `json:"exchangeOrder"`
+	// Placements is the number of exchange orders placed for the order, each placement has its own client order ID
+	Placements int `json:"placements"`
+	// Rejection is why price protection rejected the last price of the order, empty once the order is placed or modified
+	Rejection string `json:"rejection,omitempty"`
+}
+
+// ClientOrderID returns the client order ID of the next placement of the order, it's derived from the ID
+// and the number of placements so retries of a placement that may have reached the exchange send the same ID.
+func (o Order) ClientOrderID() string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%d", o.ID, o.Placements)))
+	// exchanges limit client order IDs to 32-36 characters
+	return hex.EncodeToString(sum[:16])
 }
 
 type RelationCondition string
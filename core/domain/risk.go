@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+)
+
+// RiskLimits bound the orders placed by follows, zero values are unlimited.
+// Notionals are in the quote asset and only count orders that open positions.
+type RiskLimits struct {
+	MaxOrderNotional float64 `json:"maxOrderNotional"`
+	// MaxSymbolNotional limits the open orders of all follows of the account on a symbol
+	MaxSymbolNotional float64 `json:"maxSymbolNotional"`
+	// MaxAccountNotional limits the open orders of all follows of the account
+	MaxAccountNotional float64 `json:"maxAccountNotional"`
+	MaxOpenFollows     int     `json:"maxOpenFollows"`
+	// MaxLeverage requires follows to set their leverage
+	MaxLeverage int `json:"maxLeverage"`
+	// DailyLossLimit is the most the wallet balance of the quote asset may drop during a UTC day,
+	// it's checked on exchanges that can read the account.
+	DailyLossLimit float64 `json:"dailyLossLimit"`
+	// Symbols are the symbols follows may trade in the format of follow requests, e.g. BTC-USDT, all symbols if empty
+	Symbols []string `json:"symbols"`
+}
+
+// Override returns the limits with the non-zero limits of o
+func (l RiskLimits) Override(o RiskLimits) RiskLimits {
+	if o.MaxOrderNotional != 0 {
+		l.MaxOrderNotional = o.MaxOrderNotional
+	}
+	if o.MaxSymbolNotional != 0 {
+		l.MaxSymbolNotional = o.MaxSymbolNotional
+	}
+	if o.MaxAccountNotional != 0 {
+		l.MaxAccountNotional = o.MaxAccountNotional
+	}
+	if o.MaxOpenFollows != 0 {
+		l.MaxOpenFollows = o.MaxOpenFollows
+	}
+	if o.MaxLeverage != 0 {
+		l.MaxLeverage = o.MaxLeverage
+	}
+	if o.DailyLossLimit != 0 {
+		l.DailyLossLimit = o.DailyLossLimit
+	}
+	if len(o.Symbols) != 0 {
+		l.Symbols = o.Symbols
+	}
+	return l
+}
+
+// Allows returns true if the pair is in the allowed symbols
+func (l RiskLimits) Allows(pair Pair) bool {
+	return len(l.Symbols) == 0 || slices.Contains(l.Symbols, pair.Base+"-"+pair.Quote)
+}
+
+// RiskViolation is the limit an order or a follow would exceed, it blocks the order
+type RiskViolation struct {
+	Limit  string `json:"limit"` // Limit is the json name of the limit, e.g. maxOrderNotional
+	Reason string `json:"reason"`
+}
+
+func (v *RiskViolation) Error() string {
+	return fmt.Sprintf("risk limit %s: %s", v.Limit, v.Reason)
+}
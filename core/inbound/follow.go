@@ -14,6 +14,9 @@ type FollowService interface {
 	CreateFollow(context.Context, CreateFollowRequest) (CreateFollowResponse, error)
 	GetFollow(context.Context, GetFollowRequest) (GetFollowResponse, error)
 	StopFollow(context.Context, StopFollowRequest) error
+	PauseFollow(context.Context, PauseFollowRequest) error
+	ResumeFollow(context.Context, ResumeFollowRequest) error
+	ListFollowTransitions(context.Context, ListFollowTransitionsRequest) (ListFollowTransitionsResponse, error)
 }
 
 type CreateFollowRequest struct {
@@ -23,6 +26,10 @@ type CreateFollowRequest struct {
 	Orders   []CreateOrderRequest `json:"orders" validate:"required"`
 
 	WebhookURL string `json:"webhookURL"`
+
+	// Leverage and MarginType of the pair, the exchange account settings are kept if they're not specified
+	Leverage   int               `json:"leverage" validate:"omitempty,min=1"`
+	MarginType domain.MarginType `json:"marginType" validate:"omitempty,oneof=ISOLATED CROSSED"`
 }
 
 type CreateOrderRequest struct {
@@ -35,6 +42,22 @@ type CreateOrderRequest struct {
 	ReduceOnly    bool                    `json:"reduceOnly"`
 	Relations     []domain.StatusRelation `json:"relations"`
 	PlotSpec      geometry.PlotSpec       `json:"plot" validate:"required"`
+	// Protection overrides geometry.DefaultProtection, zero values disable the checks
+	Protection *geometry.Protection `json:"protection"`
+	// LimitPlotSpec calculates the limit price of TAKE_PROFIT_LIMIT and STOP_LOSS_LIMIT orders,
+	// if it's not specified the limit price is the stop price offset by LimitOffset, 0.01 is 1%.
+	LimitPlotSpec geometry.PlotSpec `json:"limitPlot"`
+	LimitOffset   float64           `json:"limitOffset"`
+	// StopPlotSpec calculates the stop price of OCO orders, the limit price of their stop leg
+	// is calculated by LimitPlotSpec or LimitOffset like for STOP_LOSS_LIMIT orders.
+	StopPlotSpec geometry.PlotSpec `json:"stopPlot"`
+	// TimeInForce of limit orders, GTC by default
+	TimeInForce domain.TimeInForce `json:"timeInForce"`
+	// WorkingType is the price that triggers orders with a stop price, exchange default if empty
+	WorkingType domain.WorkingType `json:"workingType"`
+	// PositionSide is used by accounts in hedge mode, if it's not specified LIMIT and MARKET orders
+	// open a position in the direction of their side and the other types close the opposite position.
+	PositionSide domain.PositionSide `json:"positionSide"`
 }
 
 type Exchange struct {
@@ -43,26 +66,23 @@ type Exchange struct {
 	Config    map[string]any `json:"config"`
 }
 
-func (e *Exchange) UnmarshalConfig(to any) error {
-	var cfgBytes []byte
-
+// ConfigJSON returns the json config of the exchange, read from the ConfigEnv variable if it's set
+func (e *Exchange) ConfigJSON() ([]byte, error) {
 	if e.ConfigEnv != "" {
 		v, ok := os.LookupEnv(e.ConfigEnv)
 		if !ok {
-			return fmt.Errorf("ENV %s not set", e.ConfigEnv)
+			return nil, fmt.Errorf("ENV %s not set", e.ConfigEnv)
 		}
+		return []byte(v), nil
+	}
 
-		cb, err := json.Marshal(v)
-		if err != nil {
-			return err
-		}
-		cfgBytes = cb
-	} else {
-		bytes, err := json.Marshal(e.Config)
-		if err != nil {
-			return err
-		}
-		cfgBytes = bytes
+	return json.Marshal(e.Config)
+}
+
+func (e *Exchange) UnmarshalConfig(to any) error {
+	cfgBytes, err := e.ConfigJSON()
+	if err != nil {
+		return err
 	}
 
 	return json.Unmarshal(cfgBytes, to)
@@ -86,3 +106,19 @@ type StopFollowRequest struct {
 	FollowID     string   `json:"followID" validate:"required"`
 	CancelOrders bool     `json:"cancelOrders"`
 }
+
+type PauseFollowRequest struct {
+	FollowID string `json:"followID" validate:"required"`
+}
+
+type ResumeFollowRequest struct {
+	FollowID string `json:"followID" validate:"required"`
+}
+
+type ListFollowTransitionsRequest struct {
+	FollowID string `json:"followID" validate:"required"`
+}
+
+type ListFollowTransitionsResponse struct {
+	Transitions []domain.FollowTransition `json:"transitions"`
+}
@@ -0,0 +1,29 @@
+package inbound
+
+import (
+	"context"
+
+	"github.com/H3Cki/Plotrader/core/domain/geometry"
+)
+
+type PlotService interface {
+	ListPlotTypes(context.Context, ListPlotTypesRequest) (ListPlotTypesResponse, error)
+	FindCrossings(context.Context, FindCrossingsRequest) (FindCrossingsResponse, error)
+}
+
+type ListPlotTypesRequest struct{}
+
+type ListPlotTypesResponse struct {
+	PlotTypes []geometry.PlotType `json:"plotTypes"`
+}
+
+type FindCrossingsRequest struct {
+	A    geometry.PlotSpec `json:"a" validate:"required"`
+	B    geometry.PlotSpec `json:"b" validate:"required"`
+	From string            `json:"from" validate:"required"`
+	To   string            `json:"to" validate:"required"`
+}
+
+type FindCrossingsResponse struct {
+	Crossings []geometry.Crossing `json:"crossings"`
+}
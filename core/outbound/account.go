@@ -0,0 +1,27 @@
+package outbound
+
+import (
+	"context"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+)
+
+// AccountReader is an optional Exchange capability of querying the positions, balances and open orders of the account
+type AccountReader interface {
+	// Positions returns the open positions of the pair, positions without an amount are left out
+	Positions(context.Context, PositionsRequest) ([]domain.Position, error)
+	Balances(context.Context, BalancesRequest) ([]domain.Balance, error)
+	OpenOrders(context.Context, OpenOrdersRequest) ([]domain.ExchangeOrder, error)
+}
+
+type PositionsRequest struct {
+	Pair domain.Pair
+}
+
+type BalancesRequest struct {
+	Asset string // Asset filters the balances, all balances are returned if it's empty
+}
+
+type OpenOrdersRequest struct {
+	Pair domain.Pair
+}
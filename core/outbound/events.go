@@ -0,0 +1,25 @@
+package outbound
+
+import (
+	"context"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+)
+
+// EventStreamer is an optional Exchange capability of pushing account events as they happen
+type EventStreamer interface {
+	// StreamEvents streams the events until ctx is done, the channel is closed afterwards
+	StreamEvents(context.Context) (<-chan ExchangeEvent, error)
+}
+
+type ExchangeEvent struct {
+	Time    time.Time
+	Order   *domain.ExchangeOrder // Order is set by order updates
+	Account *AccountUpdate        // Account is set by balance and position updates
+}
+
+type AccountUpdate struct {
+	Balances  []domain.Balance
+	Positions []domain.Position
+}
@@ -2,6 +2,7 @@ package outbound
 
 import (
 	"context"
+	"errors"
 
 	"github.com/H3Cki/Plotrader/core/domain"
 )
@@ -12,6 +13,43 @@ type Exchange interface {
 	CreateOrder(context.Context, CreateExchangeOrderRequest) (*domain.ExchangeOrder, error)
 	ModifyOrder(context.Context, ModifyExchangeOrderRequest) (*domain.ExchangeOrder, error)
 	CancelOrder(context.Context, CancelExchangeOrdersRequest) (*domain.ExchangeOrder, error)
+	MarkPrice(context.Context, MarkPriceRequest) (float64, error)
+}
+
+// ErrUnsupported is returned when the exchange doesn't support an optional capability
+var ErrUnsupported = errors.New("unsupported by exchange")
+
+// PositionConfigurer is an optional Exchange capability of setting the leverage and margin type of a pair
+type PositionConfigurer interface {
+	ConfigurePosition(context.Context, ConfigurePositionRequest) error
+}
+
+// BatchCreator is an optional Exchange capability of creating multiple orders with fewer requests
+type BatchCreator interface {
+	CreateOrders(context.Context, []CreateExchangeOrderRequest) []BatchResult
+}
+
+// BatchModifier is an optional Exchange capability of modifying multiple orders with fewer requests
+type BatchModifier interface {
+	ModifyOrders(context.Context, []ModifyExchangeOrderRequest) []BatchResult
+}
+
+// BatchCanceler is an optional Exchange capability of canceling multiple orders with fewer requests
+type BatchCanceler interface {
+	CancelOrders(context.Context, []CancelExchangeOrdersRequest) []BatchResult
+}
+
+// BatchResult is the outcome of a single order of a batch, results are returned in the order of the requests.
+// Orders of a batch fail independently, a failed request fails all of its orders.
+type BatchResult struct {
+	EO  *domain.ExchangeOrder
+	Err error
+}
+
+type ConfigurePositionRequest struct {
+	Pair       domain.Pair
+	Leverage   int               // Leverage is left unchanged if it's zero
+	MarginType domain.MarginType // MarginType is left unchanged if it's empty
 }
 
 type GetExchangeOrderRequest struct {
@@ -19,25 +57,41 @@ type GetExchangeOrderRequest struct {
 }
 
 type CreateExchangeOrderRequest struct {
-	Pair         domain.Pair
-	Type         domain.OrderType
-	Side         domain.OrderSide
-	BaseQuantity float64
-	Price        float64
-	StopPrice    float64
+	Pair           domain.Pair
+	Type           domain.OrderType
+	Side           domain.OrderSide
+	PositionSide   domain.PositionSide
+	BaseQuantity   float64
+	Price          float64
+	StopPrice      float64
+	StopLimitPrice float64 // StopLimitPrice is the limit price of the stop leg of OCO orders
+	ReduceOnly     bool
+	ClosePosition  bool // ClosePosition closes the whole position when triggered, BaseQuantity is ignored
+	TimeInForce    domain.TimeInForce
+	WorkingType    domain.WorkingType
+	// ClientOrderID identifies the placement, exchanges that support it look the order up by it
+	// instead of placing it again when a placement fails without knowing whether the order was accepted
+	ClientOrderID string
 }
 
 type ModifyExchangeOrderRequest struct {
-	EO           *domain.ExchangeOrder // EO contains data that lets the exchange identify the order
-	BaseQuantity float64
-	Price        float64
-	StopPrice    float64
+	EO             *domain.ExchangeOrder // EO contains data that lets the exchange identify the order
+	BaseQuantity   float64
+	Price          float64
+	StopPrice      float64
+	StopLimitPrice float64
+	// ClientOrderID of the new order if the exchange has to recreate the order to modify it
+	ClientOrderID string
 }
 
 type CancelExchangeOrdersRequest struct {
 	EO *domain.ExchangeOrder
 }
 
+type MarkPriceRequest struct {
+	Pair domain.Pair
+}
+
 type FileLoader[T any] interface {
 	Exists(name string) bool
 	Save(name string, data T) error
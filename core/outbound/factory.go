@@ -0,0 +1,68 @@
+package outbound
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrUnknownExchange is returned by ExchangeFactory when no exchange is registered under the name
+var ErrUnknownExchange = errors.New("unknown exchange")
+
+// ExchangeFactory creates exchanges from the name and json user config sent with follow requests
+type ExchangeFactory interface {
+	NewExchange(name string, config []byte) (Exchange, error)
+}
+
+// ExchangeConstructor creates an exchange from its json user config, it's responsible for validating the config
+type ExchangeConstructor func(config []byte) (Exchange, error)
+
+// ExchangeRegistry is an ExchangeFactory of constructors registered by name
+type ExchangeRegistry struct {
+	mu           sync.RWMutex
+	constructors map[string]ExchangeConstructor
+}
+
+func NewExchangeRegistry() *ExchangeRegistry {
+	return &ExchangeRegistry{
+		constructors: map[string]ExchangeConstructor{},
+	}
+}
+
+// Register adds the constructor under name, it replaces the constructor that was registered under it before
+func (r *ExchangeRegistry) Register(name string, constructor ExchangeConstructor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.constructors[name] = constructor
+}
+
+// Names returns the sorted names of the registered exchanges
+func (r *ExchangeRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.constructors))
+	for name := range r.constructors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *ExchangeRegistry) NewExchange(name string, config []byte) (Exchange, error) {
+	r.mu.RLock()
+	constructor, ok := r.constructors[name]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownExchange, name)
+	}
+
+	exchange, err := constructor(config)
+	if err != nil {
+		return nil, fmt.Errorf("%s config: %w", name, err)
+	}
+
+	return exchange, nil
+}
@@ -0,0 +1,36 @@
+package outbound_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/H3Cki/Plotrader/core/outbound"
+	"github.com/stretchr/testify/assert"
+)
+
+type exchange struct {
+	outbound.Exchange
+	config string
+}
+
+func TestExchangeRegistry(t *testing.T) {
+	r := outbound.NewExchangeRegistry()
+	r.Register("B", func(config []byte) (outbound.Exchange, error) {
+		return nil, errors.New("invalid config")
+	})
+	r.Register("A", func(config []byte) (outbound.Exchange, error) {
+		return &exchange{config: string(config)}, nil
+	})
+
+	assert.Equal(t, []string{"A", "B"}, r.Names())
+
+	ex, err := r.NewExchange("A", []byte(`{}`))
+	assert.NoError(t, err)
+	assert.Equal(t, &exchange{config: `{}`}, ex)
+
+	_, err = r.NewExchange("B", nil)
+	assert.EqualError(t, err, "B config: invalid config")
+
+	_, err = r.NewExchange("C", nil)
+	assert.ErrorIs(t, err, outbound.ErrUnknownExchange)
+}
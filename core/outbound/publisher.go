@@ -12,4 +12,14 @@ type Publisher interface {
 
 type FollowUpdate struct {
 	domain.Follow
+	// Transition is the status change that caused the update, nil for periodic updates
+	Transition *domain.FollowTransition `json:"transition,omitempty"`
+	// Rejections are the orders left as they are because price protection rejected their prices
+	Rejections []OrderRejection `json:"rejections,omitempty"`
+}
+
+type OrderRejection struct {
+	OrderID string `json:"orderID"`
+	Name    string `json:"name"`
+	Reason  string `json:"reason"`
 }
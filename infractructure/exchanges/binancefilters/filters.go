@@ -0,0 +1,117 @@
+// Package binancefilters implements the symbol filters shared by the binance spot and futures markets,
+// the filter values are passed as strings in the format returned by the exchange info endpoints.
+package binancefilters
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/H3Cki/Plotrader/infractructure/exchanges/decimal"
+)
+
+// noise is the part of a step below which quantities are snapped to the nearest step before rounding down,
+// quantities computed from float prices like 2.9999999999999996 are meant to be 3.
+var noise = decimal.New(1, 9)
+
+// Price returns a price rounded to the nearest multiple of tickSize with the precision of tickSize,
+// returns 0 if the price is lower than minPrice or higher than maxPrice.
+func Price(tickSize, minPrice, maxPrice string, price decimal.Decimal) (decimal.Decimal, error) {
+	tick, err := decimal.Parse(tickSize)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	newPrice := price.Round(tick)
+
+	min, err := decimal.Parse(minPrice)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	// reject if price is lower than min price
+	if !min.IsZero() && newPrice.Cmp(min) < 0 {
+		return decimal.Decimal{}, nil
+	}
+
+	// reject is price is higher than max price
+	max, err := decimal.Parse(maxPrice)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	if !max.IsZero() && newPrice.Cmp(max) > 0 {
+		return decimal.Decimal{}, nil
+	}
+
+	return newPrice, nil
+}
+
+// LotSize returns a quantity rounded down to the stepSize with the precision of stepSize,
+// returns an error if the quantity is lower than minQty or higher than maxQty.
+func LotSize(stepSize, minQty, maxQty string, qty decimal.Decimal) (decimal.Decimal, error) {
+	step, err := decimal.Parse(stepSize)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	newQty := qty
+	if !step.IsZero() {
+		newQty = qty.Round(step.Mul(noise)).Truncate(step)
+	}
+
+	min, err := decimal.Parse(minQty)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	if newQty.Cmp(min) < 0 {
+		return decimal.Decimal{}, errors.New("quantity too small")
+	}
+
+	max, err := decimal.Parse(maxQty)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	if newQty.Cmp(max) > 0 {
+		return decimal.Decimal{}, errors.New("quantity too large")
+	}
+
+	return newQty, nil
+}
+
+// MinNotional returns an error if the value of the order is lower than minNotional, empty minNotional is ignored
+func MinNotional(minNotional string, price, qty decimal.Decimal) error {
+	if minNotional == "" {
+		return nil
+	}
+
+	min, err := decimal.Parse(minNotional)
+	if err != nil {
+		return err
+	}
+
+	if notional := price.Mul(qty); notional.Cmp(min) < 0 {
+		return fmt.Errorf("minNotional too small, expected > %s, got %s", min, notional)
+	}
+
+	return nil
+}
+
+// MaxNotional returns an error if the value of the order is higher than maxNotional, empty or zero maxNotional is ignored
+func MaxNotional(maxNotional string, price, qty decimal.Decimal) error {
+	if maxNotional == "" {
+		return nil
+	}
+
+	max, err := decimal.Parse(maxNotional)
+	if err != nil {
+		return err
+	}
+
+	if notional := price.Mul(qty); !max.IsZero() && notional.Cmp(max) > 0 {
+		return fmt.Errorf("maxNotional too large, expected < %s, got %s", max, notional)
+	}
+
+	return nil
+}
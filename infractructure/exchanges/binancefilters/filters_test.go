@@ -0,0 +1,201 @@
+package binancefilters_test
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/H3Cki/Plotrader/infractructure/exchanges/binancefilters"
+	"github.com/H3Cki/Plotrader/infractructure/exchanges/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrice(t *testing.T) {
+	a, b := 0.1, 0.2
+	tests := []struct {
+		name  string
+		price float64
+		want  string
+	}{
+		{"rounded", 1.1111119111, "1.111112"},
+		{"below min", 0.0000001, "0"},
+		{"above max", 11, "0"},
+		{"float noise", a + b, "0.300000"},
+		{"small", 0.00001, "0.000010"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := binancefilters.Price("0.00000100", "0.00000100", "10.00000000", decimal.FromFloat(tt.price))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestLotSize(t *testing.T) {
+	lotSize := func(stepSize, minQty, maxQty string, qty float64) (string, error) {
+		got, err := binancefilters.LotSize(stepSize, minQty, maxQty, decimal.FromFloat(qty))
+		return got.String(), err
+	}
+
+	got, err := lotSize("0.01000000", "0.01000000", "9000.00000000", 0.2199)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.21", got)
+
+	// exact multiples are kept despite float division error
+	got, err = lotSize("0.00001000", "0.00001000", "9000.00000000", 0.01)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.01000", got)
+
+	// float noise below a multiple doesn't round a step down
+	quote, price := 0.3, 0.1
+	got, err = lotSize("1.00000000", "1.00000000", "9000.00000000", quote/price)
+	assert.NoError(t, err)
+	assert.Equal(t, "3", got)
+
+	_, err = lotSize("0.01000000", "0.01000000", "9000.00000000", 0.001)
+	assert.Error(t, err)
+
+	_, err = lotSize("0.01000000", "0.01000000", "9000.00000000", 9001)
+	assert.Error(t, err)
+
+	// zero step size leaves the quantity unchanged
+	got, err = lotSize("0.00000000", "0.00000000", "1000.00000000", 0.123456789)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.123456789", got)
+}
+
+func TestNotional(t *testing.T) {
+	d := decimal.FromFloat
+
+	assert.NoError(t, binancefilters.MinNotional("", d(1), d(1)))
+	assert.NoError(t, binancefilters.MinNotional("5", d(10), d(1)))
+	assert.Error(t, binancefilters.MinNotional("5", d(1), d(1)))
+	// 0.1 * 50 is exactly 5
+	assert.NoError(t, binancefilters.MinNotional("5.00000000", d(0.1), d(50)))
+
+	assert.NoError(t, binancefilters.MaxNotional("", d(10), d(10)))
+	assert.NoError(t, binancefilters.MaxNotional("0", d(10), d(10)))
+	assert.NoError(t, binancefilters.MaxNotional("1000", d(10), d(10)))
+	assert.Error(t, binancefilters.MaxNotional("50", d(10), d(10)))
+}
+
+// stepSizes are tick and step sizes in the format of the exchange info
+var stepSizes = []string{"0.00000100", "0.00001000", "0.01000000", "0.10", "0.5", "1.00000000", "10"}
+
+// filterInput is a random value and step size
+type filterInput struct {
+	value float64
+	step  string
+}
+
+func (filterInput) Generate(r *rand.Rand, size int) reflect.Value {
+	step := stepSizes[r.Intn(len(stepSizes))]
+	// values of different magnitudes with up to 8 decimal places
+	value := math.Round(r.Float64()*math.Pow(10, float64(r.Intn(7)))*1e8) / 1e8
+	return reflect.ValueOf(filterInput{value: value, step: step})
+}
+
+// floatPrice is the float path the decimals replaced
+func floatPrice(tickSize string, price float64) float64 {
+	tick, _ := strconv.ParseFloat(tickSize, 64)
+	decimals := math.Pow(10, float64(decimalPlaces(tickSize)))
+	return math.Round(math.Round(price/tick)*tick*decimals) / decimals
+}
+
+// floatLotSize is the float path the decimals replaced
+func floatLotSize(stepSize string, qty float64) float64 {
+	step, _ := strconv.ParseFloat(stepSize, 64)
+	decimals := math.Pow(10, float64(decimalPlaces(stepSize)))
+	return math.Round(math.Floor(qty/step+1e-9)*step*decimals) / decimals
+}
+
+func decimalPlaces(s string) int {
+	s = strings.TrimRight(s, "0")
+	if i := strings.IndexByte(s, '.'); i > -1 {
+		return len(s) - i - 1
+	}
+	return 0
+}
+
+// checkFormat returns true if s is in plain notation with the decimal places of the step
+func checkFormat(s, step string) bool {
+	places := 0
+	if i := strings.IndexByte(s, '.'); i > -1 {
+		places = len(s) - i - 1
+	}
+	return !strings.ContainsAny(s, "eE") && places == decimalPlaces(step)
+}
+
+// near returns true if value is within the float error of the point at frac between two steps,
+// the float path may round such values either way
+func near(value float64, step string, frac float64) bool {
+	s, _ := strconv.ParseFloat(step, 64)
+	q := value / s
+	_, f := math.Modf(q)
+	// the error of the division grows with the number of steps
+	tolerance := 1e-6 + math.Abs(q)*1e-15
+	return math.Abs(f-frac) < tolerance || math.Abs(f-frac-1) < tolerance
+}
+
+// withinStep returns true if got and want differ by at most one step
+func withinStep(got decimal.Decimal, want float64, step decimal.Decimal) bool {
+	return got.Sub(decimal.FromFloat(want)).Abs().Cmp(step) <= 0
+}
+
+func mustParse(t *testing.T, s string) decimal.Decimal {
+	d, err := decimal.Parse(s)
+	assert.NoError(t, err)
+	return d
+}
+
+func TestPrice_MatchesFloat(t *testing.T) {
+	f := func(in filterInput) bool {
+		got, err := binancefilters.Price(in.step, "0", "0", decimal.FromFloat(in.value))
+		if err != nil || !checkFormat(got.String(), in.step) {
+			return false
+		}
+
+		// the price is a multiple of the tick at most half a tick away
+		value, tick := decimal.FromFloat(in.value), mustParse(t, in.step)
+		if !got.Round(tick).Equal(got) || value.Sub(got).Abs().Mul(decimal.New(2, 0)).Cmp(tick) > 0 {
+			return false
+		}
+
+		want := floatPrice(in.step, in.value)
+		if near(in.value, in.step, 0.5) {
+			return withinStep(got, want, tick)
+		}
+		return got.Float64() == want
+	}
+
+	assert.NoError(t, quick.Check(f, &quick.Config{MaxCount: 10000}))
+}
+
+func TestLotSize_MatchesFloat(t *testing.T) {
+	f := func(in filterInput) bool {
+		got, err := binancefilters.LotSize(in.step, "0", "100000000", decimal.FromFloat(in.value))
+		if err != nil || !checkFormat(got.String(), in.step) {
+			return false
+		}
+
+		// the quantity is the multiple of the step at or below the value
+		value, step := decimal.FromFloat(in.value), mustParse(t, in.step)
+		if !got.Truncate(step).Equal(got) || got.Cmp(value) > 0 || value.Sub(got).Cmp(step) >= 0 {
+			return false
+		}
+
+		want := floatLotSize(in.step, in.value)
+		if near(in.value, in.step, 0) {
+			return withinStep(got, want, step)
+		}
+		return got.Float64() == want
+	}
+
+	assert.NoError(t, quick.Check(f, &quick.Config{MaxCount: 10000}))
+}
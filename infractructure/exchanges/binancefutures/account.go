@@ -0,0 +1,96 @@
+package binancefutures
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/Plotrader/core/outbound"
+	"github.com/H3Cki/go-binance/v2/futures"
+)
+
+// Positions implements outbound.AccountReader, binance returns the positions of both sides in hedge mode
+// even if they're empty so they're filtered by their amount.
+func (e *Exchange) Positions(ctx context.Context, req outbound.PositionsRequest) ([]domain.Position, error) {
+	risks, err := e.client.NewGetPositionRiskService().Symbol(pairToSymbol(req.Pair)).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	positions := []domain.Position{}
+	for _, risk := range risks {
+		amount, err := strconv.ParseFloat(risk.PositionAmt, 64)
+		if err != nil {
+			return nil, err
+		}
+		if amount == 0 {
+			continue
+		}
+
+		entryPrice, err := parseOptionalPrice(risk.EntryPrice)
+		if err != nil {
+			return nil, err
+		}
+
+		positions = append(positions, domain.Position{
+			Symbol:       risk.Symbol,
+			PositionSide: toDomainPositionSide(futures.PositionSideType(risk.PositionSide)),
+			Amount:       amount,
+			EntryPrice:   entryPrice,
+		})
+	}
+
+	return positions, nil
+}
+
+// Balances implements outbound.AccountReader
+func (e *Exchange) Balances(ctx context.Context, req outbound.BalancesRequest) ([]domain.Balance, error) {
+	resp, err := e.client.NewGetBalanceService().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := []domain.Balance{}
+	for _, b := range resp {
+		if req.Asset != "" && b.Asset != req.Asset {
+			continue
+		}
+
+		wallet, err := strconv.ParseFloat(b.Balance, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		available, err := strconv.ParseFloat(b.AvailableBalance, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		balances = append(balances, domain.Balance{
+			Asset:     b.Asset,
+			Wallet:    wallet,
+			Available: available,
+		})
+	}
+
+	return balances, nil
+}
+
+// OpenOrders implements outbound.AccountReader
+func (e *Exchange) OpenOrders(ctx context.Context, req outbound.OpenOrdersRequest) ([]domain.ExchangeOrder, error) {
+	resp, err := e.client.NewListOpenOrdersService().Symbol(pairToSymbol(req.Pair)).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	orders := []domain.ExchangeOrder{}
+	for _, order := range resp {
+		eo, err := orderToOrder(order)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, *eo)
+	}
+
+	return orders, nil
+}
@@ -0,0 +1,59 @@
+package binancefutures
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/Plotrader/core/outbound"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExchange_AccountReader(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fapi/v2/positionRisk":
+			assert.Equal(t, "BTCUSDT", r.URL.Query().Get("symbol"))
+			json.NewEncoder(w).Encode([]map[string]any{
+				{"symbol": "BTCUSDT", "positionSide": "LONG", "positionAmt": "0.500", "entryPrice": "60000.0"},
+				// hedge mode returns the empty side too
+				{"symbol": "BTCUSDT", "positionSide": "SHORT", "positionAmt": "0.000", "entryPrice": "0.0"},
+			})
+		case "/fapi/v2/balance":
+			json.NewEncoder(w).Encode([]map[string]any{
+				{"asset": "USDT", "balance": "1000.5", "availableBalance": "800.25"},
+				{"asset": "BNB", "balance": "1", "availableBalance": "1"},
+			})
+		case "/fapi/v1/openOrders":
+			json.NewEncoder(w).Encode([]map[string]any{orderJSON(1, "BTCUSDT", "LIMIT", "55000", "0.1")})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	e := newBatchExchange(srv.URL)
+	ctx := context.Background()
+	pair := domain.Pair{Base: "BTC", Quote: "USDT"}
+
+	positions, err := e.Positions(ctx, outbound.PositionsRequest{Pair: pair})
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Position{{Symbol: "BTCUSDT", PositionSide: domain.PositionSideLong, Amount: 0.5, EntryPrice: 60000}}, positions)
+
+	balances, err := e.Balances(ctx, outbound.BalancesRequest{Asset: "USDT"})
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Balance{{Asset: "USDT", Wallet: 1000.5, Available: 800.25}}, balances)
+
+	balances, err = e.Balances(ctx, outbound.BalancesRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, balances, 2)
+
+	orders, err := e.OpenOrders(ctx, outbound.OpenOrdersRequest{Pair: pair})
+	assert.NoError(t, err)
+	assert.Len(t, orders, 1)
+	assert.Equal(t, int64(1), orders[0].ID)
+	assert.Equal(t, 55000.0, orders[0].Price)
+}
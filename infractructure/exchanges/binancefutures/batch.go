@@ -0,0 +1,276 @@
+package binancefutures
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/Plotrader/core/outbound"
+	"github.com/H3Cki/go-binance/v2/common"
+	"github.com/H3Cki/go-binance/v2/futures"
+)
+
+const (
+	// maxBatchOrders is the most orders binance creates or modifies in a single request
+	maxBatchOrders = 5
+	// maxBatchCancels is the most orders of a symbol binance cancels in a single request
+	maxBatchCancels = 10
+)
+
+// CreateOrders implements outbound.BatchCreator, orders are placed in batches of maxBatchOrders
+func (e *Exchange) CreateOrders(ctx context.Context, reqs []outbound.CreateExchangeOrderRequest) []outbound.BatchResult {
+	results := make([]outbound.BatchResult, len(reqs))
+	indexes := []int{}
+	orders := []map[string]string{}
+
+	for i, req := range reqs {
+		ov, err := e.createValues(ctx, req)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		if err := applyFilters(&ov); err != nil {
+			results[i].Err = fmt.Errorf("filter error: %w", err)
+			continue
+		}
+
+		params, err := createParams(ov)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		indexes = append(indexes, i)
+		orders = append(orders, params)
+	}
+
+	e.sendBatches(ctx, http.MethodPost, indexes, orders, results)
+
+	for j, i := range indexes {
+		if results[i].Err != nil {
+			results[i].EO, results[i].Err = e.placedOrder(ctx, orders[j]["symbol"], orders[j]["newClientOrderId"], results[i].Err)
+		}
+	}
+
+	return results
+}
+
+// ModifyOrders implements outbound.BatchModifier, limit orders are modified in batches of maxBatchOrders
+// and the other types are recreated one by one as binance can only modify limit orders.
+func (e *Exchange) ModifyOrders(ctx context.Context, reqs []outbound.ModifyExchangeOrderRequest) []outbound.BatchResult {
+	results := make([]outbound.BatchResult, len(reqs))
+	indexes := []int{}
+	orders := []map[string]string{}
+
+	for i, req := range reqs {
+		eo := req.EO
+
+		ov, err := e.modifyValues(ctx, req)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		if unchanged(eo, ov) {
+			results[i].EO = eo
+			continue
+		}
+
+		if ov.orderType != futures.OrderTypeLimit {
+			results[i].EO, results[i].Err = e.recreateOrder(ctx, eo, ov)
+			continue
+		}
+
+		id, err := orderID(eo.ID)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		indexes = append(indexes, i)
+		orders = append(orders, map[string]string{
+			"orderId":  strconv.FormatInt(id, 10),
+			"symbol":   ov.symbol.Symbol,
+			"side":     string(ov.side),
+			"quantity": ov.baseQuantity.String(),
+			"price":    ov.price.String(),
+		})
+	}
+
+	e.sendBatches(ctx, http.MethodPut, indexes, orders, results)
+
+	// "no need to modify the order" is not an error
+	for _, i := range indexes {
+		apiErr, ok := results[i].Err.(*common.APIError)
+		if ok && apiErr.Code == -5027 {
+			results[i] = outbound.BatchResult{EO: reqs[i].EO}
+		}
+	}
+
+	return results
+}
+
+// CancelOrders implements outbound.BatchCanceler, orders are canceled per symbol in batches of maxBatchCancels
+func (e *Exchange) CancelOrders(ctx context.Context, reqs []outbound.CancelExchangeOrdersRequest) []outbound.BatchResult {
+	results := make([]outbound.BatchResult, len(reqs))
+
+	symbols := []string{}
+	indexes := map[string][]int{}
+	ids := map[int]int64{}
+	for i, req := range reqs {
+		id, err := orderID(req.EO.ID)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		ids[i] = id
+
+		symbol := req.EO.Symbol
+		if _, ok := indexes[symbol]; !ok {
+			symbols = append(symbols, symbol)
+		}
+		indexes[symbol] = append(indexes[symbol], i)
+	}
+
+	for _, symbol := range symbols {
+		symbolIndexes := indexes[symbol]
+		for start := 0; start < len(symbolIndexes); start += maxBatchCancels {
+			batch := symbolIndexes[start:min(start+maxBatchCancels, len(symbolIndexes))]
+
+			batchIDs := make([]int64, len(batch))
+			for j, i := range batch {
+				batchIDs[j] = ids[i]
+			}
+
+			// the error joins the errors of the orders, the response is nil only if the request failed
+			resp, err := e.client.NewCancelMultipleOrdersService().Symbol(symbol).OrderIDList(batchIDs).Do(ctx)
+			for j, i := range batch {
+				switch {
+				case resp == nil:
+					results[i].Err = err
+				case j >= resp.N:
+					results[i].Err = errors.New("missing batch order response")
+				case resp.Errors[j] != nil:
+					results[i].Err = resp.Errors[j]
+				default:
+					results[i].EO, results[i].Err = cancelRespToOrder(resp.Orders[j])
+				}
+			}
+		}
+	}
+
+	return results
+}
+
+// createParams returns the batch parameters of the order, they match the ones set by createOrderService and the type specific setters
+func createParams(ov orderValues) (map[string]string, error) {
+	params := map[string]string{
+		"symbol": ov.symbol.Symbol,
+		"side":   string(ov.side),
+		"type":   string(ov.orderType),
+	}
+
+	if ov.workingType != "" {
+		params["workingType"] = string(ov.workingType)
+	}
+
+	if ov.clientOrderID != "" {
+		params["newClientOrderId"] = ov.clientOrderID
+	}
+
+	if ov.positionSide != "" && ov.positionSide != futures.PositionSideTypeBoth {
+		params["positionSide"] = string(ov.positionSide)
+		// reduce only is implied by the position side in hedge mode and binance rejects it
+		ov.reduceOnly = false
+	}
+
+	if ov.closePosition {
+		params["closePosition"] = "true"
+	} else {
+		params["quantity"] = ov.baseQuantity.String()
+		if ov.reduceOnly {
+			params["reduceOnly"] = "true"
+		}
+	}
+
+	switch ov.orderType {
+	case futures.OrderTypeLimit:
+		params["timeInForce"] = string(ov.timeInForce)
+		params["price"] = ov.price.String()
+	case futures.OrderTypeStopMarket, futures.OrderTypeTakeProfitMarket:
+		params["stopPrice"] = ov.stopPrice.String()
+	case futures.OrderTypeStop, futures.OrderTypeTakeProfit:
+		params["timeInForce"] = string(ov.timeInForce)
+		params["price"] = ov.price.String()
+		params["stopPrice"] = ov.stopPrice.String()
+	default:
+		return nil, fmt.Errorf("unsupported order type %s", ov.orderType)
+	}
+
+	return params, nil
+}
+
+// sendBatches sends the orders in batches of maxBatchOrders and stores the outcome of each order in results at its index
+func (e *Exchange) sendBatches(ctx context.Context, method string, indexes []int, orders []map[string]string, results []outbound.BatchResult) {
+	for start := 0; start < len(orders); start += maxBatchOrders {
+		end := min(start+maxBatchOrders, len(orders))
+
+		items, err := e.batchOrders(ctx, method, orders[start:end])
+		for j, i := range indexes[start:end] {
+			switch {
+			case err != nil:
+				results[i].Err = err
+			case j >= len(items):
+				results[i].Err = errors.New("missing batch order response")
+			default:
+				results[i].EO, results[i].Err = batchItemToOrder(items[j])
+			}
+		}
+	}
+}
+
+// batchOrders sends the orders to the batchOrders endpoint and returns the items of the response.
+// The library's batch services can't be used, the create service sends empty quantities of close position orders
+// and the modify service doesn't encode the modifications.
+func (e *Exchange) batchOrders(ctx context.Context, method string, orders []map[string]string) ([]json.RawMessage, error) {
+	batch, err := json.Marshal(orders)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := e.signedRequest(ctx, method, "/fapi/v1/batchOrders", url.Values{"batchOrders": {string(batch)}})
+	if err != nil {
+		return nil, err
+	}
+
+	items := []json.RawMessage{}
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// batchItemToOrder converts an item of a batch response, it's either an order or the error of the order
+func batchItemToOrder(item json.RawMessage) (*domain.ExchangeOrder, error) {
+	apiErr := &common.APIError{}
+	if err := json.Unmarshal(item, apiErr); err != nil {
+		return nil, err
+	}
+	if apiErr.Code != 0 || apiErr.Message != "" {
+		return nil, apiErr
+	}
+
+	order := &futures.Order{}
+	if err := json.Unmarshal(item, order); err != nil {
+		return nil, err
+	}
+
+	return orderToOrder(order)
+}
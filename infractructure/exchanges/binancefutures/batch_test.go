@@ -0,0 +1,294 @@
+package binancefutures
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/Plotrader/core/outbound"
+	"github.com/H3Cki/go-binance/v2/common"
+	"github.com/H3Cki/go-binance/v2/futures"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// batchServer records the batch requests and answers them with respond, requests with invalid signatures are rejected
+type batchServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []url.Values
+	methods  []string
+}
+
+func newBatchServer(t *testing.T, respond func(method string, form url.Values) any) *batchServer {
+	s := &batchServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		query := r.URL.Query()
+		signature := query.Get("signature")
+		query.Del("signature")
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write([]byte(query.Encode() + string(body)))
+		if r.URL.Path != "/fapi/v1/batchOrders" || r.Header.Get("X-MBX-APIKEY") != "key" || signature != hex.EncodeToString(mac.Sum(nil)) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":-1022,"msg":"Signature for this request is not valid."}`))
+			return
+		}
+
+		form, err := url.ParseQuery(string(body))
+		assert.NoError(t, err)
+
+		s.mu.Lock()
+		s.requests = append(s.requests, form)
+		s.methods = append(s.methods, r.Method)
+		s.mu.Unlock()
+
+		json.NewEncoder(w).Encode(respond(r.Method, form))
+	}))
+	return s
+}
+
+func newBatchExchange(url string) *Exchange {
+	client := futures.NewClient("key", "secret")
+	client.BaseURL = url
+
+	cache := newInfoCache(zap.NewNop().Sugar(), client, &memoryLoader{}, eiFileName)
+	cache.Stop()
+	cache.Set(ExchangeInfo{Symbols: []futures.Symbol{{Symbol: "BTCUSDT"}, {Symbol: "ETHUSDT"}}}, time.Now())
+
+	return &Exchange{logger: zap.NewNop().Sugar(), client: client, cache: cache}
+}
+
+// batchOrders decodes the orders of a batch request
+func batchOrders(t *testing.T, form url.Values) []map[string]string {
+	orders := []map[string]string{}
+	assert.NoError(t, json.Unmarshal([]byte(form.Get("batchOrders")), &orders))
+	return orders
+}
+
+func orderJSON(id int, symbol, orderType, price, quantity string) map[string]any {
+	return map[string]any{
+		"orderId":  id,
+		"symbol":   symbol,
+		"status":   "NEW",
+		"type":     orderType,
+		"side":     "BUY",
+		"price":    price,
+		"origQty":  quantity,
+		"avgPrice": "0",
+	}
+}
+
+func TestExchange_CreateOrders(t *testing.T) {
+	var srv *batchServer
+	srv = newBatchServer(t, func(method string, form url.Values) any {
+		items := []any{}
+		for _, o := range batchOrders(t, form) {
+			if o["price"] == "66" {
+				items = append(items, map[string]any{"code": -2019, "msg": "Margin is insufficient."})
+				continue
+			}
+			items = append(items, orderJSON(len(srv.requests)*10+len(items), o["symbol"], o["type"], o["price"], o["quantity"]))
+		}
+		return items
+	})
+	defer srv.Close()
+
+	e := newBatchExchange(srv.URL)
+
+	reqs := []outbound.CreateExchangeOrderRequest{}
+	for i := 0; i < 7; i++ {
+		reqs = append(reqs, outbound.CreateExchangeOrderRequest{
+			Pair:         domain.Pair{Base: "BTC", Quote: "USDT"},
+			Type:         domain.OrderTypeLimit,
+			Side:         domain.OrderSideBuy,
+			BaseQuantity: 1,
+			Price:        float64(60 + i),
+			TimeInForce:  domain.TimeInForceGTC,
+		})
+	}
+	// invalid orders fail without being sent
+	reqs[2].Side = "SIDEWAYS"
+
+	results := e.CreateOrders(context.Background(), reqs)
+	assert.Len(t, results, 7)
+
+	// 6 valid orders are sent in batches of 5
+	assert.Equal(t, []string{http.MethodPost, http.MethodPost}, srv.methods)
+	first := batchOrders(t, srv.requests[0])
+	assert.Len(t, first, 5)
+	assert.Equal(t, map[string]string{
+		"symbol":      "BTCUSDT",
+		"side":        "BUY",
+		"type":        "LIMIT",
+		"quantity":    "1",
+		"price":       "60",
+		"timeInForce": "GTC",
+	}, first[0])
+	assert.Len(t, batchOrders(t, srv.requests[1]), 1)
+
+	for i, res := range results {
+		switch i {
+		case 2:
+			assert.Error(t, res.Err)
+		case 6:
+			apiErr, ok := res.Err.(*common.APIError)
+			assert.True(t, ok)
+			assert.Equal(t, int64(-2019), apiErr.Code)
+		default:
+			assert.NoError(t, res.Err)
+			assert.Equal(t, float64(60+i), res.EO.Price)
+		}
+	}
+}
+
+func TestExchange_CreateOrders_RequestError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":-1102,"msg":"Mandatory parameter 'batchOrders' was not sent."}`))
+	}))
+	defer srv.Close()
+
+	e := newBatchExchange(srv.URL)
+	req := outbound.CreateExchangeOrderRequest{
+		Pair:         domain.Pair{Base: "BTC", Quote: "USDT"},
+		Type:         domain.OrderTypeLimit,
+		Side:         domain.OrderSideBuy,
+		BaseQuantity: 1,
+		Price:        60,
+	}
+
+	// a failed request fails all of its orders
+	for _, res := range e.CreateOrders(context.Background(), []outbound.CreateExchangeOrderRequest{req, req}) {
+		apiErr, ok := res.Err.(*common.APIError)
+		assert.True(t, ok)
+		assert.Equal(t, int64(-1102), apiErr.Code)
+	}
+}
+
+func TestExchange_ModifyOrders(t *testing.T) {
+	srv := newBatchServer(t, func(method string, form url.Values) any {
+		items := []any{}
+		for _, o := range batchOrders(t, form) {
+			if o["orderId"] == "3" {
+				items = append(items, map[string]any{"code": -5027, "msg": "No need to modify the order."})
+				continue
+			}
+			id := 0
+			fmt.Sscan(o["orderId"], &id)
+			items = append(items, orderJSON(id, o["symbol"], "LIMIT", o["price"], o["quantity"]))
+		}
+		return items
+	})
+	defer srv.Close()
+
+	e := newBatchExchange(srv.URL)
+
+	eo := func(id int64, price float64) *domain.ExchangeOrder {
+		return &domain.ExchangeOrder{ID: id, Symbol: "BTCUSDT", Type: "LIMIT", Side: "BUY", Price: price, BaseQuantity: 1}
+	}
+	unchangedEO := eo(2, 50)
+	noNeedEO := eo(3, 50)
+
+	results := e.ModifyOrders(context.Background(), []outbound.ModifyExchangeOrderRequest{
+		{EO: eo(1, 50), Price: 55, BaseQuantity: 1},
+		{EO: unchangedEO, Price: 50, BaseQuantity: 1},
+		{EO: noNeedEO, Price: 51, BaseQuantity: 1},
+	})
+
+	// unchanged orders aren't sent
+	assert.Equal(t, []string{http.MethodPut}, srv.methods)
+	assert.Equal(t, []map[string]string{
+		{"orderId": "1", "symbol": "BTCUSDT", "side": "BUY", "quantity": "1", "price": "55"},
+		{"orderId": "3", "symbol": "BTCUSDT", "side": "BUY", "quantity": "1", "price": "51"},
+	}, batchOrders(t, srv.requests[0]))
+
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, float64(55), results[0].EO.Price)
+	assert.NoError(t, results[1].Err)
+	assert.Same(t, unchangedEO, results[1].EO)
+	assert.NoError(t, results[2].Err)
+	assert.Same(t, noNeedEO, results[2].EO)
+}
+
+func TestExchange_CancelOrders(t *testing.T) {
+	srv := newBatchServer(t, func(method string, form url.Values) any {
+		items := []any{}
+		for _, id := range strings.Split(strings.Trim(form.Get("orderIdList"), "[]"), ",") {
+			if id == "4" {
+				items = append(items, map[string]any{"code": -2011, "msg": "Unknown order sent."})
+				continue
+			}
+			n := 0
+			fmt.Sscan(id, &n)
+			o := orderJSON(n, form.Get("symbol"), "LIMIT", "50", "1")
+			o["status"] = "CANCELED"
+			items = append(items, o)
+		}
+		return items
+	})
+	defer srv.Close()
+
+	e := newBatchExchange(srv.URL)
+
+	reqs := []outbound.CancelExchangeOrdersRequest{}
+	for i, symbol := range []string{"BTCUSDT", "ETHUSDT", "BTCUSDT", "ETHUSDT"} {
+		reqs = append(reqs, outbound.CancelExchangeOrdersRequest{
+			EO: &domain.ExchangeOrder{ID: int64(i + 1), Symbol: symbol},
+		})
+	}
+
+	results := e.CancelOrders(context.Background(), reqs)
+
+	// orders are canceled per symbol
+	assert.Equal(t, []string{http.MethodDelete, http.MethodDelete}, srv.methods)
+	assert.Equal(t, "BTCUSDT", srv.requests[0].Get("symbol"))
+	assert.Equal(t, "[1,3]", srv.requests[0].Get("orderIdList"))
+	assert.Equal(t, "ETHUSDT", srv.requests[1].Get("symbol"))
+	assert.Equal(t, "[2,4]", srv.requests[1].Get("orderIdList"))
+
+	for i, res := range results {
+		if i == 3 {
+			assert.Error(t, res.Err)
+			continue
+		}
+		assert.NoError(t, res.Err)
+		assert.Equal(t, int64(i+1), res.EO.ID)
+		assert.Equal(t, domain.OrderStatusCanceled, res.EO.Status)
+	}
+}
+
+func TestExchange_CancelOrders_OrderID(t *testing.T) {
+	srv := newBatchServer(t, func(method string, form url.Values) any {
+		o := orderJSON(1, form.Get("symbol"), "LIMIT", "50", "1")
+		o["status"] = "CANCELED"
+		return []any{o}
+	})
+	defer srv.Close()
+
+	e := newBatchExchange(srv.URL)
+
+	// IDs of orders read back from the repo are float64
+	results := e.CancelOrders(context.Background(), []outbound.CancelExchangeOrdersRequest{
+		{EO: &domain.ExchangeOrder{ID: float64(1), Symbol: "BTCUSDT"}},
+		{EO: &domain.ExchangeOrder{ID: "1", Symbol: "BTCUSDT"}},
+	})
+
+	// orders with invalid IDs fail without being sent
+	assert.Equal(t, "[1]", srv.requests[0].Get("orderIdList"))
+	assert.NoError(t, results[0].Err)
+	assert.Error(t, results[1].Err)
+}
@@ -2,12 +2,16 @@ package binancefutures
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"os"
+	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/H3Cki/Plotrader/core/domain"
 	"github.com/H3Cki/Plotrader/core/outbound"
+	"github.com/H3Cki/Plotrader/infractructure/exchanges/decimal"
 	"github.com/H3Cki/go-binance/v2/common"
 	"github.com/H3Cki/go-binance/v2/futures"
 	"go.uber.org/zap"
@@ -18,12 +22,11 @@ var (
 	maxEiAge   = 24 * time.Hour
 )
 
-func eiFn() string {
-	if futures.UseTestnet {
-		return "testnet_" + eiFileName
-	}
-	return eiFileName
-}
+// baseURL and testnetBaseURL are set per client, the library's UseTestnet switches every client in the process
+const (
+	baseURL        = "https://fapi.binance.com"
+	testnetBaseURL = "https://testnet.binancefuture.com"
+)
 
 type UserConfig struct {
 	Testnet    bool   `json:"testnet"`
@@ -34,32 +37,67 @@ type UserConfig struct {
 type Config struct {
 	ExchangeInfoer outbound.FileLoader[ExchangeInfo]
 	UserConfig     UserConfig
+	// ClientPool shares the clients and rate limits of the API key with other exchanges, a process wide pool if nil
+	ClientPool *ClientPool
 }
 
 type Exchange struct {
 	logger *zap.SugaredLogger
 	client *futures.Client
-	ei     ExchangeInfo
-	eier   outbound.FileLoader[ExchangeInfo]
+	// cache is the exchange info shared with the other exchanges of the endpoint
+	cache *infoCache
+	// hedgeMode is true if the account uses dual side position mode, orders have to specify the position side
+	hedgeMode bool
+	testnet   bool
+	// wsURL is the base url of the user data stream
+	wsURL string
 }
 
 type ExchangeInfo futures.ExchangeInfo
 
 func New(logger *zap.SugaredLogger, cfg Config) *Exchange {
-	futures.UseTestnet = cfg.UserConfig.Testnet
-	return &Exchange{
-		logger: logger,
-		client: futures.NewClient(cfg.UserConfig.API_KEY, cfg.UserConfig.SECRET_KEY),
-		eier:   cfg.ExchangeInfoer,
+	pool := cfg.ClientPool
+	if pool == nil {
+		pool = defaultClientPool
+	}
+
+	e := &Exchange{
+		logger:  logger,
+		testnet: cfg.UserConfig.Testnet,
+		wsURL:   wsURL,
+	}
+
+	apiURL := baseURL
+	if e.testnet {
+		apiURL = testnetBaseURL
+		e.wsURL = wsTestnetURL
+	}
+
+	e.client = pool.client(cfg.UserConfig.API_KEY, cfg.UserConfig.SECRET_KEY, apiURL)
+	e.cache = pool.infoCache(logger, e.client, apiURL, cfg.ExchangeInfoer, e.eiFn())
+	return e
+}
+
+// eiFn returns the name of the exchange info file, testnet symbols are cached separately
+func (f *Exchange) eiFn() string {
+	if f.testnet {
+		return "testnet_" + eiFileName
 	}
+	return eiFileName
 }
 
 func (f *Exchange) Init(ctx context.Context) error {
 	if err := f.client.NewPingService().Do(ctx); err != nil {
 		return err
 	}
-	_, err := f.info(ctx, false)
-	return err
+
+	mode, err := f.client.NewGetPositionModeService().Do(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting position mode: %w", err)
+	}
+	f.hedgeMode = mode.DualSidePosition
+
+	return f.cache.Load(ctx)
 }
 
 func (f *Exchange) GetOrder(ctx context.Context, req outbound.GetExchangeOrderRequest) (*domain.ExchangeOrder, error) {
@@ -73,33 +111,46 @@ func (f *Exchange) GetOrder(ctx context.Context, req outbound.GetExchangeOrderRe
 
 // Order
 func (e *Exchange) CreateOrder(ctx context.Context, req outbound.CreateExchangeOrderRequest) (*domain.ExchangeOrder, error) {
-	symbol, err := e.symbol(ctx, pairToSymbol(req.Pair))
+	ov, err := e.createValues(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	side, err := orderSide(req.Side)
+	resp, err := e.createOrder(ctx, ov)
+	return resp, err
+}
+
+// createValues converts the request to order values, filters are not applied yet
+func (e *Exchange) createValues(ctx context.Context, req outbound.CreateExchangeOrderRequest) (orderValues, error) {
+	symbol, err := e.symbol(ctx, pairToSymbol(req.Pair))
 	if err != nil {
-		return nil, err
+		return orderValues{}, err
 	}
 
-	orderType, err := orderType(req.Type)
+	side, err := orderSide(req.Side)
 	if err != nil {
-		return nil, err
+		return orderValues{}, err
 	}
 
-	ov := orderValues{
-		symbol:       symbol,
-		side:         side,
-		orderType:    orderType,
-		price:        req.Price,
-		stopPrice:    req.StopPrice,
-		baseQuantity: req.BaseQuantity,
-		timeInForce:  futures.TimeInForceTypeGTC,
+	orderType, err := orderType(req.Type)
+	if err != nil {
+		return orderValues{}, err
 	}
 
-	resp, err := e.createOrder(ctx, ov)
-	return resp, err
+	return orderValues{
+		symbol:        symbol,
+		side:          side,
+		orderType:     orderType,
+		price:         decimal.FromFloat(req.Price),
+		stopPrice:     decimal.FromFloat(req.StopPrice),
+		positionSide:  e.positionSide(req.PositionSide),
+		baseQuantity:  decimal.FromFloat(req.BaseQuantity),
+		timeInForce:   timeInForce(req.TimeInForce),
+		workingType:   futures.WorkingType(req.WorkingType),
+		reduceOnly:    req.ReduceOnly,
+		closePosition: req.ClosePosition,
+		clientOrderID: req.ClientOrderID,
+	}, nil
 }
 
 func (f *Exchange) ModifyOrder(ctx context.Context, req outbound.ModifyExchangeOrderRequest) (*domain.ExchangeOrder, error) {
@@ -111,11 +162,63 @@ func (f *Exchange) CancelOrder(ctx context.Context, req outbound.CancelExchangeO
 	return f.cancelOrder(ctx, req)
 }
 
+func (f *Exchange) MarkPrice(ctx context.Context, req outbound.MarkPriceRequest) (float64, error) {
+	indexes, err := f.client.NewPremiumIndexService().Symbol(pairToSymbol(req.Pair)).Do(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(indexes) == 0 {
+		return 0, fmt.Errorf("no mark price for symbol %s", pairToSymbol(req.Pair))
+	}
+	return strconv.ParseFloat(indexes[0].MarkPrice, 64)
+}
+
+// ConfigurePosition implements outbound.PositionConfigurer
+func (e *Exchange) ConfigurePosition(ctx context.Context, req outbound.ConfigurePositionRequest) error {
+	symbol := pairToSymbol(req.Pair)
+
+	if req.MarginType != "" {
+		err := e.client.NewChangeMarginTypeService().
+			Symbol(symbol).
+			MarginType(futures.MarginType(req.MarginType)).
+			Do(ctx)
+		// "No need to change margin type"
+		apiErr, ok := err.(*common.APIError)
+		if ok && apiErr.Code == -4046 {
+			err = nil
+		}
+		if err != nil {
+			return fmt.Errorf("error changing margin type: %w", err)
+		}
+	}
+
+	if req.Leverage != 0 {
+		_, err := e.client.NewChangeLeverageService().
+			Symbol(symbol).
+			Leverage(req.Leverage).
+			Do(ctx)
+		if err != nil {
+			return fmt.Errorf("error changing leverage: %w", err)
+		}
+	}
+
+	return nil
+}
+
 func (e *Exchange) createOrder(ctx context.Context, ov orderValues) (*domain.ExchangeOrder, error) {
 	if err := applyFilters(&ov); err != nil {
 		return nil, fmt.Errorf("filter error: %w", err)
 	}
 
+	eo, err := e.placeOrder(ctx, ov)
+	if err != nil {
+		return e.placedOrder(ctx, ov.symbol.Symbol, ov.clientOrderID, err)
+	}
+	return eo, nil
+}
+
+// placeOrder sends the filtered order with the service of its type
+func (e *Exchange) placeOrder(ctx context.Context, ov orderValues) (*domain.ExchangeOrder, error) {
 	switch ov.orderType {
 	case futures.OrderTypeLimit:
 		return e.createLimit(ctx, ov)
@@ -123,19 +226,17 @@ func (e *Exchange) createOrder(ctx context.Context, ov orderValues) (*domain.Exc
 		return e.createStopMarket(ctx, ov)
 	case futures.OrderTypeTakeProfitMarket:
 		return e.createTakeProfitMarket(ctx, ov)
+	case futures.OrderTypeStop, futures.OrderTypeTakeProfit:
+		return e.createStopLimit(ctx, ov)
 	}
 
 	return nil, fmt.Errorf("unsupported order type %s", ov.orderType)
 }
 
 func (e *Exchange) createLimit(ctx context.Context, ov orderValues) (*domain.ExchangeOrder, error) {
-	svc := e.client.NewCreateOrderService().
-		Symbol(ov.symbol.Symbol).
-		Side(ov.side).
-		Type(ov.orderType).
+	svc := e.createOrderService(ov).
 		TimeInForce(ov.timeInForce).
-		Quantity(fmt.Sprint(ov.baseQuantity)).
-		Price(fmt.Sprint(ov.price))
+		Price(ov.price.String())
 
 	resp, err := svc.Do(ctx)
 	if err != nil {
@@ -146,13 +247,8 @@ func (e *Exchange) createLimit(ctx context.Context, ov orderValues) (*domain.Exc
 }
 
 func (e *Exchange) createStopMarket(ctx context.Context, ov orderValues) (*domain.ExchangeOrder, error) {
-	svc := e.client.NewCreateOrderService().
-		Symbol(ov.symbol.Symbol).
-		Side(ov.side).
-		Type(ov.orderType).
-		TimeInForce(ov.timeInForce).
-		Quantity(fmt.Sprint(ov.baseQuantity)).
-		StopPrice(fmt.Sprint(ov.price))
+	svc := e.createOrderService(ov).
+		StopPrice(ov.stopPrice.String())
 
 	resp, err := svc.Do(ctx)
 	if err != nil {
@@ -163,13 +259,8 @@ func (e *Exchange) createStopMarket(ctx context.Context, ov orderValues) (*domai
 }
 
 func (e *Exchange) createTakeProfitMarket(ctx context.Context, ov orderValues) (*domain.ExchangeOrder, error) {
-	svc := e.client.NewCreateOrderService().
-		Symbol(ov.symbol.Symbol).
-		Side(ov.side).
-		Type(ov.orderType).
-		TimeInForce(ov.timeInForce).
-		Quantity(fmt.Sprint(ov.baseQuantity)).
-		StopPrice(fmt.Sprint(ov.price))
+	svc := e.createOrderService(ov).
+		StopPrice(ov.stopPrice.String())
 
 	resp, err := svc.Do(ctx)
 	if err != nil {
@@ -179,162 +270,193 @@ func (e *Exchange) createTakeProfitMarket(ctx context.Context, ov orderValues) (
 	return createRespToOrder(resp)
 }
 
-func (f *Exchange) modifyOrder(ctx context.Context, req outbound.ModifyExchangeOrderRequest) (*domain.ExchangeOrder, error) {
-	eo := req.EO
+// createStopLimit creates STOP and TAKE_PROFIT orders, both have a stop price and a limit price
+func (e *Exchange) createStopLimit(ctx context.Context, ov orderValues) (*domain.ExchangeOrder, error) {
+	svc := e.createOrderService(ov).
+		TimeInForce(ov.timeInForce).
+		Price(ov.price.String()).
+		StopPrice(ov.stopPrice.String())
 
-	symbol, err := f.symbol(ctx, eo.Symbol)
+	resp, err := svc.Do(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	ov := orderValues{
-		symbol:       symbol,
-		side:         futures.SideType(eo.Side),
-		orderType:    futures.OrderTypeLimit,
-		price:        req.Price,
-		baseQuantity: req.BaseQuantity,
-		timeInForce:  futures.TimeInForceTypeGTC,
+	return createRespToOrder(resp)
+}
+
+// positionSide returns the position side of new orders, accounts in one-way mode use BOTH
+func (e *Exchange) positionSide(ps domain.PositionSide) futures.PositionSideType {
+	if !e.hedgeMode {
+		return futures.PositionSideTypeBoth
 	}
 
-	if err := applyFilters(&ov); err != nil {
+	switch ps {
+	case domain.PositionSideLong:
+		return futures.PositionSideTypeLong
+	case domain.PositionSideShort:
+		return futures.PositionSideTypeShort
+	}
+
+	return futures.PositionSideTypeBoth
+}
+
+// createOrderService sets the values common to all order types,
+// close position orders are sent without a quantity and reduce only flag as binance rejects them.
+func (e *Exchange) createOrderService(ov orderValues) *futures.CreateOrderService {
+	svc := e.client.NewCreateOrderService().
+		Symbol(ov.symbol.Symbol).
+		Side(ov.side).
+		Type(ov.orderType)
+
+	if ov.workingType != "" {
+		svc = svc.WorkingType(ov.workingType)
+	}
+
+	if ov.clientOrderID != "" {
+		svc = svc.NewClientOrderID(ov.clientOrderID)
+	}
+
+	if ov.positionSide != "" && ov.positionSide != futures.PositionSideTypeBoth {
+		svc = svc.PositionSide(ov.positionSide)
+		// reduce only is implied by the position side in hedge mode and binance rejects it
+		ov.reduceOnly = false
+	}
+
+	if ov.closePosition {
+		return svc.ClosePosition(true)
+	}
+
+	svc = svc.Quantity(ov.baseQuantity.String())
+
+	if ov.reduceOnly {
+		svc = svc.ReduceOnly(true)
+	}
+
+	return svc
+}
+
+func (f *Exchange) modifyOrder(ctx context.Context, req outbound.ModifyExchangeOrderRequest) (*domain.ExchangeOrder, error) {
+	eo := req.EO
+
+	ov, err := f.modifyValues(ctx, req)
+	if err != nil {
 		return nil, err
 	}
 
-	if ov.price == eo.Price && ov.baseQuantity == eo.BaseQuantity {
-		f.logger.Debugf("ignoring modification of order %d, prev=%s, new=%f", eo.ID, eo.Price, ov.price)
+	if unchanged(eo, ov) {
+		f.logger.Debugf("ignoring modification of order %d, prev=%f, new=%s", eo.ID, eo.Price, ov.price)
 		return eo, nil
 	}
 
 	switch ov.orderType {
 	case futures.OrderTypeLimit:
-		svc := f.client.NewModifyOrderService().
-			OrderID(eo.ID.(int64)).
-			Price(ov.price).
-			Quantity(ov.baseQuantity).
-			Side(ov.side).
-			Symbol(ov.symbol.Symbol)
-
-		resp, err := svc.Do(ctx)
+		// the library's modify service formats the values as floats
+		data, err := f.signedRequest(ctx, http.MethodPut, "/fapi/v1/order", url.Values{
+			"orderId":  {strconv.FormatInt(eo.ID.(int64), 10)},
+			"symbol":   {ov.symbol.Symbol},
+			"side":     {string(ov.side)},
+			"quantity": {ov.baseQuantity.String()},
+			"price":    {ov.price.String()},
+		})
 		// If error is "no need to modify the order, ignore err"
 		apiErr, ok := err.(*common.APIError)
 		if ok && apiErr.Code == -5027 {
-			f.logger.Debugf("ignoring modification of order %d, prev=%s, new=%f", eo.ID, eo.Price, ov.price)
+			f.logger.Debugf("ignoring modification of order %d, prev=%f, new=%s", eo.ID, eo.Price, ov.price)
 			return eo, nil
 		}
 		if err != nil {
 			return nil, err
 		}
 
+		resp := &futures.ModifyOrderResponse{}
+		if err := json.Unmarshal(data, resp); err != nil {
+			return nil, err
+		}
+
 		return modifyRespToOrder(resp)
 	}
 
 	return f.recreateOrder(ctx, eo, ov)
 }
 
-func (f *Exchange) recreateOrder(ctx context.Context, eo *domain.ExchangeOrder, ov orderValues) (*domain.ExchangeOrder, error) {
-	_, err := f.cancelOrder(ctx, outbound.CancelExchangeOrdersRequest{EO: eo})
-	if err != nil {
-		return nil, err
-	}
-	return f.createOrder(ctx, ov)
-}
-
-func (f *Exchange) cancelOrder(ctx context.Context, req outbound.CancelExchangeOrdersRequest) (*domain.ExchangeOrder, error) {
+// modifyValues returns the filtered values of the modified order, the rest of the values are taken from the order
+func (f *Exchange) modifyValues(ctx context.Context, req outbound.ModifyExchangeOrderRequest) (orderValues, error) {
 	eo := req.EO
-	svc := f.client.NewCancelOrderService()
-	resp, err := svc.OrderID(eo.ID.(int64)).Symbol(eo.Symbol).Do(ctx)
+
+	symbol, err := f.symbol(ctx, eo.Symbol)
 	if err != nil {
-		return nil, err
+		return orderValues{}, err
 	}
-	return cancelRespToOrder(resp)
-}
-
-// info tries to read the ei from file, if it doesn't exist or is outdated it attempts to fetch the ei
-func (f *Exchange) info(ctx context.Context, force bool) (updated bool, err error) {
-	// Load if not exists
-	ei, err := f.eier.Read(eiFn())
-	if force || os.IsNotExist(err) {
-		ei, err = f.getExchangeInfo(ctx)
-		if err != nil {
-			return false, err
-		}
 
-		f.ei = ei
-
-		// Ignore save error
-		if err := f.eier.Save(eiFn(), ei); err != nil {
-			f.logger.Errorf("error saving exchange info: %v", err)
-		}
-		return true, nil
+	ov := orderValues{
+		symbol:        symbol,
+		side:          futures.SideType(eo.Side),
+		positionSide:  futures.PositionSideType(eo.PositionSide),
+		orderType:     futures.OrderType(eo.Type),
+		price:         decimal.FromFloat(req.Price),
+		stopPrice:     decimal.FromFloat(req.StopPrice),
+		baseQuantity:  decimal.FromFloat(req.BaseQuantity),
+		timeInForce:   timeInForce(domain.TimeInForce(eo.TimeInForce)),
+		workingType:   futures.WorkingType(eo.WorkingType),
+		reduceOnly:    eo.ReduceOnly,
+		closePosition: eo.ClosePosition,
+		clientOrderID: req.ClientOrderID,
 	}
 
-	f.ei = ei
-
-	// Try to fetch the ei if it's outdated
-	if time.Since(time.Unix(ei.ServerTime, 0)) > maxEiAge {
-		ei, err = f.getExchangeInfo(ctx)
-		if err != nil {
-			f.logger.Errorf("error saving exchange info: %v", err)
-			return false, err
-		}
-
-		f.ei = ei
+	if err := applyFilters(&ov); err != nil {
+		return orderValues{}, err
+	}
 
-		// Ignore save error
-		if err := f.eier.Save(eiFn(), ei); err != nil {
-			f.logger.Errorf("error saving exchange info: %v", err)
-		}
+	if ov.closePosition {
+		// close position orders have no quantity
+		ov.baseQuantity = decimal.FromFloat(eo.BaseQuantity)
 	}
 
-	return true, nil
+	return ov, nil
 }
 
-func (f *Exchange) getExchangeInfo(ctx context.Context) (ExchangeInfo, error) {
-	ei, err := f.client.NewExchangeInfoService().Do(ctx)
-	if err != nil {
-		return ExchangeInfo{}, err
-	}
-	return ExchangeInfo(*ei), err
+// unchanged returns true if the filtered values match the order, binance rejects modifications without changes
+func unchanged(eo *domain.ExchangeOrder, ov orderValues) bool {
+	return ov.price.Equal(decimal.FromFloat(eo.Price)) &&
+		ov.stopPrice.Equal(decimal.FromFloat(eo.StopPrice)) &&
+		ov.baseQuantity.Equal(decimal.FromFloat(eo.BaseQuantity))
 }
 
-func (f *Exchange) symbol(ctx context.Context, symbol string) (futures.Symbol, error) {
-	eiUpdated, err := f.info(ctx, false)
+func (f *Exchange) recreateOrder(ctx context.Context, eo *domain.ExchangeOrder, ov orderValues) (*domain.ExchangeOrder, error) {
+	_, err := f.cancelOrder(ctx, outbound.CancelExchangeOrdersRequest{EO: eo})
 	if err != nil {
-		return futures.Symbol{}, err
-	}
-
-	for _, fsymbol := range f.ei.Symbols {
-		if fsymbol.Symbol == symbol {
-			return fsymbol, nil
-		}
-	}
-
-	// ExchangeInfo was fresh yet such symbol was not found
-	if eiUpdated {
-		return futures.Symbol{}, fmt.Errorf("unknown symbol: %s", symbol)
+		return nil, err
 	}
+	return f.createOrder(ctx, ov)
+}
 
-	// ExchangeInfo was not fresh, force reload and try finding symbol again
-	_, err = f.info(ctx, true)
+func (f *Exchange) cancelOrder(ctx context.Context, req outbound.CancelExchangeOrdersRequest) (*domain.ExchangeOrder, error) {
+	eo := req.EO
+	svc := f.client.NewCancelOrderService()
+	resp, err := svc.OrderID(eo.ID.(int64)).Symbol(eo.Symbol).Do(ctx)
 	if err != nil {
-		return futures.Symbol{}, err
-	}
-
-	for _, fsymbol := range f.ei.Symbols {
-		if fsymbol.Symbol == symbol {
-			return fsymbol, nil
-		}
+		return nil, err
 	}
+	return cancelRespToOrder(resp)
+}
 
-	return futures.Symbol{}, fmt.Errorf("unknown symbol: %s", symbol)
+func (f *Exchange) symbol(ctx context.Context, symbol string) (futures.Symbol, error) {
+	return f.cache.Symbol(ctx, symbol)
 }
 
 type orderValues struct {
-	symbol       futures.Symbol
-	side         futures.SideType
-	orderType    futures.OrderType
-	price        float64
-	stopPrice    float64
-	baseQuantity float64
-	timeInForce  futures.TimeInForceType
+	symbol        futures.Symbol
+	side          futures.SideType
+	positionSide  futures.PositionSideType
+	orderType     futures.OrderType
+	price         decimal.Decimal
+	stopPrice     decimal.Decimal
+	baseQuantity  decimal.Decimal
+	timeInForce   futures.TimeInForceType
+	workingType   futures.WorkingType
+	reduceOnly    bool
+	closePosition bool
+	// clientOrderID is sent as newClientOrderId if it's set
+	clientOrderID string
 }
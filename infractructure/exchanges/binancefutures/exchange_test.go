@@ -2,12 +2,16 @@ package binancefutures
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"reflect"
 	"testing"
 
 	"github.com/H3Cki/Plotrader/core/domain"
 	"github.com/H3Cki/Plotrader/core/outbound"
 	"github.com/H3Cki/go-binance/v2/futures"
+	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 )
 
@@ -15,8 +19,7 @@ func TestExchange_ModifyOrder(t *testing.T) {
 	type fields struct {
 		logger *zap.SugaredLogger
 		client *futures.Client
-		ei     ExchangeInfo
-		eier   outbound.FileLoader[ExchangeInfo]
+		cache  *infoCache
 	}
 	type args struct {
 		ctx context.Context
@@ -36,8 +39,7 @@ func TestExchange_ModifyOrder(t *testing.T) {
 			f := &Exchange{
 				logger: tt.fields.logger,
 				client: tt.fields.client,
-				ei:     tt.fields.ei,
-				eier:   tt.fields.eier,
+				cache:  tt.fields.cache,
 			}
 			got, err := f.ModifyOrder(tt.args.ctx, tt.args.req)
 			if (err != nil) != tt.wantErr {
@@ -55,8 +57,7 @@ func TestExchange_modifyOrder(t *testing.T) {
 	type fields struct {
 		logger *zap.SugaredLogger
 		client *futures.Client
-		ei     ExchangeInfo
-		eier   outbound.FileLoader[ExchangeInfo]
+		cache  *infoCache
 	}
 	type args struct {
 		ctx context.Context
@@ -76,8 +77,7 @@ func TestExchange_modifyOrder(t *testing.T) {
 			f := &Exchange{
 				logger: tt.fields.logger,
 				client: tt.fields.client,
-				ei:     tt.fields.ei,
-				eier:   tt.fields.eier,
+				cache:  tt.fields.cache,
 			}
 			got, err := f.modifyOrder(tt.args.ctx, tt.args.req)
 			if (err != nil) != tt.wantErr {
@@ -95,8 +95,7 @@ func TestExchange_CancelOrder(t *testing.T) {
 	type fields struct {
 		logger *zap.SugaredLogger
 		client *futures.Client
-		ei     ExchangeInfo
-		eier   outbound.FileLoader[ExchangeInfo]
+		cache  *infoCache
 	}
 	type args struct {
 		ctx context.Context
@@ -116,8 +115,7 @@ func TestExchange_CancelOrder(t *testing.T) {
 			f := &Exchange{
 				logger: tt.fields.logger,
 				client: tt.fields.client,
-				ei:     tt.fields.ei,
-				eier:   tt.fields.eier,
+				cache:  tt.fields.cache,
 			}
 			got, err := f.CancelOrder(tt.args.ctx, tt.args.req)
 			if (err != nil) != tt.wantErr {
@@ -135,8 +133,7 @@ func TestExchange_cancelOrder(t *testing.T) {
 	type fields struct {
 		logger *zap.SugaredLogger
 		client *futures.Client
-		ei     ExchangeInfo
-		eier   outbound.FileLoader[ExchangeInfo]
+		cache  *infoCache
 	}
 	type args struct {
 		ctx context.Context
@@ -156,8 +153,7 @@ func TestExchange_cancelOrder(t *testing.T) {
 			f := &Exchange{
 				logger: tt.fields.logger,
 				client: tt.fields.client,
-				ei:     tt.fields.ei,
-				eier:   tt.fields.eier,
+				cache:  tt.fields.cache,
 			}
 			got, err := f.cancelOrder(tt.args.ctx, tt.args.req)
 			if (err != nil) != tt.wantErr {
@@ -170,3 +166,91 @@ func TestExchange_cancelOrder(t *testing.T) {
 		})
 	}
 }
+
+type memoryLoader struct {
+	ei *ExchangeInfo
+}
+
+func (l *memoryLoader) Exists(name string) bool { return l.ei != nil }
+
+func (l *memoryLoader) Save(name string, data ExchangeInfo) error {
+	l.ei = &data
+	return nil
+}
+
+func (l *memoryLoader) Read(name string) (ExchangeInfo, error) {
+	if l.ei == nil {
+		return ExchangeInfo{}, os.ErrNotExist
+	}
+	return *l.ei, nil
+}
+
+func TestExchange_Init_PositionMode(t *testing.T) {
+	for _, dualSide := range []bool{true, false} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/fapi/v1/ping":
+				w.Write([]byte(`{}`))
+			case "/fapi/v1/positionSide/dual":
+				if dualSide {
+					w.Write([]byte(`{"dualSidePosition":true}`))
+				} else {
+					w.Write([]byte(`{"dualSidePosition":false}`))
+				}
+			case "/fapi/v1/exchangeInfo":
+				w.Write([]byte(`{"symbols":[]}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+
+		client := futures.NewClient("key", "secret")
+		client.BaseURL = srv.URL
+		e := &Exchange{logger: zap.NewNop().Sugar(), client: client}
+		e.cache = newInfoCache(e.logger, client, &memoryLoader{}, eiFileName)
+
+		assert.NoError(t, e.Init(context.Background()))
+		assert.Equal(t, dualSide, e.hedgeMode)
+
+		srv.Close()
+	}
+}
+
+func TestExchange_positionSide(t *testing.T) {
+	oneWay := &Exchange{}
+	assert.Equal(t, futures.PositionSideTypeBoth, oneWay.positionSide(domain.PositionSideLong))
+
+	hedge := &Exchange{hedgeMode: true}
+	assert.Equal(t, futures.PositionSideTypeLong, hedge.positionSide(domain.PositionSideLong))
+	assert.Equal(t, futures.PositionSideTypeShort, hedge.positionSide(domain.PositionSideShort))
+	assert.Equal(t, futures.PositionSideTypeBoth, hedge.positionSide(""))
+}
+
+func TestExchange_ConfigurePosition(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path+" "+r.FormValue("symbol"))
+		switch r.URL.Path {
+		case "/fapi/v1/marginType":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":-4046,"msg":"No need to change margin type."}`))
+		case "/fapi/v1/leverage":
+			w.Write([]byte(`{"leverage":5,"maxNotionalValue":"1000000","symbol":"BTCUSDT"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	client := futures.NewClient("key", "secret")
+	client.BaseURL = srv.URL
+	e := &Exchange{logger: zap.NewNop().Sugar(), client: client}
+
+	err := e.ConfigurePosition(context.Background(), outbound.ConfigurePositionRequest{
+		Pair:       domain.Pair{Base: "BTC", Quote: "USDT"},
+		Leverage:   5,
+		MarginType: domain.MarginTypeIsolated,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/fapi/v1/marginType BTCUSDT", "/fapi/v1/leverage BTCUSDT"}, paths)
+}
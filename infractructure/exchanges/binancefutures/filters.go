@@ -1,11 +1,10 @@
 package binancefutures
 
 import (
-	"errors"
 	"fmt"
-	"math"
-	"strconv"
 
+	"github.com/H3Cki/Plotrader/infractructure/exchanges/binancefilters"
+	"github.com/H3Cki/Plotrader/infractructure/exchanges/decimal"
 	"github.com/H3Cki/go-binance/v2/futures"
 )
 
@@ -44,18 +43,26 @@ var orderTypeFilters = map[futures.OrderType]func(*orderValues) error{
 	},
 	futures.OrderTypeStopMarket:       marketOrderFilters,
 	futures.OrderTypeTakeProfitMarket: marketOrderFilters,
+	futures.OrderTypeStop:             stopLimitOrderFilters,
+	futures.OrderTypeTakeProfit:       stopLimitOrderFilters,
 }
 
+// marketOrderFilters applies to STOP_MARKET and TAKE_PROFIT_MARKET orders, which only have a stop price
 var marketOrderFilters = func(req *orderValues) error {
 	s := req.symbol
 	// PRICE
 	if pf := s.PriceFilter(); pf != nil {
-		price, err := priceFilter(pf, req.price)
+		stopPrice, err := priceFilter(pf, req.stopPrice)
 		if err != nil {
 			return err
 		}
 
-		req.price = price
+		req.stopPrice = stopPrice
+	}
+
+	// close position orders have no quantity
+	if req.closePosition {
+		return nil
 	}
 
 	// MARKET LOT SIZE
@@ -70,7 +77,7 @@ var marketOrderFilters = func(req *orderValues) error {
 
 	// MIN NOTIONAL
 	if mnf := s.MinNotionalFilter(); mnf != nil {
-		err := minNotionalFilter(mnf, req.price, req.baseQuantity)
+		err := minNotionalFilter(mnf, req.stopPrice, req.baseQuantity)
 		if err != nil {
 			return err
 		}
@@ -79,130 +86,69 @@ var marketOrderFilters = func(req *orderValues) error {
 	return nil
 }
 
-func applyFilters(or *orderValues) error {
-	filterFunc, ok := orderTypeFilters[futures.OrderType(or.orderType)]
-	if !ok {
-		return fmt.Errorf("unsupported order type: %v", or.orderType)
-	}
-
-	return filterFunc(or)
-}
-
-// priceFilter returns a price adjusted for the tickSize for a given symbol,
-// returns an error if the price exceeds min or max value.
-func priceFilter(pf *futures.PriceFilter, price float64) (float64, error) {
-	tickSize, err := strconv.ParseFloat(pf.TickSize, 64)
-	if err != nil {
-		return 0, err
-	}
-
-	newPrice := price
+// stopLimitOrderFilters applies to STOP and TAKE_PROFIT orders, which have both a limit price and a stop price
+var stopLimitOrderFilters = func(req *orderValues) error {
+	s := req.symbol
+	// PRICE
+	if pf := s.PriceFilter(); pf != nil {
+		price, err := priceFilter(pf, req.price)
+		if err != nil {
+			return err
+		}
 
-	if tickSize != 0 {
-		// set price to nearest multiple of tickSize
-		decimals := stringDecimalPlacesExp(pf.TickSize)
-		newPrice = math.Round(price/tickSize) * tickSize
-		newPrice = math.Round(newPrice*decimals) / decimals
-	}
+		stopPrice, err := priceFilter(pf, req.stopPrice)
+		if err != nil {
+			return err
+		}
 
-	minPrice, err := strconv.ParseFloat(pf.MinPrice, 64)
-	if err != nil {
-		return 0, err
+		req.price = price
+		req.stopPrice = stopPrice
 	}
 
-	// reject if price is lower than min price
-	if minPrice != 0 && newPrice < minPrice {
-		return 0, nil
-	}
+	// LOT SIZE
+	if lsf := s.LotSizeFilter(); lsf != nil {
+		qty, err := lotSizeFilter(lsf, req.baseQuantity)
+		if err != nil {
+			return err
+		}
 
-	// reject is price is higher than max price
-	maxPrice, err := strconv.ParseFloat(pf.MaxPrice, 64)
-	if err != nil {
-		return 0, err
+		req.baseQuantity = qty
 	}
 
-	if maxPrice != 0 && newPrice > maxPrice {
-		return 0, nil
+	// MIN NOTIONAL
+	if mnf := s.MinNotionalFilter(); mnf != nil {
+		err := minNotionalFilter(mnf, req.price, req.baseQuantity)
+		if err != nil {
+			return err
+		}
 	}
 
-	return newPrice, nil
+	return nil
 }
 
-func lotSizeFilter(lsf *futures.LotSizeFilter, qty float64) (float64, error) {
-	stepSize, err := strconv.ParseFloat(lsf.StepSize, 64)
-	if err != nil {
-		return 0, err
-	}
-
-	decimals := stringDecimalPlacesExp(lsf.StepSize)
-	newQty := math.Floor(qty/stepSize) * stepSize
-	newQty = math.Round(newQty*decimals) / decimals
-
-	minQty, err := strconv.ParseFloat(lsf.MinQuantity, 64)
-	if err != nil {
-		return 0, err
-	}
-
-	if newQty < minQty {
-		return 0, errors.New("quantity too small")
-	}
-
-	maxQty, err := strconv.ParseFloat(lsf.MaxQuantity, 64)
-	if err != nil {
-		return 0, err
-	}
-
-	if newQty > maxQty {
-		return 0, errors.New("quantity too large")
+func applyFilters(or *orderValues) error {
+	filterFunc, ok := orderTypeFilters[futures.OrderType(or.orderType)]
+	if !ok {
+		return fmt.Errorf("unsupported order type: %v", or.orderType)
 	}
 
-	return newQty, nil
+	return filterFunc(or)
 }
 
-func marketLotSizeFilter(lsf *futures.MarketLotSizeFilter, qty float64) (float64, error) {
-	stepSize, err := strconv.ParseFloat(lsf.StepSize, 64)
-	if err != nil {
-		return 0, err
-	}
-
-	decimals := stringDecimalPlacesExp(lsf.StepSize)
-	newQty := math.Floor(qty/stepSize) * stepSize
-	newQty = math.Round(newQty*decimals) / decimals
-
-	minQty, err := strconv.ParseFloat(lsf.MinQuantity, 64)
-	if err != nil {
-		return 0, err
-	}
-
-	if newQty < minQty {
-		return 0, errors.New("quantity too small")
-	}
-
-	maxQty, err := strconv.ParseFloat(lsf.MaxQuantity, 64)
-	if err != nil {
-		return 0, err
-	}
-
-	if newQty > maxQty {
-		return 0, errors.New("quantity too large")
-	}
-
-	return newQty, nil
+// priceFilter returns a price adjusted for the tickSize for a given symbol,
+// returns 0 if the price exceeds min or max value.
+func priceFilter(pf *futures.PriceFilter, price decimal.Decimal) (decimal.Decimal, error) {
+	return binancefilters.Price(pf.TickSize, pf.MinPrice, pf.MaxPrice, price)
 }
 
-func minNotionalFilter(mnf *futures.MinNotionalFilter, price, qty float64) error {
-	if mnf.Notional == "" {
-		return nil
-	}
-
-	minNotional, err := strconv.ParseFloat(mnf.Notional, 64)
-	if err != nil {
-		return err
-	}
+func lotSizeFilter(lsf *futures.LotSizeFilter, qty decimal.Decimal) (decimal.Decimal, error) {
+	return binancefilters.LotSize(lsf.StepSize, lsf.MinQuantity, lsf.MaxQuantity, qty)
+}
 
-	if price*qty < minNotional {
-		return fmt.Errorf("minNotional too small, expected > %f, got %f", minNotional, price*qty)
-	}
+func marketLotSizeFilter(lsf *futures.MarketLotSizeFilter, qty decimal.Decimal) (decimal.Decimal, error) {
+	return binancefilters.LotSize(lsf.StepSize, lsf.MinQuantity, lsf.MaxQuantity, qty)
+}
 
-	return nil
+func minNotionalFilter(mnf *futures.MinNotionalFilter, price, qty decimal.Decimal) error {
+	return binancefilters.MinNotional(mnf.Notional, price, qty)
 }
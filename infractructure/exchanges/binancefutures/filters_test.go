@@ -5,6 +5,7 @@ import (
 	"math"
 	"testing"
 
+	"github.com/H3Cki/Plotrader/infractructure/exchanges/decimal"
 	"github.com/H3Cki/go-binance/v2/futures"
 	"github.com/stretchr/testify/assert"
 )
@@ -103,15 +104,15 @@ func Test_applyFuturesFilters(t *testing.T) {
 				o: orderValues{
 					symbol:       symbolfETHBTC,
 					orderType:    futures.OrderTypeLimit,
-					price:        0.12345678912345,
-					baseQuantity: 0.212345678912345,
+					price:        decimal.FromFloat(0.12345678912345),
+					baseQuantity: decimal.FromFloat(0.212345678912345),
 				},
 			},
 			wantErr: false,
 			exRes: orderValues{
 				orderType:    futures.OrderTypeLimit,
-				price:        0.123457,
-				baseQuantity: 0.21,
+				price:        decimal.FromFloat(0.123457),
+				baseQuantity: decimal.FromFloat(0.21),
 			},
 		},
 		{
@@ -120,15 +121,15 @@ func Test_applyFuturesFilters(t *testing.T) {
 				o: orderValues{
 					symbol:       symbolfETHBTC,
 					orderType:    futures.OrderTypeLimit,
-					price:        0.078794,
-					baseQuantity: 0.0001,
+					price:        decimal.FromFloat(0.078794),
+					baseQuantity: decimal.FromFloat(0.0001),
 				},
 			},
 			wantErr: true,
 			exRes: orderValues{
 				orderType:    futures.OrderTypeLimit,
-				price:        0.078794,
-				baseQuantity: 0.0001,
+				price:        decimal.FromFloat(0.078794),
+				baseQuantity: decimal.FromFloat(0.0001),
 			},
 		},
 		{
@@ -137,15 +138,67 @@ func Test_applyFuturesFilters(t *testing.T) {
 				o: orderValues{
 					symbol:       symbolfETHBTC,
 					orderType:    futures.OrderTypeLimit,
-					price:        0.078794,
-					baseQuantity: 0.0001,
+					price:        decimal.FromFloat(0.078794),
+					baseQuantity: decimal.FromFloat(0.0001),
 				},
 			},
 			wantErr: true,
 			exRes: orderValues{
 				orderType:    futures.OrderTypeLimit,
-				price:        0.078794,
-				baseQuantity: 100000.0,
+				price:        decimal.FromFloat(0.078794),
+				baseQuantity: decimal.FromFloat(100000.0),
+			},
+		},
+		{
+			name: "stop limit",
+			args: args{
+				o: orderValues{
+					symbol:       symbolfETHBTC,
+					orderType:    futures.OrderTypeStop,
+					price:        decimal.FromFloat(0.12345678912345),
+					stopPrice:    decimal.FromFloat(0.12445678912345),
+					baseQuantity: decimal.FromFloat(0.212345678912345),
+				},
+			},
+			wantErr: false,
+			exRes: orderValues{
+				orderType:    futures.OrderTypeStop,
+				price:        decimal.FromFloat(0.123457),
+				stopPrice:    decimal.FromFloat(0.124457),
+				baseQuantity: decimal.FromFloat(0.21),
+			},
+		},
+		{
+			name: "stop market",
+			args: args{
+				o: orderValues{
+					symbol:       symbolfETHBTC,
+					orderType:    futures.OrderTypeStopMarket,
+					stopPrice:    decimal.FromFloat(0.12345678912345),
+					baseQuantity: decimal.FromFloat(0.212345678912345),
+				},
+			},
+			wantErr: false,
+			exRes: orderValues{
+				orderType:    futures.OrderTypeStopMarket,
+				stopPrice:    decimal.FromFloat(0.123457),
+				baseQuantity: decimal.FromFloat(0.212345678912345),
+			},
+		},
+		{
+			name: "close position",
+			args: args{
+				o: orderValues{
+					symbol:        symbolfETHBTC,
+					orderType:     futures.OrderTypeTakeProfitMarket,
+					stopPrice:     decimal.FromFloat(0.12345678912345),
+					closePosition: true,
+				},
+			},
+			wantErr: false,
+			exRes: orderValues{
+				orderType: futures.OrderTypeTakeProfitMarket,
+				stopPrice: decimal.FromFloat(0.123457),
 			},
 		},
 	}
@@ -158,8 +211,13 @@ func Test_applyFuturesFilters(t *testing.T) {
 			if tt.wantErr {
 				return
 			}
-			assert.Equal(t, tt.exRes.price, tt.args.o.price)
-			assert.LessOrEqual(t, math.Abs(gain(tt.exRes.baseQuantity, tt.args.o.baseQuantity)), 0.001)
+			assert.Equal(t, tt.exRes.price.String(), tt.args.o.price.String())
+			assert.Equal(t, tt.exRes.stopPrice.String(), tt.args.o.stopPrice.String())
+			if tt.exRes.baseQuantity.IsZero() {
+				assert.True(t, tt.args.o.baseQuantity.IsZero())
+				return
+			}
+			assert.LessOrEqual(t, math.Abs(gain(tt.exRes.baseQuantity.Float64(), tt.args.o.baseQuantity.Float64())), 0.001)
 		})
 	}
 }
@@ -212,12 +270,12 @@ func Test_futuresPriceFilter(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := priceFilter(pf, tt.price)
+			got, err := priceFilter(pf, decimal.FromFloat(tt.price))
 			if (err != nil) != tt.wantErr {
 				t.Errorf("priceFilter() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if got != tt.want {
+			if got.Float64() != tt.want {
 				t.Errorf("priceFilter() = %v, want %v", got, tt.want)
 			}
 		})
@@ -260,12 +318,12 @@ func Test_futuresLotSizeFilter(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := lotSizeFilter(pf, tt.qty)
+			got, err := lotSizeFilter(pf, decimal.FromFloat(tt.qty))
 			if (err != nil) != tt.wantErr {
 				t.Errorf("lotSizeFilter() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if got != tt.want {
+			if got.Float64() != tt.want {
 				t.Errorf("lotSizeFilter() = %v, want %v", got, tt.want)
 			}
 		})
@@ -0,0 +1,57 @@
+package binancefutures
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/go-binance/v2/common"
+)
+
+// lookupTimeout limits the lookup of an order after a failed placement, the placement's context may have expired
+const lookupTimeout = 10 * time.Second
+
+// placedOrder looks up the order with the client order ID after its placement failed with err,
+// if binance may have accepted it. The order is returned if it exists and err otherwise,
+// so placements that timed out after binance accepted them aren't placed again.
+func (e *Exchange) placedOrder(ctx context.Context, symbol, clientOrderID string, err error) (*domain.ExchangeOrder, error) {
+	if clientOrderID == "" || !ambiguous(err) {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), lookupTimeout)
+	defer cancel()
+
+	order, lookupErr := e.client.NewGetOrderService().Symbol(symbol).OrigClientOrderID(clientOrderID).Do(ctx)
+	// "Order does not exist", the placement didn't reach binance
+	apiErr, ok := lookupErr.(*common.APIError)
+	if ok && apiErr.Code == -2013 {
+		return nil, err
+	}
+	if lookupErr != nil {
+		return nil, fmt.Errorf("%w, error looking up order %s: %w", err, clientOrderID, lookupErr)
+	}
+
+	e.logger.Infof("order %s was placed despite error: %s", clientOrderID, err)
+	return orderToOrder(order)
+}
+
+// ambiguous returns true if a placement failed without binance telling whether it accepted the order,
+// or binance rejected it because an order with the same client order ID was placed before.
+func ambiguous(err error) bool {
+	apiErr, ok := err.(*common.APIError)
+	if !ok {
+		// timeouts, network errors and unreadable responses
+		return true
+	}
+
+	switch apiErr.Code {
+	case -1006, // "An unexpected response was received from the message bus. Execution status unknown."
+		-1007, // "Timeout waiting for response from backend server. Send status unknown; execution status unknown."
+		-4116: // "ClientOrderId is duplicated."
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,184 @@
+package binancefutures
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/Plotrader/core/outbound"
+	"github.com/H3Cki/go-binance/v2/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// placementServer answers placements with place and lookups by client order ID with the orders accepted so far,
+// place returns whether binance accepted the order and the error of the response.
+type placementServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	placed  map[string]map[string]any
+	lookups []string
+}
+
+func newPlacementServer(place func() (bool, *common.APIError)) *placementServer {
+	s := &placementServer{placed: map[string]map[string]any{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+
+		// lookups wait for the placements in progress
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if r.Method == http.MethodGet {
+			id := r.Form.Get("origClientOrderId")
+			s.lookups = append(s.lookups, id)
+			order, ok := s.placed[id]
+			if !ok {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"code":-2013,"msg":"Order does not exist."}`))
+				return
+			}
+			json.NewEncoder(w).Encode(order)
+			return
+		}
+
+		id := r.Form.Get("newClientOrderId")
+		order := orderJSON(len(s.placed)+1, r.Form.Get("symbol"), r.Form.Get("type"), r.Form.Get("price"), r.Form.Get("quantity"))
+		order["clientOrderId"] = id
+
+		accepted, apiErr := place()
+		if accepted {
+			s.placed[id] = order
+		}
+		if apiErr != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(apiErr)
+			return
+		}
+		json.NewEncoder(w).Encode(order)
+	}))
+	return s
+}
+
+func limitRequest(clientOrderID string) outbound.CreateExchangeOrderRequest {
+	return outbound.CreateExchangeOrderRequest{
+		Pair:          domain.Pair{Base: "BTC", Quote: "USDT"},
+		Type:          domain.OrderTypeLimit,
+		Side:          domain.OrderSideBuy,
+		BaseQuantity:  1,
+		Price:         60,
+		ClientOrderID: clientOrderID,
+	}
+}
+
+func TestExchange_CreateOrder_Timeout(t *testing.T) {
+	// binance accepts the order but the response arrives after the placement timed out
+	srv := newPlacementServer(func() (bool, *common.APIError) {
+		time.Sleep(100 * time.Millisecond)
+		return true, nil
+	})
+	defer srv.Close()
+
+	e := newBatchExchange(srv.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	eo, err := e.CreateOrder(ctx, limitRequest("abc"))
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", eo.ClientID)
+	assert.Equal(t, []string{"abc"}, srv.lookups)
+	assert.Len(t, srv.placed, 1)
+}
+
+func TestExchange_CreateOrder_Ambiguous(t *testing.T) {
+	tests := []struct {
+		name     string
+		accepted bool
+		code     int64
+		lookup   bool
+		wantErr  int64
+	}{
+		{"accepted despite unknown status", true, -1007, true, 0},
+		{"not accepted", false, -1007, true, -1007},
+		{"duplicate of an earlier placement", true, -4116, true, 0},
+		{"rejected", false, -2019, false, -2019},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newPlacementServer(func() (bool, *common.APIError) {
+				return tt.accepted, &common.APIError{Code: tt.code, Message: "error"}
+			})
+			defer srv.Close()
+
+			e := newBatchExchange(srv.URL)
+			eo, err := e.CreateOrder(context.Background(), limitRequest("abc"))
+
+			assert.Equal(t, tt.lookup, len(srv.lookups) == 1)
+			if tt.wantErr != 0 {
+				apiErr, ok := err.(*common.APIError)
+				assert.True(t, ok)
+				assert.Equal(t, tt.wantErr, apiErr.Code)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "abc", eo.ClientID)
+		})
+	}
+
+	// orders without a client order ID can't be looked up
+	srv := newPlacementServer(func() (bool, *common.APIError) {
+		return false, &common.APIError{Code: -1007, Message: "Timeout waiting for response from backend server."}
+	})
+	defer srv.Close()
+
+	_, err := newBatchExchange(srv.URL).CreateOrder(context.Background(), limitRequest(""))
+	assert.Error(t, err)
+	assert.Empty(t, srv.lookups)
+}
+
+func TestExchange_CreateOrders_Duplicate(t *testing.T) {
+	srv := newBatchServer(t, func(method string, form url.Values) any {
+		items := []any{}
+		for _, o := range batchOrders(t, form) {
+			assert.NotEmpty(t, o["newClientOrderId"])
+			if o["newClientOrderId"] == "placed" {
+				items = append(items, map[string]any{"code": -4116, "msg": "ClientOrderId is duplicated."})
+				continue
+			}
+			items = append(items, orderJSON(len(items)+1, o["symbol"], o["type"], o["price"], o["quantity"]))
+		}
+		return items
+	})
+	// the duplicate was placed by an earlier attempt
+	srv.Config.Handler = lookupHandler(srv.Config.Handler, map[string]any{
+		"orderId": 7, "clientOrderId": "placed", "symbol": "BTCUSDT", "status": "NEW", "type": "LIMIT",
+		"side": "BUY", "price": "60", "origQty": "1", "avgPrice": "0",
+	})
+	defer srv.Close()
+
+	e := newBatchExchange(srv.URL)
+	results := e.CreateOrders(context.Background(), []outbound.CreateExchangeOrderRequest{limitRequest("new"), limitRequest("placed")})
+
+	assert.NoError(t, results[0].Err)
+	assert.NoError(t, results[1].Err)
+	assert.Equal(t, int64(7), results[1].EO.ID)
+	assert.Equal(t, "placed", results[1].EO.ClientID)
+}
+
+// lookupHandler answers lookups of orders by client order ID with order and passes the other requests to next
+func lookupHandler(next http.Handler, order map[string]any) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Path == "/fapi/v1/order" && r.URL.Query().Get("origClientOrderId") == order["clientOrderId"] {
+			json.NewEncoder(w).Encode(order)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,39 @@
+package binancefutures
+
+import (
+	"context"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/outbound"
+	"github.com/H3Cki/Plotrader/infractructure/exchanges/infocache"
+	"github.com/H3Cki/go-binance/v2/futures"
+	"go.uber.org/zap"
+)
+
+// infoCache is the exchange info of an endpoint shared by the exchanges of a ClientPool
+type infoCache = infocache.Cache[ExchangeInfo, futures.Symbol]
+
+func newInfoCache(logger *zap.SugaredLogger, client *futures.Client, loader outbound.FileLoader[ExchangeInfo], fileName string) *infoCache {
+	return infocache.New(infocache.Config[ExchangeInfo, futures.Symbol]{
+		Logger:   logger,
+		Loader:   loader,
+		FileName: fileName,
+		MaxAge:   maxEiAge,
+		Fetch: func(ctx context.Context) (ExchangeInfo, error) {
+			ei, err := client.NewExchangeInfoService().Do(ctx)
+			if err != nil {
+				return ExchangeInfo{}, err
+			}
+			return ExchangeInfo(*ei), nil
+		},
+		Index: func(ei ExchangeInfo) map[string]futures.Symbol {
+			symbols := make(map[string]futures.Symbol, len(ei.Symbols))
+			for _, s := range ei.Symbols {
+				symbols[s.Symbol] = s
+			}
+			return symbols
+		},
+		// ServerTime is the fetch time in ms
+		FetchedAt: func(ei ExchangeInfo) time.Time { return time.UnixMilli(ei.ServerTime) },
+	})
+}
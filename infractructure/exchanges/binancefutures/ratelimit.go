@@ -0,0 +1,265 @@
+package binancefutures
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/outbound"
+	"github.com/H3Cki/go-binance/v2/futures"
+	"go.uber.org/zap"
+)
+
+// Default limits of the futures API, the request weight is limited per IP and the orders per account
+const (
+	weightLimitPerMinute = 2400
+	orderLimitPerMinute  = 1200
+	// defaultRetryAfter is the pause after a 429 or 418 response without a Retry-After header
+	defaultRetryAfter = time.Minute
+)
+
+// bucket is a token bucket refilled evenly over a minute, it's synced down to the usage reported by binance
+// and paused after 429 and 418 responses.
+type bucket struct {
+	mu          sync.Mutex
+	capacity    float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+
+	now   func() time.Time
+	sleep func(context.Context, time.Duration) error
+}
+
+func newBucket(capacity float64) *bucket {
+	return &bucket{
+		capacity: capacity,
+		tokens:   capacity,
+		last:     time.Now(),
+		now:      time.Now,
+		sleep:    sleepCtx,
+	}
+}
+
+// wait blocks until cost tokens are available and the bucket isn't paused, or ctx is done
+func (b *bucket) wait(ctx context.Context, cost float64) error {
+	for {
+		b.mu.Lock()
+		now := b.now()
+		b.refill(now)
+
+		var d time.Duration
+		switch {
+		case now.Before(b.pausedUntil):
+			d = b.pausedUntil.Sub(now)
+		case b.tokens >= cost:
+			b.tokens -= cost
+			b.mu.Unlock()
+			return nil
+		default:
+			d = time.Duration((cost - b.tokens) / b.capacity * float64(time.Minute))
+		}
+		b.mu.Unlock()
+
+		if err := b.sleep(ctx, d); err != nil {
+			return err
+		}
+	}
+}
+
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Minutes() * b.capacity
+		if b.tokens > b.capacity {
+			b.tokens = b.capacity
+		}
+	}
+	b.last = now
+}
+
+// used lowers the available tokens to what's left of the usage reported by binance, other clients share the limit
+func (b *bucket) used(n float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(b.now())
+	if left := b.capacity - n; b.tokens > left {
+		b.tokens = left
+	}
+}
+
+// pause stops the bucket from handing out tokens for d
+func (b *bucket) pause(d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if until := b.now().Add(d); until.After(b.pausedUntil) {
+		b.pausedUntil = until
+	}
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// limitedTransport waits for the weight and order buckets before sending requests
+// and updates them from the X-MBX-USED-WEIGHT-1M and X-MBX-ORDER-COUNT-1M headers of the responses.
+type limitedTransport struct {
+	next   http.RoundTripper
+	weight *bucket
+	orders *bucket
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	order := isOrderRequest(req)
+
+	if err := t.weight.wait(req.Context(), requestWeight(req)); err != nil {
+		return nil, err
+	}
+
+	if order {
+		if err := t.orders.wait(req.Context(), 1); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if n, err := strconv.ParseFloat(resp.Header.Get("X-MBX-USED-WEIGHT-1M"), 64); err == nil {
+		t.weight.used(n)
+	}
+
+	if n, err := strconv.ParseFloat(resp.Header.Get("X-MBX-ORDER-COUNT-1M"), 64); err == nil {
+		t.orders.used(n)
+	}
+
+	// 429 is a rate limit warning, 418 an IP ban for repeating requests after it, both tell how long to back off
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusTeapot {
+		t.weight.pause(retryAfter(resp))
+	}
+
+	return resp, nil
+}
+
+// requestWeights are the weights of the endpoints weighing more than 1 as they're called by the exchange,
+// the weights of some endpoints are higher without a symbol but the exchange always sets it.
+var requestWeights = map[string]float64{
+	"/fapi/v1/batchOrders":       5,
+	"/fapi/v2/balance":           5,
+	"/fapi/v2/positionRisk":      5,
+	"/fapi/v1/positionSide/dual": 30,
+}
+
+// requestWeight returns the request weight of the endpoint, 1 if it's not in requestWeights
+func requestWeight(req *http.Request) float64 {
+	if w, ok := requestWeights[req.URL.Path]; ok {
+		return w
+	}
+	return 1
+}
+
+// isOrderRequest returns true for requests counted by the order rate limit, placing and modifying orders
+func isOrderRequest(req *http.Request) bool {
+	if req.Method != http.MethodPost && req.Method != http.MethodPut {
+		return false
+	}
+	return strings.HasSuffix(req.URL.Path, "/order") || strings.HasSuffix(req.URL.Path, "/batchOrders")
+}
+
+func retryAfter(resp *http.Response) time.Duration {
+	s, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || s <= 0 {
+		return defaultRetryAfter
+	}
+	return time.Duration(s) * time.Second
+}
+
+// ClientPool shares futures clients between the exchanges of the same API key,
+// the clients of a pool share the request weight limit and exchange info of their endpoint
+// and the order limit of their API key.
+type ClientPool struct {
+	mu         sync.Mutex
+	clients    map[clientKey]*futures.Client
+	weights    map[string]*bucket
+	orders     map[string]*bucket
+	infoCaches map[string]*infoCache
+}
+
+type clientKey struct {
+	apiKey    string
+	secretKey string
+	baseURL   string
+}
+
+func NewClientPool() *ClientPool {
+	return &ClientPool{
+		clients:    map[clientKey]*futures.Client{},
+		weights:    map[string]*bucket{},
+		orders:     map[string]*bucket{},
+		infoCaches: map[string]*infoCache{},
+	}
+}
+
+// defaultClientPool is used by exchanges created without a pool
+var defaultClientPool = NewClientPool()
+
+// client returns the pooled client of the API key at baseURL, creating it if it doesn't exist
+func (p *ClientPool) client(apiKey, secretKey, baseURL string) *futures.Client {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := clientKey{apiKey: apiKey, secretKey: secretKey, baseURL: baseURL}
+	if c, ok := p.clients[key]; ok {
+		return c
+	}
+
+	weight, ok := p.weights[baseURL]
+	if !ok {
+		weight = newBucket(weightLimitPerMinute)
+		p.weights[baseURL] = weight
+	}
+
+	orders, ok := p.orders[baseURL+apiKey]
+	if !ok {
+		orders = newBucket(orderLimitPerMinute)
+		p.orders[baseURL+apiKey] = orders
+	}
+
+	c := futures.NewClient(apiKey, secretKey)
+	c.BaseURL = baseURL
+	c.HTTPClient = &http.Client{
+		Transport: &limitedTransport{
+			next:   http.DefaultTransport,
+			weight: weight,
+			orders: orders,
+		},
+	}
+
+	p.clients[key] = c
+	return c
+}
+
+// infoCache returns the exchange info cache of the endpoint, it's created with the client and loader of the first exchange
+func (p *ClientPool) infoCache(logger *zap.SugaredLogger, client *futures.Client, baseURL string, loader outbound.FileLoader[ExchangeInfo], fileName string) *infoCache {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if c, ok := p.infoCaches[baseURL]; ok {
+		return c
+	}
+
+	c := newInfoCache(logger, client, loader, fileName)
+	p.infoCaches[baseURL] = c
+	return c
+}
@@ -0,0 +1,170 @@
+package binancefutures
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock makes the bucket sleep instantly by advancing the time
+type fakeClock struct {
+	now   time.Time
+	slept []time.Duration
+}
+
+func (c *fakeClock) install(b *bucket) {
+	b.now = func() time.Time { return c.now }
+	b.sleep = func(_ context.Context, d time.Duration) error {
+		c.slept = append(c.slept, d)
+		c.now = c.now.Add(d)
+		return nil
+	}
+	b.last = c.now
+}
+
+func TestBucket(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	b := newBucket(60)
+	clock.install(b)
+
+	for i := 0; i < 60; i++ {
+		assert.NoError(t, b.wait(ctx, 1))
+	}
+	assert.Empty(t, clock.slept)
+
+	// a token is refilled every second
+	assert.NoError(t, b.wait(ctx, 1))
+	assert.Equal(t, []time.Duration{time.Second}, clock.slept)
+
+	// usage reported by binance includes requests of other clients
+	clock.now = clock.now.Add(time.Minute)
+	b.used(58)
+	clock.slept = nil
+	assert.NoError(t, b.wait(ctx, 1))
+	assert.NoError(t, b.wait(ctx, 1))
+	assert.Empty(t, clock.slept)
+	assert.NoError(t, b.wait(ctx, 1))
+	assert.Equal(t, []time.Duration{time.Second}, clock.slept)
+
+	clock.now = clock.now.Add(time.Minute)
+	clock.slept = nil
+	b.pause(30 * time.Second)
+	b.pause(10 * time.Second) // shorter pauses don't shorten the current one
+	assert.NoError(t, b.wait(ctx, 1))
+	assert.Equal(t, 30*time.Second, sum(clock.slept))
+
+	ctxCanceled, cancel := context.WithCancel(ctx)
+	cancel()
+	b.sleep = sleepCtx
+	b.pause(time.Hour)
+	assert.ErrorIs(t, b.wait(ctxCanceled, 1), context.Canceled)
+}
+
+func sum(ds []time.Duration) time.Duration {
+	total := time.Duration(0)
+	for _, d := range ds {
+		total += d
+	}
+	return total
+}
+
+func TestClientPool(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-MBX-USED-WEIGHT-1M", "2400")
+		if status != http.StatusOK {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(status)
+			w.Write([]byte(`{"code":-1003,"msg":"Too many requests"}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	pool := NewClientPool()
+	a := pool.client("a", "secret", srv.URL)
+	assert.Same(t, a, pool.client("a", "secret", srv.URL))
+
+	b := pool.client("b", "secret", srv.URL)
+	assert.NotSame(t, a, b)
+
+	// clients of the same endpoint share the weight limit, the order limit is per API key
+	assert.Len(t, pool.weights, 1)
+	assert.Len(t, pool.orders, 2)
+
+	clock := &fakeClock{now: time.Now()}
+	clock.install(pool.weights[srv.URL])
+	ctx := context.Background()
+
+	// a used up weight reported to one client throttles the other
+	assert.NoError(t, a.NewPingService().Do(ctx))
+	assert.Empty(t, clock.slept)
+	assert.NoError(t, b.NewPingService().Do(ctx))
+	assert.Len(t, clock.slept, 1)
+	assert.InDelta(t, float64(time.Minute/2400), float64(clock.slept[0]), float64(time.Millisecond))
+
+	for _, code := range []int{http.StatusTooManyRequests, http.StatusTeapot} {
+		status = code
+		assert.Error(t, a.NewPingService().Do(ctx))
+
+		status = http.StatusOK
+		clock.slept = nil
+		assert.NoError(t, b.NewPingService().Do(ctx))
+		assert.Equal(t, 7*time.Second, clock.slept[0])
+	}
+}
+
+func TestIsOrderRequest(t *testing.T) {
+	for _, tt := range []struct {
+		method, path string
+		want         bool
+	}{
+		{http.MethodPost, "/fapi/v1/order", true},
+		{http.MethodPut, "/fapi/v1/order", true},
+		{http.MethodPost, "/fapi/v1/batchOrders", true},
+		{http.MethodGet, "/fapi/v1/order", false},
+		{http.MethodDelete, "/fapi/v1/order", false},
+		{http.MethodPost, "/fapi/v1/listenKey", false},
+	} {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		assert.Equal(t, tt.want, isOrderRequest(req), "%s %s", tt.method, tt.path)
+	}
+}
+
+func TestRequestWeight(t *testing.T) {
+	for _, tt := range []struct {
+		method, path string
+		want         float64
+	}{
+		{http.MethodPost, "/fapi/v1/batchOrders", 5},
+		{http.MethodPut, "/fapi/v1/batchOrders", 5},
+		{http.MethodGet, "/fapi/v1/positionSide/dual", 30},
+		{http.MethodPost, "/fapi/v1/order", 1},
+		{http.MethodGet, "/fapi/v1/ping", 1},
+	} {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		assert.Equal(t, tt.want, requestWeight(req), "%s %s", tt.method, tt.path)
+	}
+}
+
+func TestLimitedTransport_Weight(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	weight := newBucket(weightLimitPerMinute)
+	clock := &fakeClock{now: time.Now()}
+	clock.install(weight)
+	client := &http.Client{Transport: &limitedTransport{next: http.DefaultTransport, weight: weight, orders: newBucket(orderLimitPerMinute)}}
+
+	// a batch takes the tokens of its weight before it's sent
+	resp, err := client.Post(srv.URL+"/fapi/v1/batchOrders", "", nil)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, float64(weightLimitPerMinute-5), weight.tokens)
+}
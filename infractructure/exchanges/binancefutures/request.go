@@ -0,0 +1,65 @@
+package binancefutures
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/H3Cki/go-binance/v2/common"
+)
+
+// signedRequest sends a form signed with the client's credentials through the client's http client and returns the response body,
+// it's used where the library's services don't format the values exactly.
+func (e *Exchange) signedRequest(ctx context.Context, method, path string, form url.Values) ([]byte, error) {
+	body := form.Encode()
+
+	query := url.Values{}
+	query.Set("timestamp", strconv.FormatInt(time.Now().UnixMilli()-e.client.TimeOffset, 10))
+	queryString := query.Encode()
+
+	mac := hmac.New(sha256.New, []byte(e.client.SecretKey))
+	mac.Write([]byte(queryString + body))
+	queryString += "&signature=" + hex.EncodeToString(mac.Sum(nil))
+
+	req, err := http.NewRequestWithContext(ctx, method, e.client.BaseURL+path+"?"+queryString, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-MBX-APIKEY", e.client.APIKey)
+
+	httpClient := e.client.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiErr := &common.APIError{}
+		if err := json.Unmarshal(data, apiErr); err != nil {
+			return nil, fmt.Errorf("%s %s status %d: %s", method, path, resp.StatusCode, data)
+		}
+		return nil, apiErr
+	}
+
+	return data, nil
+}
@@ -0,0 +1,96 @@
+package binancefutures
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// dirLoader is a FileLoader shared by exchanges like the exchange info directory
+type dirLoader struct {
+	mu    sync.Mutex
+	files map[string]ExchangeInfo
+}
+
+func (l *dirLoader) Exists(name string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, ok := l.files[name]
+	return ok
+}
+
+func (l *dirLoader) Save(name string, data ExchangeInfo) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.files[name] = data
+	return nil
+}
+
+func (l *dirLoader) Read(name string) (ExchangeInfo, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	ei, ok := l.files[name]
+	if !ok {
+		return ExchangeInfo{}, os.ErrNotExist
+	}
+	return ei, nil
+}
+
+// eiServer serves an exchange info with a single BTCUSDT symbol of the tick size
+func eiServer(tickSize string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/fapi/v1/exchangeInfo" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"serverTime":9999999999999,"symbols":[{"symbol":"BTCUSDT","filters":[{"filterType":"PRICE_FILTER","tickSize":"%s"}]}]}`, tickSize)
+	}))
+}
+
+func TestExchange_Testnet(t *testing.T) {
+	loader := &dirLoader{files: map[string]ExchangeInfo{}}
+	logger := zap.NewNop().Sugar()
+
+	pool := NewClientPool()
+
+	mainnet := New(logger, Config{ExchangeInfoer: loader, ClientPool: pool, UserConfig: UserConfig{API_KEY: "key", SECRET_KEY: "secret"}})
+	testnet := New(logger, Config{ExchangeInfoer: loader, ClientPool: pool, UserConfig: UserConfig{API_KEY: "key", SECRET_KEY: "secret", Testnet: true}})
+
+	// creating the testnet exchange must not switch the mainnet one
+	assert.Equal(t, baseURL, mainnet.client.BaseURL)
+	assert.Equal(t, wsURL, mainnet.wsURL)
+	assert.Equal(t, testnetBaseURL, testnet.client.BaseURL)
+	assert.Equal(t, wsTestnetURL, testnet.wsURL)
+
+	mainSrv, testSrv := eiServer("0.10"), eiServer("0.01")
+	defer mainSrv.Close()
+	defer testSrv.Close()
+	mainnet.client.BaseURL = mainSrv.URL
+	testnet.client.BaseURL = testSrv.URL
+
+	wg := sync.WaitGroup{}
+	for ex, tickSize := range map[*Exchange]string{mainnet: "0.10", testnet: "0.01"} {
+		ex, tickSize := ex, tickSize
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				s, err := ex.symbol(context.Background(), "BTCUSDT")
+				assert.NoError(t, err)
+				assert.Equal(t, tickSize, s.PriceFilter().TickSize)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, loader.files, 2)
+	assert.Equal(t, "0.10", loader.files[eiFileName].Symbols[0].PriceFilter().TickSize)
+	assert.Equal(t, "0.01", loader.files["testnet_"+eiFileName].Symbols[0].PriceFilter().TickSize)
+}
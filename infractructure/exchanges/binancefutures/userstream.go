@@ -0,0 +1,250 @@
+package binancefutures
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/Plotrader/core/outbound"
+	"github.com/H3Cki/go-binance/v2/futures"
+	"github.com/gorilla/websocket"
+)
+
+var (
+	wsURL        = "wss://fstream.binance.com/ws"
+	wsTestnetURL = "wss://stream.binancefuture.com/ws"
+
+	// listen keys expire after 60 minutes without a keepalive
+	listenKeyKeepalive = 30 * time.Minute
+	wsReconnectDelay   = 5 * time.Second
+
+	errListenKeyExpired = errors.New("listen key expired")
+)
+
+// StreamEvents implements outbound.EventStreamer with the user data stream,
+// it reconnects with a new listen key whenever the connection drops or the key expires.
+func (e *Exchange) StreamEvents(ctx context.Context) (<-chan outbound.ExchangeEvent, error) {
+	conn, listenKey, err := e.connectUserStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan outbound.ExchangeEvent)
+	go e.streamUserData(ctx, conn, listenKey, events)
+
+	return events, nil
+}
+
+func (e *Exchange) connectUserStream(ctx context.Context) (*websocket.Conn, string, error) {
+	listenKey, err := e.client.NewStartUserStreamService().Do(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("error starting user stream: %w", err)
+	}
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, e.wsURL+"/"+listenKey, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("error connecting to user stream: %w", err)
+	}
+
+	return conn, listenKey, nil
+}
+
+func (e *Exchange) streamUserData(ctx context.Context, conn *websocket.Conn, listenKey string, events chan<- outbound.ExchangeEvent) {
+	defer close(events)
+
+	for {
+		err := e.readUserStream(ctx, conn, listenKey, events)
+		conn.Close()
+		if ctx.Err() != nil {
+			return
+		}
+		e.logger.Errorf("user stream disconnected: %v", err)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wsReconnectDelay):
+			}
+
+			conn, listenKey, err = e.connectUserStream(ctx)
+			if err == nil {
+				break
+			}
+			e.logger.Errorf("error reconnecting user stream: %v", err)
+		}
+	}
+}
+
+// readUserStream forwards the events until the connection fails, the listen key expires or ctx is done
+func (e *Exchange) readUserStream(ctx context.Context, conn *websocket.Conn, listenKey string, events chan<- outbound.ExchangeEvent) error {
+	messages := make(chan []byte)
+	readErr := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				readErr <- err
+				return
+			}
+			select {
+			case messages <- msg:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	keepalive := time.NewTicker(listenKeyKeepalive)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-readErr:
+			return err
+		case <-keepalive.C:
+			if err := e.client.NewKeepaliveUserStreamService().ListenKey(listenKey).Do(ctx); err != nil {
+				e.logger.Errorf("error keeping user stream alive: %v", err)
+			}
+		case msg := <-messages:
+			event, err := parseUserDataEvent(msg)
+			if errors.Is(err, errListenKeyExpired) {
+				return err
+			}
+			if err != nil {
+				e.logger.Errorf("error parsing user stream event: %v", err)
+				continue
+			}
+			if event == nil {
+				continue
+			}
+			select {
+			case events <- *event:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// parseUserDataEvent returns nil if the event is not relevant
+func parseUserDataEvent(msg []byte) (*outbound.ExchangeEvent, error) {
+	ude := futures.WsUserDataEvent{}
+	if err := json.Unmarshal(msg, &ude); err != nil {
+		return nil, err
+	}
+
+	switch ude.Event {
+	case futures.UserDataEventTypeListenKeyExpired:
+		return nil, errListenKeyExpired
+	case futures.UserDataEventTypeOrderTradeUpdate:
+		eo, err := tradeUpdateToOrder(ude.OrderTradeUpdate)
+		if err != nil {
+			return nil, err
+		}
+		return &outbound.ExchangeEvent{Time: time.UnixMilli(ude.Time), Order: eo}, nil
+	case futures.UserDataEventTypeAccountUpdate:
+		update, err := accountUpdate(ude.AccountUpdate)
+		if err != nil {
+			return nil, err
+		}
+		return &outbound.ExchangeEvent{Time: time.UnixMilli(ude.Time), Account: update}, nil
+	}
+
+	return nil, nil
+}
+
+func tradeUpdateToOrder(u futures.WsOrderTradeUpdate) (*domain.ExchangeOrder, error) {
+	status, price, quantity, err := statusPriceQuantity(u.Status, u.OriginalPrice, u.OriginalQty)
+	if err != nil {
+		return nil, err
+	}
+
+	fillPrice, err := parseOptionalPrice(u.AveragePrice)
+	if err != nil {
+		return nil, err
+	}
+
+	stopPrice, err := parseOptionalPrice(u.StopPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	// triggered stop orders are reported with the type they were executed as
+	orderType := u.Type
+	if u.OriginalType != "" {
+		orderType = u.OriginalType
+	}
+
+	return &domain.ExchangeOrder{
+		ID:           u.ID,
+		ClientID:     u.ClientOrderID,
+		Status:       status,
+		Type:         string(orderType),
+		Side:         string(u.Side),
+		PositionSide: string(u.PositionSide),
+		Symbol:       u.Symbol,
+		Price:        price,
+		StopPrice:    stopPrice,
+		BaseQuantity: quantity,
+		FillPrice:    fillPrice,
+
+		ReduceOnly:    u.IsReduceOnly,
+		ClosePosition: u.IsClosingPosition,
+		TimeInForce:   string(u.TimeInForce),
+		WorkingType:   string(u.WorkingType),
+	}, nil
+}
+
+func accountUpdate(u futures.WsAccountUpdate) (*outbound.AccountUpdate, error) {
+	update := &outbound.AccountUpdate{}
+
+	for _, b := range u.Balances {
+		wallet, err := strconv.ParseFloat(b.Balance, 64)
+		if err != nil {
+			return nil, err
+		}
+		update.Balances = append(update.Balances, domain.Balance{
+			Asset:  b.Asset,
+			Wallet: wallet,
+		})
+	}
+
+	for _, p := range u.Positions {
+		amount, err := strconv.ParseFloat(p.Amount, 64)
+		if err != nil {
+			return nil, err
+		}
+		entryPrice, err := parseOptionalPrice(p.EntryPrice)
+		if err != nil {
+			return nil, err
+		}
+		update.Positions = append(update.Positions, domain.Position{
+			Symbol:       p.Symbol,
+			PositionSide: toDomainPositionSide(p.Side),
+			Amount:       amount,
+			EntryPrice:   entryPrice,
+		})
+	}
+
+	return update, nil
+}
+
+func toDomainPositionSide(ps futures.PositionSideType) domain.PositionSide {
+	switch ps {
+	case futures.PositionSideTypeLong:
+		return domain.PositionSideLong
+	case futures.PositionSideTypeShort:
+		return domain.PositionSideShort
+	}
+	return ""
+}
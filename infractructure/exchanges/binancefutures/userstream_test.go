@@ -0,0 +1,137 @@
+package binancefutures
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/Plotrader/core/outbound"
+	"github.com/H3Cki/go-binance/v2/futures"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// recorded user data stream events, every connection replays one of them
+var recordedUserStreams = [][]string{
+	{
+		`{"e":"ACCOUNT_CONFIG_UPDATE","E":1611646737479,"T":1611646737476,"ac":{"s":"BTCUSDT","l":25}}`,
+		`{"e":"ORDER_TRADE_UPDATE","E":1568879465651,"T":1568879465650,"o":{"s":"BTCUSDT","c":"TEST","S":"SELL","o":"MARKET","f":"GTC","q":"0.001","p":"0","ap":"9100.5","sp":"9100","x":"TRADE","X":"FILLED","i":8886774,"l":"0.001","z":"0.001","L":"9100.5","T":1568879465650,"t":0,"b":"0","a":"9.91","m":false,"R":true,"wt":"MARK_PRICE","ot":"STOP_MARKET","ps":"LONG","cp":false,"rp":"0"}}`,
+		`{"e":"ACCOUNT_UPDATE","E":1564745798939,"T":1564745798938,"a":{"m":"ORDER","B":[{"a":"USDT","wb":"122624.12345678","cw":"100.12345678","bc":"50.12345678"}],"P":[{"s":"BTCUSDT","pa":"0","ep":"0.00000","cr":"200","up":"0","mt":"isolated","iw":"0.00000000","ps":"LONG"},{"s":"BTCUSDT","pa":"-20","ep":"6563.66500","cr":"0","up":"2850.21200000","mt":"isolated","iw":"13200.70726908","ps":"SHORT"}]}}`,
+		`{"e":"listenKeyExpired","E":1576653824250}`,
+	},
+	{
+		`{"e":"ORDER_TRADE_UPDATE","E":1568879466651,"T":1568879466650,"o":{"s":"BTCUSDT","c":"TEST2","S":"BUY","o":"LIMIT","f":"GTC","q":"0.002","p":"9000","ap":"0","sp":"0","x":"NEW","X":"NEW","i":8886775,"l":"0","z":"0","L":"0","T":1568879466650,"t":0,"b":"0","a":"0","m":false,"R":false,"wt":"CONTRACT_PRICE","ot":"LIMIT","ps":"LONG","cp":false,"rp":"0"}}`,
+	},
+}
+
+func newUserStreamServer(t *testing.T) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	connections := atomic.Int32{}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/fapi/v1/listenKey":
+			w.Write([]byte(`{"listenKey":"key"}`))
+		case strings.HasPrefix(r.URL.Path, "/ws/key"):
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer conn.Close()
+
+			idx := int(connections.Add(1)) - 1
+			if idx >= len(recordedUserStreams) {
+				return
+			}
+			for _, msg := range recordedUserStreams[idx] {
+				if err := conn.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+					return
+				}
+			}
+
+			// keep the connection open until the client closes it
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestExchange_StreamEvents(t *testing.T) {
+	srv := newUserStreamServer(t)
+	defer srv.Close()
+
+	reconnectDelay := wsReconnectDelay
+	wsReconnectDelay = 10 * time.Millisecond
+	defer func() { wsReconnectDelay = reconnectDelay }()
+
+	client := futures.NewClient("key", "secret")
+	client.BaseURL = srv.URL
+	e := &Exchange{
+		logger: zap.NewNop().Sugar(),
+		client: client,
+		wsURL:  "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws",
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := e.StreamEvents(ctx)
+	assert.NoError(t, err)
+
+	received := []outbound.ExchangeEvent{}
+	for len(received) < 3 {
+		select {
+		case event := <-events:
+			received = append(received, event)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for events, got %d", len(received))
+		}
+	}
+
+	assert.Equal(t, &domain.ExchangeOrder{
+		ID:           int64(8886774),
+		ClientID:     "TEST",
+		Status:       domain.OrderStatusDone,
+		Type:         "STOP_MARKET",
+		Side:         "SELL",
+		PositionSide: "LONG",
+		Symbol:       "BTCUSDT",
+		StopPrice:    9100,
+		BaseQuantity: 0.001,
+		FillPrice:    9100.5,
+		ReduceOnly:   true,
+		TimeInForce:  "GTC",
+		WorkingType:  "MARK_PRICE",
+	}, received[0].Order)
+	assert.Equal(t, time.UnixMilli(1568879465651), received[0].Time)
+
+	assert.Equal(t, &outbound.AccountUpdate{
+		Balances: []domain.Balance{{Asset: "USDT", Wallet: 122624.12345678}},
+		Positions: []domain.Position{
+			{Symbol: "BTCUSDT", PositionSide: domain.PositionSideLong},
+			{Symbol: "BTCUSDT", PositionSide: domain.PositionSideShort, Amount: -20, EntryPrice: 6563.665},
+		},
+	}, received[1].Account)
+
+	// the expired listen key is replaced by a new connection
+	assert.Equal(t, int64(8886775), received[2].Order.ID)
+	assert.Equal(t, domain.OrderStatusActive, received[2].Order.Status)
+	// the client order ID matches the updates to the placements of the follow's orders
+	assert.Equal(t, "TEST2", received[2].Order.ClientID)
+
+	cancel()
+	for range events {
+	}
+}
@@ -14,6 +14,17 @@ func pairToSymbol(p domain.Pair) string {
 	return p.Base + p.Quote
 }
 
+// orderID returns the ID of the exchange order, IDs of orders decoded from JSON are float64
+func orderID(id any) (int64, error) {
+	switch v := id.(type) {
+	case int64:
+		return v, nil
+	case float64:
+		return int64(v), nil
+	}
+	return 0, fmt.Errorf("invalid order id: %v", id)
+}
+
 func orderSide(side domain.OrderSide) (futures.SideType, error) {
 	switch side {
 	case domain.OrderSideBuy:
@@ -33,24 +44,54 @@ func orderType(side domain.OrderType) (futures.OrderType, error) {
 		return futures.OrderTypeStopMarket, nil
 	case domain.OrderTypeTakeProfit:
 		return futures.OrderTypeTakeProfitMarket, nil
+	case domain.OrderTypeStopLossLimit:
+		return futures.OrderTypeStop, nil
+	case domain.OrderTypeTakeProfitLimit:
+		return futures.OrderTypeTakeProfit, nil
 	}
 	return "", fmt.Errorf("unsupported position side: %s", side)
 }
 
+func timeInForce(tif domain.TimeInForce) futures.TimeInForceType {
+	if tif == "" {
+		return futures.TimeInForceTypeGTC
+	}
+	return futures.TimeInForceType(tif)
+}
+
 func orderToOrder(order *futures.Order) (*domain.ExchangeOrder, error) {
 	status, price, quantity, err := statusPriceQuantity(order.Status, order.Price, order.OrigQuantity)
 	if err != nil {
 		return nil, err
 	}
 
+	fillPrice, err := parseOptionalPrice(order.AvgPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	stopPrice, err := parseOptionalPrice(order.StopPrice)
+	if err != nil {
+		return nil, err
+	}
+
 	return &domain.ExchangeOrder{
 		ID:           order.OrderID,
+		ClientID:     order.ClientOrderID,
 		Status:       status,
 		Type:         string(order.Type),
 		Side:         string(order.Side),
+		PositionSide: string(order.PositionSide),
 		Symbol:       order.Symbol,
 		Price:        price,
+		StopPrice:    stopPrice,
 		BaseQuantity: quantity,
+		FillPrice:    fillPrice,
+
+		ReduceOnly:    order.ReduceOnly,
+		ClosePosition: order.ClosePosition,
+		TimeInForce:   string(order.TimeInForce),
+		WorkingType:   string(order.WorkingType),
 	}, nil
 }
 
@@ -60,14 +101,33 @@ func createRespToOrder(resp *futures.CreateOrderResponse) (*domain.ExchangeOrder
 		return nil, err
 	}
 
+	fillPrice, err := parseOptionalPrice(resp.AvgPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	stopPrice, err := parseOptionalPrice(resp.StopPrice)
+	if err != nil {
+		return nil, err
+	}
+
 	return &domain.ExchangeOrder{
 		ID:           resp.OrderID,
+		ClientID:     resp.ClientOrderID,
 		Status:       status,
 		Type:         string(resp.Type),
 		Side:         string(resp.Side),
+		PositionSide: string(resp.PositionSide),
 		Symbol:       resp.Symbol,
 		Price:        price,
+		StopPrice:    stopPrice,
 		BaseQuantity: quantity,
+		FillPrice:    fillPrice,
+
+		ReduceOnly:    resp.ReduceOnly,
+		ClosePosition: resp.ClosePosition,
+		TimeInForce:   string(resp.TimeInForce),
+		WorkingType:   string(resp.WorkingType),
 	}, nil
 }
 
@@ -77,14 +137,26 @@ func cancelRespToOrder(resp *futures.CancelOrderResponse) (*domain.ExchangeOrder
 		return nil, err
 	}
 
+	stopPrice, err := parseOptionalPrice(resp.StopPrice)
+	if err != nil {
+		return nil, err
+	}
+
 	return &domain.ExchangeOrder{
 		ID:           resp.OrderID,
+		ClientID:     resp.ClientOrderID,
 		Status:       status,
 		Type:         string(resp.Type),
 		Side:         string(resp.Side),
+		PositionSide: string(resp.PositionSide),
 		Symbol:       resp.Symbol,
 		Price:        price,
+		StopPrice:    stopPrice,
 		BaseQuantity: quantity,
+
+		ReduceOnly:  resp.ReduceOnly,
+		TimeInForce: string(resp.TimeInForce),
+		WorkingType: string(resp.WorkingType),
 	}, nil
 }
 
@@ -94,14 +166,33 @@ func modifyRespToOrder(resp *futures.ModifyOrderResponse) (*domain.ExchangeOrder
 		return nil, err
 	}
 
+	fillPrice, err := parseOptionalPrice(resp.AvgPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	stopPrice, err := parseOptionalPrice(resp.StopPrice)
+	if err != nil {
+		return nil, err
+	}
+
 	return &domain.ExchangeOrder{
 		ID:           resp.OrderID,
+		ClientID:     resp.ClientOrderID,
 		Status:       status,
 		Type:         string(resp.Type),
 		Side:         string(resp.Side),
+		PositionSide: string(resp.PositionSide),
 		Symbol:       resp.Symbol,
 		Price:        price,
+		StopPrice:    stopPrice,
 		BaseQuantity: quantity,
+		FillPrice:    fillPrice,
+
+		ReduceOnly:    resp.ReduceOnly,
+		ClosePosition: resp.ClosePosition,
+		TimeInForce:   string(resp.TimeInForce),
+		WorkingType:   string(resp.WorkingType),
 	}, nil
 }
 
@@ -124,6 +215,15 @@ func statusPriceQuantity(status futures.OrderStatusType, price, quantity string)
 	return s, p, q, nil
 }
 
+// parseOptionalPrice parses prices that are not set for every order, like the average fill price or the stop price
+func parseOptionalPrice(price string) (float64, error) {
+	if price == "" {
+		return 0, nil
+	}
+
+	return strconv.ParseFloat(price, 64)
+}
+
 func toDomainOrderStatus(status futures.OrderStatusType) (domain.OrderStatus, error) {
 	switch status {
 	case futures.OrderStatusTypeNew, futures.OrderStatusTypePartiallyFilled:
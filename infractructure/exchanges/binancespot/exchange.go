@@ -0,0 +1,336 @@
+package binancespot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/Plotrader/core/outbound"
+	"github.com/H3Cki/Plotrader/infractructure/exchanges/decimal"
+	"github.com/H3Cki/go-binance/v2"
+	"go.uber.org/zap"
+)
+
+var (
+	eiFileName = "binancespot_ei.json"
+	maxEiAge   = 24 * time.Hour
+)
+
+// baseURL and testnetBaseURL are set per client, the library's UseTestnet switches every client in the process
+const (
+	baseURL        = "https://api.binance.com"
+	testnetBaseURL = "https://testnet.binance.vision"
+)
+
+type UserConfig struct {
+	Testnet    bool   `json:"testnet"`
+	API_KEY    string `json:"API_KEY" validate:"required"`
+	SECRET_KEY string `json:"SECRET_KEY" validate:"required"`
+}
+
+type Config struct {
+	ExchangeInfoer outbound.FileLoader[ExchangeInfo]
+	UserConfig     UserConfig
+}
+
+type Exchange struct {
+	logger *zap.SugaredLogger
+	client *binance.Client
+	cache  *infoCache
+	// testnet selects the testnet endpoints and exchange info cache of this client only
+	testnet bool
+}
+
+type ExchangeInfo binance.ExchangeInfo
+
+func New(logger *zap.SugaredLogger, cfg Config) *Exchange {
+	e := &Exchange{
+		logger:  logger,
+		client:  binance.NewClient(cfg.UserConfig.API_KEY, cfg.UserConfig.SECRET_KEY),
+		testnet: cfg.UserConfig.Testnet,
+	}
+	e.client.BaseURL = baseURL
+	if e.testnet {
+		e.client.BaseURL = testnetBaseURL
+	}
+	e.cache = infoCaches.Get(e.client.BaseURL, infoCacheConfig(logger, e.client, cfg.ExchangeInfoer, e.eiFn()))
+	return e
+}
+
+// eiFn returns the name of the exchange info file, testnet symbols are cached separately
+func (e *Exchange) eiFn() string {
+	if e.testnet {
+		return "testnet_" + eiFileName
+	}
+	return eiFileName
+}
+
+func (e *Exchange) Init(ctx context.Context) error {
+	if err := e.client.NewPingService().Do(ctx); err != nil {
+		return err
+	}
+	return e.cache.Load(ctx)
+}
+
+func (e *Exchange) GetOrder(ctx context.Context, req outbound.GetExchangeOrderRequest) (*domain.ExchangeOrder, error) {
+	eo := req.EO
+	if domain.OrderType(eo.Type) == domain.OrderTypeOCO {
+		return e.getOCO(ctx, eo)
+	}
+
+	id, err := orderID(eo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := e.client.NewGetOrderService().OrderID(id).Symbol(eo.Symbol).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return orderToOrder(order)
+}
+
+func (e *Exchange) CreateOrder(ctx context.Context, req outbound.CreateExchangeOrderRequest) (*domain.ExchangeOrder, error) {
+	if req.ReduceOnly || req.ClosePosition || req.WorkingType != "" {
+		return nil, fmt.Errorf("reduceOnly, closePosition and workingType: %w", outbound.ErrUnsupported)
+	}
+
+	symbol, err := e.cache.Symbol(ctx, pairToSymbol(req.Pair))
+	if err != nil {
+		return nil, err
+	}
+
+	side, err := orderSide(req.Side)
+	if err != nil {
+		return nil, err
+	}
+
+	tif, err := timeInForce(req.TimeInForce)
+	if err != nil {
+		return nil, err
+	}
+
+	ov := orderValues{
+		symbol:         symbol,
+		side:           side,
+		orderType:      req.Type,
+		price:          decimal.FromFloat(req.Price),
+		stopPrice:      decimal.FromFloat(req.StopPrice),
+		stopLimitPrice: decimal.FromFloat(req.StopLimitPrice),
+		baseQuantity:   decimal.FromFloat(req.BaseQuantity),
+		timeInForce:    tif,
+		clientOrderID:  req.ClientOrderID,
+	}
+
+	return e.createOrder(ctx, ov)
+}
+
+// ModifyOrder cancels the order and creates a new one with the new values, spot orders can't be modified
+func (e *Exchange) ModifyOrder(ctx context.Context, req outbound.ModifyExchangeOrderRequest) (*domain.ExchangeOrder, error) {
+	eo := req.EO
+
+	symbol, err := e.cache.Symbol(ctx, eo.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	tif, err := timeInForce(domain.TimeInForce(eo.TimeInForce))
+	if err != nil {
+		return nil, err
+	}
+
+	ov := orderValues{
+		symbol:         symbol,
+		side:           binance.SideType(eo.Side),
+		orderType:      domain.OrderType(eo.Type),
+		price:          decimal.FromFloat(req.Price),
+		stopPrice:      decimal.FromFloat(req.StopPrice),
+		stopLimitPrice: decimal.FromFloat(req.StopLimitPrice),
+		baseQuantity:   decimal.FromFloat(req.BaseQuantity),
+		timeInForce:    tif,
+		clientOrderID:  req.ClientOrderID,
+	}
+
+	if err := applyFilters(&ov); err != nil {
+		return nil, err
+	}
+
+	if ov.price.Equal(decimal.FromFloat(eo.Price)) &&
+		ov.stopPrice.Equal(decimal.FromFloat(eo.StopPrice)) &&
+		ov.stopLimitPrice.Equal(decimal.FromFloat(eo.StopLimitPrice)) &&
+		ov.baseQuantity.Equal(decimal.FromFloat(eo.BaseQuantity)) {
+		e.logger.Debugf("ignoring modification of order %v, prev=%f, new=%s", eo.ID, eo.Price, ov.price)
+		return eo, nil
+	}
+
+	if _, err := e.CancelOrder(ctx, outbound.CancelExchangeOrdersRequest{EO: eo}); err != nil {
+		return nil, err
+	}
+
+	return e.createOrder(ctx, ov)
+}
+
+func (e *Exchange) CancelOrder(ctx context.Context, req outbound.CancelExchangeOrdersRequest) (*domain.ExchangeOrder, error) {
+	eo := req.EO
+	if domain.OrderType(eo.Type) == domain.OrderTypeOCO {
+		return e.cancelOCO(ctx, eo)
+	}
+
+	id, err := orderID(eo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := e.client.NewCancelOrderService().OrderID(id).Symbol(eo.Symbol).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return cancelRespToOrder(resp)
+}
+
+// MarkPrice returns the last price of the pair, spot markets have no mark price
+func (e *Exchange) MarkPrice(ctx context.Context, req outbound.MarkPriceRequest) (float64, error) {
+	prices, err := e.client.NewListPricesService().Symbol(pairToSymbol(req.Pair)).Do(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(prices) == 0 {
+		return 0, fmt.Errorf("no price for symbol %s", pairToSymbol(req.Pair))
+	}
+	return strconv.ParseFloat(prices[0].Price, 64)
+}
+
+func (e *Exchange) createOrder(ctx context.Context, ov orderValues) (*domain.ExchangeOrder, error) {
+	if err := applyFilters(&ov); err != nil {
+		return nil, fmt.Errorf("filter error: %w", err)
+	}
+
+	switch ov.orderType {
+	case domain.OrderTypeLimit:
+		return e.createLimit(ctx, ov)
+	case domain.OrderTypeStopLossLimit, domain.OrderTypeTakeProfitLimit:
+		return e.createStopLimit(ctx, ov)
+	case domain.OrderTypeOCO:
+		return e.createOCO(ctx, ov)
+	}
+
+	return nil, fmt.Errorf("unsupported order type %s", ov.orderType)
+}
+
+func (e *Exchange) createLimit(ctx context.Context, ov orderValues) (*domain.ExchangeOrder, error) {
+	svc := e.client.NewCreateOrderService().
+		Symbol(ov.symbol.Symbol).
+		Side(ov.side).
+		Type(binance.OrderTypeLimit).
+		TimeInForce(ov.timeInForce).
+		Quantity(ov.baseQuantity.String()).
+		Price(ov.price.String())
+	if ov.clientOrderID != "" {
+		svc = svc.NewClientOrderID(ov.clientOrderID)
+	}
+
+	resp, err := svc.Do(ctx)
+	if err != nil {
+		return e.placedOrder(ctx, ov.symbol.Symbol, ov.clientOrderID, err)
+	}
+
+	return createRespToOrder(resp, 0)
+}
+
+// createStopLimit creates STOP_LOSS_LIMIT and TAKE_PROFIT_LIMIT orders
+func (e *Exchange) createStopLimit(ctx context.Context, ov orderValues) (*domain.ExchangeOrder, error) {
+	svc := e.client.NewCreateOrderService().
+		Symbol(ov.symbol.Symbol).
+		Side(ov.side).
+		Type(binance.OrderType(ov.orderType)).
+		TimeInForce(ov.timeInForce).
+		Quantity(ov.baseQuantity.String()).
+		Price(ov.price.String()).
+		StopPrice(ov.stopPrice.String())
+	if ov.clientOrderID != "" {
+		svc = svc.NewClientOrderID(ov.clientOrderID)
+	}
+
+	resp, err := svc.Do(ctx)
+	if err != nil {
+		return e.placedOrder(ctx, ov.symbol.Symbol, ov.clientOrderID, err)
+	}
+
+	return createRespToOrder(resp, ov.stopPrice.Float64())
+}
+
+func (e *Exchange) createOCO(ctx context.Context, ov orderValues) (*domain.ExchangeOrder, error) {
+	svc := e.client.NewCreateOCOService().
+		Symbol(ov.symbol.Symbol).
+		Side(ov.side).
+		Quantity(ov.baseQuantity.String()).
+		Price(ov.price.String()).
+		StopPrice(ov.stopPrice.String()).
+		StopLimitPrice(ov.stopLimitPrice.String()).
+		StopLimitTimeInForce(ov.timeInForce)
+	if ov.clientOrderID != "" {
+		svc = svc.ListClientOrderID(ov.clientOrderID).
+			LimitClientOrderID(limitClientOrderID(ov.clientOrderID)).
+			StopClientOrderID(stopClientOrderID(ov.clientOrderID))
+	}
+
+	resp, err := svc.Do(ctx)
+	if err != nil {
+		return e.placedOCO(ctx, ov.symbol.Symbol, ov.clientOrderID, err)
+	}
+
+	eo, err := ocoReportsToOrder(resp.OrderListID, resp.Symbol, resp.OrderReports)
+	if err != nil {
+		return nil, err
+	}
+	eo.ClientID = resp.ListClientOrderID
+	return eo, nil
+}
+
+// getOCO gets both legs of the OCO order
+func (e *Exchange) getOCO(ctx context.Context, eo domain.ExchangeOrder) (*domain.ExchangeOrder, error) {
+	id, err := parseOCOID(eo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, err := e.client.NewGetOrderService().OrderID(id.limitOrderID).Symbol(eo.Symbol).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stop, err := e.client.NewGetOrderService().OrderID(id.stopOrderID).Symbol(eo.Symbol).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return ocoToOrder(id, limit, stop)
+}
+
+func (e *Exchange) cancelOCO(ctx context.Context, eo *domain.ExchangeOrder) (*domain.ExchangeOrder, error) {
+	id, err := parseOCOID(eo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := e.client.NewCancelOCOService().Symbol(eo.Symbol).OrderListID(id.listID).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return ocoReportsToOrder(resp.OrderListID, resp.Symbol, resp.OrderReports)
+}
+
+type orderValues struct {
+	symbol         binance.Symbol
+	side           binance.SideType
+	orderType      domain.OrderType
+	price          decimal.Decimal
+	stopPrice      decimal.Decimal
+	stopLimitPrice decimal.Decimal
+	baseQuantity   decimal.Decimal
+	timeInForce    binance.TimeInForceType
+	clientOrderID  string
+}
@@ -0,0 +1,136 @@
+package binancespot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/Plotrader/core/outbound"
+	"github.com/H3Cki/Plotrader/infractructure/exchanges/infocache"
+	"github.com/H3Cki/go-binance/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestExchange_OCO(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/exchangeInfo":
+			w.Write([]byte(`{"serverTime":` + "9999999999999" + `,"symbols":[` + sBTCUSDT + `]}`))
+		case "/api/v3/order/oco":
+			// values have the precision of the tick and step sizes
+			assert.Equal(t, "35000.00", r.FormValue("price"))
+			assert.Equal(t, "28000.00", r.FormValue("stopPrice"))
+			assert.Equal(t, "27900.00", r.FormValue("stopLimitPrice"))
+			assert.Equal(t, "GTC", r.FormValue("stopLimitTimeInForce"))
+			assert.Equal(t, "0.50000", r.FormValue("quantity"))
+			w.Write([]byte(`{"orderListId":10,"symbol":"BTCUSDT","orderReports":[
+				{"symbol":"BTCUSDT","orderId":11,"orderListId":10,"price":"27900.00000000","origQty":"0.50000000","executedQty":"0","cummulativeQuoteQty":"0","status":"NEW","timeInForce":"GTC","type":"STOP_LOSS_LIMIT","side":"SELL","stopPrice":"28000.00000000"},
+				{"symbol":"BTCUSDT","orderId":12,"orderListId":10,"price":"35000.00000000","origQty":"0.50000000","executedQty":"0","cummulativeQuoteQty":"0","status":"NEW","timeInForce":"GTC","type":"LIMIT_MAKER","side":"SELL"}
+			]}`))
+		case "/api/v3/order":
+			switch r.FormValue("orderId") {
+			case "12":
+				w.Write([]byte(`{"symbol":"BTCUSDT","orderId":12,"orderListId":10,"price":"35000.00000000","origQty":"0.50000000","executedQty":"0.50000000","cummulativeQuoteQty":"17500.00000000","status":"FILLED","timeInForce":"GTC","type":"LIMIT_MAKER","side":"SELL","stopPrice":"0"}`))
+			case "11":
+				w.Write([]byte(`{"symbol":"BTCUSDT","orderId":11,"orderListId":10,"price":"27900.00000000","origQty":"0.50000000","executedQty":"0","cummulativeQuoteQty":"0","status":"EXPIRED","timeInForce":"GTC","type":"STOP_LOSS_LIMIT","side":"SELL","stopPrice":"28000.00000000"}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	e := newTestExchange(srv.URL)
+	defer e.cache.Stop()
+	ctx := context.Background()
+
+	eo, err := e.CreateOrder(ctx, outbound.CreateExchangeOrderRequest{
+		Pair:           domain.Pair{Base: "BTC", Quote: "USDT"},
+		Type:           domain.OrderTypeOCO,
+		Side:           domain.OrderSideSell,
+		BaseQuantity:   0.5,
+		Price:          35000,
+		StopPrice:      28000,
+		StopLimitPrice: 27900,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.ExchangeOrder{
+		ID:             "10:12:11",
+		Status:         domain.OrderStatusActive,
+		Type:           "OCO",
+		Side:           "SELL",
+		Symbol:         "BTCUSDT",
+		Price:          35000,
+		StopPrice:      28000,
+		StopLimitPrice: 27900,
+		BaseQuantity:   0.5,
+		TimeInForce:    "GTC",
+	}, eo)
+
+	eo, err = e.GetOrder(ctx, outbound.GetExchangeOrderRequest{EO: *eo})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.OrderStatusDone, eo.Status)
+	assert.Equal(t, 35000.0, eo.FillPrice)
+}
+
+func TestExchange_CreateOrder_Unsupported(t *testing.T) {
+	e := &Exchange{}
+	_, err := e.CreateOrder(context.Background(), outbound.CreateExchangeOrderRequest{
+		Type:       domain.OrderTypeLimit,
+		ReduceOnly: true,
+	})
+	assert.ErrorIs(t, err, outbound.ErrUnsupported)
+}
+
+// newTestExchange creates an exchange of the test server with its own exchange info cache
+func newTestExchange(url string) *Exchange {
+	logger := zap.NewNop().Sugar()
+	client := binance.NewClient("key", "secret")
+	client.BaseURL = url
+	return &Exchange{logger: logger, client: client, cache: infocache.New(infoCacheConfig(logger, client, &memoryLoader{}, eiFileName))}
+}
+
+type memoryLoader struct {
+	ei *ExchangeInfo
+}
+
+func (l *memoryLoader) Exists(name string) bool { return l.ei != nil }
+
+func (l *memoryLoader) Save(name string, data ExchangeInfo) error {
+	l.ei = &data
+	return nil
+}
+
+func (l *memoryLoader) Read(name string) (ExchangeInfo, error) {
+	if l.ei == nil {
+		return ExchangeInfo{}, os.ErrNotExist
+	}
+	return *l.ei, nil
+}
+
+func TestNew_Testnet(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+	mainnet := New(logger, Config{ExchangeInfoer: &memoryLoader{}})
+	testnet := New(logger, Config{ExchangeInfoer: &memoryLoader{}, UserConfig: UserConfig{Testnet: true}})
+
+	assert.Equal(t, baseURL, mainnet.client.BaseURL)
+	assert.Equal(t, eiFileName, mainnet.eiFn())
+	assert.Equal(t, testnetBaseURL, testnet.client.BaseURL)
+	assert.Equal(t, "testnet_"+eiFileName, testnet.eiFn())
+}
+
+func TestNew_SharedInfoCache(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+	a := New(logger, Config{ExchangeInfoer: &memoryLoader{}})
+	b := New(logger, Config{ExchangeInfoer: &memoryLoader{}})
+	testnet := New(logger, Config{ExchangeInfoer: &memoryLoader{}, UserConfig: UserConfig{Testnet: true}})
+
+	assert.Same(t, a.cache, b.cache)
+	assert.NotSame(t, a.cache, testnet.cache)
+}
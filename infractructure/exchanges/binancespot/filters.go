@@ -0,0 +1,111 @@
+package binancespot
+
+import (
+	"fmt"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/Plotrader/infractructure/exchanges/binancefilters"
+	"github.com/H3Cki/Plotrader/infractructure/exchanges/decimal"
+)
+
+var orderTypeFilters = map[domain.OrderType]func(*orderValues) error{
+	domain.OrderTypeLimit: func(req *orderValues) error {
+		if err := filterPrices(req, &req.price); err != nil {
+			return err
+		}
+
+		if err := filterQuantity(req); err != nil {
+			return err
+		}
+
+		return filterNotional(req, req.price)
+	},
+	domain.OrderTypeStopLossLimit:   stopLimitOrderFilters,
+	domain.OrderTypeTakeProfitLimit: stopLimitOrderFilters,
+	domain.OrderTypeOCO: func(req *orderValues) error {
+		if err := filterPrices(req, &req.price, &req.stopPrice, &req.stopLimitPrice); err != nil {
+			return err
+		}
+
+		if err := filterQuantity(req); err != nil {
+			return err
+		}
+
+		// either of the legs can be executed
+		if err := filterNotional(req, req.price); err != nil {
+			return err
+		}
+
+		return filterNotional(req, req.stopLimitPrice)
+	},
+}
+
+// stopLimitOrderFilters applies to STOP_LOSS_LIMIT and TAKE_PROFIT_LIMIT orders, which have both a limit price and a stop price
+var stopLimitOrderFilters = func(req *orderValues) error {
+	if err := filterPrices(req, &req.price, &req.stopPrice); err != nil {
+		return err
+	}
+
+	if err := filterQuantity(req); err != nil {
+		return err
+	}
+
+	return filterNotional(req, req.price)
+}
+
+func applyFilters(ov *orderValues) error {
+	filterFunc, ok := orderTypeFilters[ov.orderType]
+	if !ok {
+		return fmt.Errorf("unsupported order type: %v", ov.orderType)
+	}
+
+	return filterFunc(ov)
+}
+
+// filterPrices applies the PRICE_FILTER to the prices
+func filterPrices(req *orderValues, prices ...*decimal.Decimal) error {
+	pf := req.symbol.PriceFilter()
+	if pf == nil {
+		return nil
+	}
+
+	for _, price := range prices {
+		p, err := binancefilters.Price(pf.TickSize, pf.MinPrice, pf.MaxPrice, *price)
+		if err != nil {
+			return err
+		}
+		*price = p
+	}
+
+	return nil
+}
+
+// filterQuantity applies the LOT_SIZE filter to the base quantity
+func filterQuantity(req *orderValues) error {
+	lsf := req.symbol.LotSizeFilter()
+	if lsf == nil {
+		return nil
+	}
+
+	qty, err := binancefilters.LotSize(lsf.StepSize, lsf.MinQuantity, lsf.MaxQuantity, req.baseQuantity)
+	if err != nil {
+		return err
+	}
+
+	req.baseQuantity = qty
+	return nil
+}
+
+// filterNotional applies the NOTIONAL filter to the value of the order at given price
+func filterNotional(req *orderValues, price decimal.Decimal) error {
+	nf := req.symbol.NotionalFilter()
+	if nf == nil {
+		return nil
+	}
+
+	if err := binancefilters.MinNotional(nf.MinNotional, price, req.baseQuantity); err != nil {
+		return err
+	}
+
+	return binancefilters.MaxNotional(nf.MaxNotional, price, req.baseQuantity)
+}
@@ -0,0 +1,86 @@
+package binancespot
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/Plotrader/infractructure/exchanges/decimal"
+	"github.com/H3Cki/go-binance/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+var sBTCUSDT = `
+	{
+		"symbol": "BTCUSDT",
+		"status": "TRADING",
+		"baseAsset": "BTC",
+		"quoteAsset": "USDT",
+		"orderTypes": ["LIMIT", "LIMIT_MAKER", "MARKET", "STOP_LOSS_LIMIT", "TAKE_PROFIT_LIMIT"],
+		"ocoAllowed": true,
+		"filters": [
+			{"filterType": "PRICE_FILTER", "minPrice": "0.01000000", "maxPrice": "1000000.00000000", "tickSize": "0.01000000"},
+			{"filterType": "LOT_SIZE", "minQty": "0.00001000", "maxQty": "9000.00000000", "stepSize": "0.00001000"},
+			{"filterType": "NOTIONAL", "minNotional": "5.00000000", "applyMinToMarket": true, "maxNotional": "9000000.00000000", "applyMaxToMarket": false, "avgPriceMins": 5}
+		]
+	}`
+
+func symbolBTCUSDT(t *testing.T) binance.Symbol {
+	var s binance.Symbol
+	if err := json.Unmarshal([]byte(sBTCUSDT), &s); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func Test_applyFilters(t *testing.T) {
+	symbol := symbolBTCUSDT(t)
+
+	tests := []struct {
+		name    string
+		ov      orderValues
+		want    orderValues
+		wantErr bool
+	}{
+		{
+			name: "limit",
+			ov:   orderValues{orderType: domain.OrderTypeLimit, price: decimal.FromFloat(30000.123), baseQuantity: decimal.FromFloat(0.0123456)},
+			want: orderValues{orderType: domain.OrderTypeLimit, price: decimal.FromFloat(30000.12), baseQuantity: decimal.FromFloat(0.01234)},
+		},
+		{
+			name: "stop limit",
+			ov:   orderValues{orderType: domain.OrderTypeStopLossLimit, price: decimal.FromFloat(29000.004), stopPrice: decimal.FromFloat(29100.006), baseQuantity: decimal.FromFloat(0.01)},
+			want: orderValues{orderType: domain.OrderTypeStopLossLimit, price: decimal.FromFloat(29000), stopPrice: decimal.FromFloat(29100.01), baseQuantity: decimal.FromFloat(0.01)},
+		},
+		{
+			name: "oco",
+			ov:   orderValues{orderType: domain.OrderTypeOCO, price: decimal.FromFloat(35000.001), stopPrice: decimal.FromFloat(28000.009), stopLimitPrice: decimal.FromFloat(27900.111), baseQuantity: decimal.FromFloat(0.01)},
+			want: orderValues{orderType: domain.OrderTypeOCO, price: decimal.FromFloat(35000), stopPrice: decimal.FromFloat(28000.01), stopLimitPrice: decimal.FromFloat(27900.11), baseQuantity: decimal.FromFloat(0.01)},
+		},
+		{
+			name:    "oco stop leg under min notional",
+			ov:      orderValues{orderType: domain.OrderTypeOCO, price: decimal.FromFloat(600), stopPrice: decimal.FromFloat(400), stopLimitPrice: decimal.FromFloat(400), baseQuantity: decimal.FromFloat(0.01)},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported type",
+			ov:      orderValues{orderType: domain.OrderTypeStopLoss, stopPrice: decimal.FromFloat(400), baseQuantity: decimal.FromFloat(0.01)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.ov.symbol = symbol
+			err := applyFilters(&tt.ov)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				return
+			}
+			assert.Equal(t, tt.want.price.Float64(), tt.ov.price.Float64())
+			assert.Equal(t, tt.want.stopPrice.Float64(), tt.ov.stopPrice.Float64())
+			assert.Equal(t, tt.want.stopLimitPrice.Float64(), tt.ov.stopLimitPrice.Float64())
+			assert.Equal(t, tt.want.baseQuantity.Float64(), tt.ov.baseQuantity.Float64())
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package binancespot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/go-binance/v2/common"
+)
+
+// lookupTimeout limits the lookup of an order after a failed placement, the placement's context may have expired
+const lookupTimeout = 10 * time.Second
+
+// placedOrder looks up the order with the client order ID after its placement failed with err,
+// if binance may have accepted it. The order is returned if it exists and err otherwise,
+// so placements that timed out after binance accepted them aren't placed again.
+func (e *Exchange) placedOrder(ctx context.Context, symbol, clientOrderID string, err error) (*domain.ExchangeOrder, error) {
+	if clientOrderID == "" || !ambiguous(err) {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), lookupTimeout)
+	defer cancel()
+
+	order, lookupErr := e.client.NewGetOrderService().Symbol(symbol).OrigClientOrderID(clientOrderID).Do(ctx)
+	if notFound(lookupErr) {
+		return nil, err
+	}
+	if lookupErr != nil {
+		return nil, fmt.Errorf("%w, error looking up order %s: %w", err, clientOrderID, lookupErr)
+	}
+
+	e.logger.Infof("order %s was placed despite error: %s", clientOrderID, err)
+	return orderToOrder(order)
+}
+
+// placedOCO looks up both legs of an OCO order after its placement failed with err, like placedOrder
+func (e *Exchange) placedOCO(ctx context.Context, symbol, clientOrderID string, err error) (*domain.ExchangeOrder, error) {
+	if clientOrderID == "" || !ambiguous(err) {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), lookupTimeout)
+	defer cancel()
+
+	limit, lookupErr := e.client.NewGetOrderService().Symbol(symbol).OrigClientOrderID(limitClientOrderID(clientOrderID)).Do(ctx)
+	if notFound(lookupErr) {
+		return nil, err
+	}
+	if lookupErr != nil {
+		return nil, fmt.Errorf("%w, error looking up order %s: %w", err, clientOrderID, lookupErr)
+	}
+
+	// both legs are placed at once, the stop leg exists if the limit leg does
+	stop, lookupErr := e.client.NewGetOrderService().Symbol(symbol).OrigClientOrderID(stopClientOrderID(clientOrderID)).Do(ctx)
+	if lookupErr != nil {
+		return nil, fmt.Errorf("%w, error looking up order %s: %w", err, clientOrderID, lookupErr)
+	}
+
+	e.logger.Infof("order %s was placed despite error: %s", clientOrderID, err)
+	eo, convErr := ocoToOrder(ocoID{listID: limit.OrderListId, limitOrderID: limit.OrderID, stopOrderID: stop.OrderID}, limit, stop)
+	if convErr != nil {
+		return nil, convErr
+	}
+	eo.ClientID = clientOrderID
+	return eo, nil
+}
+
+// limitClientOrderID and stopClientOrderID identify the legs of the OCO order with the client order ID,
+// binance accepts up to 36 characters
+func limitClientOrderID(clientOrderID string) string { return clientOrderID + "-L" }
+func stopClientOrderID(clientOrderID string) string  { return clientOrderID + "-S" }
+
+// ambiguous returns true if a placement failed without binance telling whether it accepted the order,
+// or binance rejected it because an order with the same client order ID was placed before.
+func ambiguous(err error) bool {
+	apiErr, ok := err.(*common.APIError)
+	if !ok {
+		// timeouts, network errors and unreadable responses
+		return true
+	}
+
+	switch apiErr.Code {
+	case -1006, // "An unexpected response was received from the message bus. Execution status unknown."
+		-1007: // "Timeout waiting for response from backend server. Send status unknown; execution status unknown."
+		return true
+	case -2010: // "NEW_ORDER_REJECTED", it covers every rejection
+		return apiErr.Message == "Duplicate order sent."
+	}
+
+	return false
+}
+
+// notFound returns true for the "Order does not exist" error, the placement didn't reach binance
+func notFound(err error) bool {
+	apiErr, ok := err.(*common.APIError)
+	return ok && apiErr.Code == -2013
+}
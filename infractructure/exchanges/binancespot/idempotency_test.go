@@ -0,0 +1,111 @@
+package binancespot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/Plotrader/core/outbound"
+	"github.com/H3Cki/go-binance/v2/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// placementServer cancels every order, answers placements with apiErr after accepting them if accepted
+// and lookups by client order ID with the orders accepted so far.
+type placementServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	placed  map[string]map[string]any
+	lookups []string
+}
+
+func newPlacementServer(accepted bool, apiErr *common.APIError) *placementServer {
+	s := &placementServer{placed: map[string]map[string]any{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if r.URL.Path == "/api/v3/exchangeInfo" {
+			w.Write([]byte(`{"serverTime":9999999999999,"symbols":[` + sBTCUSDT + `]}`))
+			return
+		}
+
+		switch r.Method {
+		case http.MethodDelete:
+			w.Write([]byte(`{"symbol":"BTCUSDT","orderId":1,"price":"30000.00","origQty":"0.50000","status":"CANCELED","type":"LIMIT","side":"BUY","timeInForce":"GTC"}`))
+		case http.MethodGet:
+			id := r.Form.Get("origClientOrderId")
+			s.lookups = append(s.lookups, id)
+			order, ok := s.placed[id]
+			if !ok {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"code":-2013,"msg":"Order does not exist."}`))
+				return
+			}
+			json.NewEncoder(w).Encode(order)
+		default:
+			id := r.Form.Get("newClientOrderId")
+			if accepted {
+				s.placed[id] = map[string]any{
+					"symbol": "BTCUSDT", "orderId": len(s.placed) + 2, "clientOrderId": id,
+					"price": r.Form.Get("price"), "origQty": r.Form.Get("quantity"), "executedQty": "0", "cummulativeQuoteQty": "0",
+					"status": "NEW", "type": "LIMIT", "side": r.Form.Get("side"), "timeInForce": "GTC",
+				}
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(apiErr)
+		}
+	}))
+	return s
+}
+
+func TestExchange_ModifyOrder_Ambiguous(t *testing.T) {
+	tests := []struct {
+		name     string
+		accepted bool
+		err      common.APIError
+		lookup   bool
+		wantErr  int64
+	}{
+		{"accepted despite unknown status", true, common.APIError{Code: -1007, Message: "Timeout waiting for response from backend server."}, true, 0},
+		{"not accepted", false, common.APIError{Code: -1007, Message: "Timeout waiting for response from backend server."}, true, -1007},
+		{"duplicate of an earlier placement", true, common.APIError{Code: -2010, Message: "Duplicate order sent."}, true, 0},
+		{"rejected", false, common.APIError{Code: -2010, Message: "Account has insufficient balance for requested action."}, false, -2010},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newPlacementServer(tt.accepted, &tt.err)
+			defer srv.Close()
+
+			e := newTestExchange(srv.URL)
+			defer e.cache.Stop()
+
+			eo, err := e.ModifyOrder(context.Background(), outbound.ModifyExchangeOrderRequest{
+				EO: &domain.ExchangeOrder{
+					ID: int64(1), Type: "LIMIT", Side: "BUY", Symbol: "BTCUSDT", Price: 30000, BaseQuantity: 0.5, TimeInForce: "GTC",
+				},
+				Price:         31000,
+				BaseQuantity:  0.5,
+				ClientOrderID: "abc",
+			})
+
+			assert.Equal(t, tt.lookup, len(srv.lookups) == 1)
+			if tt.wantErr != 0 {
+				apiErr, ok := err.(*common.APIError)
+				assert.True(t, ok)
+				assert.Equal(t, tt.wantErr, apiErr.Code)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "abc", eo.ClientID)
+			assert.Equal(t, 31000.0, eo.Price)
+		})
+	}
+}
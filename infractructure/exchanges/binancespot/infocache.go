@@ -0,0 +1,42 @@
+package binancespot
+
+import (
+	"context"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/outbound"
+	"github.com/H3Cki/Plotrader/infractructure/exchanges/infocache"
+	"github.com/H3Cki/go-binance/v2"
+	"go.uber.org/zap"
+)
+
+// infoCache is the exchange info of an endpoint shared by the exchanges of the endpoint
+type infoCache = infocache.Cache[ExchangeInfo, binance.Symbol]
+
+// infoCaches holds a cache per base URL, so exchanges of the same endpoint fetch the exchange info once
+var infoCaches = infocache.NewPool[ExchangeInfo, binance.Symbol]()
+
+func infoCacheConfig(logger *zap.SugaredLogger, client *binance.Client, loader outbound.FileLoader[ExchangeInfo], fileName string) infocache.Config[ExchangeInfo, binance.Symbol] {
+	return infocache.Config[ExchangeInfo, binance.Symbol]{
+		Logger:   logger,
+		Loader:   loader,
+		FileName: fileName,
+		MaxAge:   maxEiAge,
+		Fetch: func(ctx context.Context) (ExchangeInfo, error) {
+			ei, err := client.NewExchangeInfoService().Do(ctx)
+			if err != nil {
+				return ExchangeInfo{}, err
+			}
+			return ExchangeInfo(*ei), nil
+		},
+		Index: func(ei ExchangeInfo) map[string]binance.Symbol {
+			symbols := make(map[string]binance.Symbol, len(ei.Symbols))
+			for _, s := range ei.Symbols {
+				symbols[s.Symbol] = s
+			}
+			return symbols
+		},
+		// ServerTime is the fetch time in ms
+		FetchedAt: func(ei ExchangeInfo) time.Time { return time.UnixMilli(ei.ServerTime) },
+	}
+}
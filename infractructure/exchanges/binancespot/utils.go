@@ -0,0 +1,331 @@
+package binancespot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/go-binance/v2"
+)
+
+// Parse functions
+
+func pairToSymbol(p domain.Pair) string {
+	return p.Base + p.Quote
+}
+
+func orderSide(side domain.OrderSide) (binance.SideType, error) {
+	switch side {
+	case domain.OrderSideBuy:
+		return binance.SideTypeBuy, nil
+	case domain.OrderSideSell:
+		return binance.SideTypeSell, nil
+	}
+	return "", fmt.Errorf("unsupported order side: %s", side)
+}
+
+// timeInForce returns GTC for empty values, spot orders can't be post only
+func timeInForce(tif domain.TimeInForce) (binance.TimeInForceType, error) {
+	switch tif {
+	case "", domain.TimeInForceGTC:
+		return binance.TimeInForceTypeGTC, nil
+	case domain.TimeInForceIOC:
+		return binance.TimeInForceTypeIOC, nil
+	case domain.TimeInForceFOK:
+		return binance.TimeInForceTypeFOK, nil
+	}
+	return "", fmt.Errorf("unsupported time in force: %s", tif)
+}
+
+// orderID returns the ID of a single order, IDs may be decoded as floats by the repository
+func orderID(id any) (int64, error) {
+	switch v := id.(type) {
+	case int64:
+		return v, nil
+	case float64:
+		return int64(v), nil
+	}
+	return 0, fmt.Errorf("invalid order id: %v", id)
+}
+
+// ocoID identifies both legs of an OCO order, it's stored in ExchangeOrder.ID as "listID:limitOrderID:stopOrderID"
+type ocoID struct {
+	listID       int64
+	limitOrderID int64
+	stopOrderID  int64
+}
+
+func (id ocoID) String() string {
+	return fmt.Sprintf("%d:%d:%d", id.listID, id.limitOrderID, id.stopOrderID)
+}
+
+func parseOCOID(id any) (ocoID, error) {
+	s, ok := id.(string)
+	if !ok {
+		return ocoID{}, fmt.Errorf("invalid oco id: %v", id)
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return ocoID{}, fmt.Errorf("invalid oco id: %s", s)
+	}
+
+	ids := make([]int64, len(parts))
+	for i, part := range parts {
+		v, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return ocoID{}, fmt.Errorf("invalid oco id: %s", s)
+		}
+		ids[i] = v
+	}
+
+	return ocoID{listID: ids[0], limitOrderID: ids[1], stopOrderID: ids[2]}, nil
+}
+
+func orderToOrder(order *binance.Order) (*domain.ExchangeOrder, error) {
+	status, price, quantity, err := statusPriceQuantity(order.Status, order.Price, order.OrigQuantity)
+	if err != nil {
+		return nil, err
+	}
+
+	stopPrice, err := parseOptionalNumber(order.StopPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	fillPrice, err := averagePrice(order.CummulativeQuoteQuantity, order.ExecutedQuantity)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.ExchangeOrder{
+		ID:           order.OrderID,
+		ClientID:     order.ClientOrderID,
+		Status:       status,
+		Type:         string(order.Type),
+		Side:         string(order.Side),
+		Symbol:       order.Symbol,
+		Price:        price,
+		StopPrice:    stopPrice,
+		BaseQuantity: quantity,
+		FillPrice:    fillPrice,
+		TimeInForce:  string(order.TimeInForce),
+	}, nil
+}
+
+// createRespToOrder converts the response of a created order, it doesn't contain the stop price
+func createRespToOrder(resp *binance.CreateOrderResponse, stopPrice float64) (*domain.ExchangeOrder, error) {
+	status, price, quantity, err := statusPriceQuantity(resp.Status, resp.Price, resp.OrigQuantity)
+	if err != nil {
+		return nil, err
+	}
+
+	fillPrice, err := averagePrice(resp.CummulativeQuoteQuantity, resp.ExecutedQuantity)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.ExchangeOrder{
+		ID:           resp.OrderID,
+		ClientID:     resp.ClientOrderID,
+		Status:       status,
+		Type:         string(resp.Type),
+		Side:         string(resp.Side),
+		Symbol:       resp.Symbol,
+		Price:        price,
+		StopPrice:    stopPrice,
+		BaseQuantity: quantity,
+		FillPrice:    fillPrice,
+		TimeInForce:  string(resp.TimeInForce),
+	}, nil
+}
+
+func cancelRespToOrder(resp *binance.CancelOrderResponse) (*domain.ExchangeOrder, error) {
+	status, price, quantity, err := statusPriceQuantity(resp.Status, resp.Price, resp.OrigQuantity)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.ExchangeOrder{
+		ID:           resp.OrderID,
+		Status:       status,
+		Type:         string(resp.Type),
+		Side:         string(resp.Side),
+		Symbol:       resp.Symbol,
+		Price:        price,
+		BaseQuantity: quantity,
+		TimeInForce:  string(resp.TimeInForce),
+	}, nil
+}
+
+// ocoLeg is the part of an order report that is needed to describe an OCO order
+type ocoLeg struct {
+	side        binance.SideType
+	status      binance.OrderStatusType
+	timeInForce binance.TimeInForceType
+	price       string
+	stopPrice   string
+	origQty     string
+	executedQty string
+	cumQuoteQty string
+}
+
+func legFromReport(r *binance.OCOOrderReport) ocoLeg {
+	return ocoLeg{
+		side:        r.Side,
+		status:      r.Status,
+		timeInForce: r.TimeInForce,
+		price:       r.Price,
+		stopPrice:   r.StopPrice,
+		origQty:     r.OrigQuantity,
+		executedQty: r.ExecutedQuantity,
+		cumQuoteQty: r.CummulativeQuoteQuantity,
+	}
+}
+
+func legFromOrder(o *binance.Order) ocoLeg {
+	return ocoLeg{
+		side:        o.Side,
+		status:      o.Status,
+		timeInForce: o.TimeInForce,
+		price:       o.Price,
+		stopPrice:   o.StopPrice,
+		origQty:     o.OrigQuantity,
+		executedQty: o.ExecutedQuantity,
+		cumQuoteQty: o.CummulativeQuoteQuantity,
+	}
+}
+
+func ocoReportsToOrder(listID int64, symbol string, reports []*binance.OCOOrderReport) (*domain.ExchangeOrder, error) {
+	var limit, stop *binance.OCOOrderReport
+	for _, r := range reports {
+		switch r.Type {
+		case binance.OrderTypeLimitMaker:
+			limit = r
+		case binance.OrderTypeStopLoss, binance.OrderTypeStopLossLimit, binance.OrderTypeTakeProfit, binance.OrderTypeTakeProfitLimit:
+			stop = r
+		}
+	}
+	if limit == nil || stop == nil {
+		return nil, fmt.Errorf("incomplete oco order %d", listID)
+	}
+
+	id := ocoID{listID: listID, limitOrderID: limit.OrderID, stopOrderID: stop.OrderID}
+	return ocoLegsToOrder(id, symbol, legFromReport(limit), legFromReport(stop))
+}
+
+func ocoToOrder(id ocoID, limit, stop *binance.Order) (*domain.ExchangeOrder, error) {
+	return ocoLegsToOrder(id, limit.Symbol, legFromOrder(limit), legFromOrder(stop))
+}
+
+// ocoLegsToOrder combines both legs into a single order, it's done when one of the legs is filled
+// and canceled if both of them were canceled without being filled.
+func ocoLegsToOrder(id ocoID, symbol string, limit, stop ocoLeg) (*domain.ExchangeOrder, error) {
+	limitStatus, price, quantity, err := statusPriceQuantity(limit.status, limit.price, limit.origQty)
+	if err != nil {
+		return nil, err
+	}
+
+	stopStatus, stopLimitPrice, _, err := statusPriceQuantity(stop.status, stop.price, stop.origQty)
+	if err != nil {
+		return nil, err
+	}
+
+	stopPrice, err := parseOptionalNumber(stop.stopPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	eo := &domain.ExchangeOrder{
+		ID:             id.String(),
+		Type:           string(domain.OrderTypeOCO),
+		Side:           string(limit.side),
+		Symbol:         symbol,
+		Price:          price,
+		StopPrice:      stopPrice,
+		StopLimitPrice: stopLimitPrice,
+		BaseQuantity:   quantity,
+		TimeInForce:    string(stop.timeInForce),
+	}
+
+	switch {
+	case limitStatus == domain.OrderStatusDone:
+		eo.Status = domain.OrderStatusDone
+		eo.FillPrice, err = averagePrice(limit.cumQuoteQty, limit.executedQty)
+	case stopStatus == domain.OrderStatusDone:
+		eo.Status = domain.OrderStatusDone
+		eo.FillPrice, err = averagePrice(stop.cumQuoteQty, stop.executedQty)
+	case limitStatus == domain.OrderStatusCanceled && stopStatus == domain.OrderStatusCanceled:
+		eo.Status = domain.OrderStatusCanceled
+	default:
+		eo.Status = domain.OrderStatusActive
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return eo, nil
+}
+
+func statusPriceQuantity(status binance.OrderStatusType, price, quantity string) (domain.OrderStatus, float64, float64, error) {
+	s, err := toDomainOrderStatus(status)
+	if err != nil {
+		return "", 0, 0, err
+	}
+
+	p, err := strconv.ParseFloat(price, 64)
+	if err != nil {
+		return "", 0, 0, err
+	}
+
+	q, err := strconv.ParseFloat(quantity, 64)
+	if err != nil {
+		return "", 0, 0, err
+	}
+
+	return s, p, q, nil
+}
+
+// parseOptionalNumber parses numbers that are not set for every order, like the stop price
+func parseOptionalNumber(s string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	return strconv.ParseFloat(s, 64)
+}
+
+// averagePrice calculates the average fill price, it's zero for orders that weren't filled
+func averagePrice(cumQuoteQty, executedQty string) (float64, error) {
+	quote, err := parseOptionalNumber(cumQuoteQty)
+	if err != nil {
+		return 0, err
+	}
+
+	base, err := parseOptionalNumber(executedQty)
+	if err != nil {
+		return 0, err
+	}
+
+	if base == 0 {
+		return 0, nil
+	}
+
+	return quote / base, nil
+}
+
+func toDomainOrderStatus(status binance.OrderStatusType) (domain.OrderStatus, error) {
+	switch status {
+	case binance.OrderStatusTypeNew, binance.OrderStatusTypePartiallyFilled, binance.OrderStatusTypePendingCancel:
+		return domain.OrderStatusActive, nil
+	case binance.OrderStatusTypeFilled:
+		return domain.OrderStatusDone, nil
+	case binance.OrderStatusTypeCanceled,
+		binance.OrderStatusTypeRejected,
+		binance.OrderStatusTypeExpired,
+		binance.OrderStatusExpiredInMatch:
+		return domain.OrderStatusCanceled, nil
+	}
+	return "", fmt.Errorf("unknown order status: %s", status)
+}
@@ -0,0 +1,57 @@
+package binancespot
+
+import (
+	"testing"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/go-binance/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOCOID(t *testing.T) {
+	id := ocoID{listID: 1, limitOrderID: 2, stopOrderID: 3}
+
+	got, err := parseOCOID(id.String())
+	assert.NoError(t, err)
+	assert.Equal(t, id, got)
+
+	_, err = parseOCOID(int64(1))
+	assert.Error(t, err)
+
+	_, err = parseOCOID("1:2")
+	assert.Error(t, err)
+}
+
+func TestOCOLegsToOrder(t *testing.T) {
+	id := ocoID{listID: 1, limitOrderID: 2, stopOrderID: 3}
+	leg := func(status binance.OrderStatusType, price, executed, cumQuote string) ocoLeg {
+		return ocoLeg{side: binance.SideTypeSell, status: status, price: price, stopPrice: "28000", origQty: "0.5", executedQty: executed, cumQuoteQty: cumQuote}
+	}
+
+	tests := []struct {
+		name          string
+		limit, stop   ocoLeg
+		wantStatus    domain.OrderStatus
+		wantFillPrice float64
+	}{
+		{"open", leg("NEW", "35000", "0", "0"), leg("NEW", "27900", "0", "0"), domain.OrderStatusActive, 0},
+		{"limit filled", leg("FILLED", "35000", "0.5", "17500"), leg("EXPIRED", "27900", "0", "0"), domain.OrderStatusDone, 35000},
+		{"stop filled", leg("EXPIRED", "35000", "0", "0"), leg("FILLED", "27900", "0.5", "13950"), domain.OrderStatusDone, 27900},
+		{"canceled", leg("CANCELED", "35000", "0", "0"), leg("CANCELED", "27900", "0", "0"), domain.OrderStatusCanceled, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eo, err := ocoLegsToOrder(id, "BTCUSDT", tt.limit, tt.stop)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, eo.Status)
+			assert.Equal(t, tt.wantFillPrice, eo.FillPrice)
+			assert.Equal(t, "1:2:3", eo.ID)
+			assert.Equal(t, string(domain.OrderTypeOCO), eo.Type)
+			assert.Equal(t, 35000.0, eo.Price)
+			assert.Equal(t, 28000.0, eo.StopPrice)
+			assert.Equal(t, 27900.0, eo.StopLimitPrice)
+			assert.Equal(t, 0.5, eo.BaseQuantity)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package bybit
+
+// Request and response bodies of the v5 endpoints, numbers are passed as strings
+
+type createOrderParams struct {
+	Category         string `json:"category"`
+	Symbol           string `json:"symbol"`
+	Side             string `json:"side"`
+	OrderType        string `json:"orderType"`
+	Qty              string `json:"qty"`
+	Price            string `json:"price,omitempty"`
+	TriggerPrice     string `json:"triggerPrice,omitempty"`
+	TriggerDirection int    `json:"triggerDirection,omitempty"`
+	TriggerBy        string `json:"triggerBy,omitempty"`
+	TimeInForce      string `json:"timeInForce,omitempty"`
+	PositionIdx      int    `json:"positionIdx"`
+	ReduceOnly       bool   `json:"reduceOnly,omitempty"`
+}
+
+type amendOrderParams struct {
+	Category     string `json:"category"`
+	Symbol       string `json:"symbol"`
+	OrderID      string `json:"orderId"`
+	Qty          string `json:"qty,omitempty"`
+	Price        string `json:"price,omitempty"`
+	TriggerPrice string `json:"triggerPrice,omitempty"`
+}
+
+type cancelOrderParams struct {
+	Category string `json:"category"`
+	Symbol   string `json:"symbol"`
+	OrderID  string `json:"orderId"`
+}
+
+type orderIDResponse struct {
+	OrderID     string `json:"orderId"`
+	OrderLinkID string `json:"orderLinkId"`
+}
+
+type order struct {
+	OrderID          string `json:"orderId"`
+	Symbol           string `json:"symbol"`
+	Side             string `json:"side"`
+	OrderType        string `json:"orderType"`
+	OrderStatus      string `json:"orderStatus"`
+	Price            string `json:"price"`
+	Qty              string `json:"qty"`
+	AvgPrice         string `json:"avgPrice"`
+	TriggerPrice     string `json:"triggerPrice"`
+	TriggerDirection int    `json:"triggerDirection"`
+	TriggerBy        string `json:"triggerBy"`
+	TimeInForce      string `json:"timeInForce"`
+	PositionIdx      int    `json:"positionIdx"`
+	ReduceOnly       bool   `json:"reduceOnly"`
+}
+
+type orderList struct {
+	List []order `json:"list"`
+}
+
+type ticker struct {
+	Symbol    string `json:"symbol"`
+	LastPrice string `json:"lastPrice"`
+	MarkPrice string `json:"markPrice"`
+}
+
+type tickerList struct {
+	List []ticker `json:"list"`
+}
+
+type instrumentList struct {
+	List           []Instrument `json:"list"`
+	NextPageCursor string       `json:"nextPageCursor"`
+}
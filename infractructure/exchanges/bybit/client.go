@@ -0,0 +1,137 @@
+package bybit
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	baseURL        = "https://api.bybit.com"
+	testnetBaseURL = "https://api-testnet.bybit.com"
+	recvWindow     = "5000"
+)
+
+// APIError is returned when bybit responds with a non zero retCode
+type APIError struct {
+	Code    int
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("bybit error %d: %s", e.Code, e.Message)
+}
+
+type response struct {
+	RetCode int             `json:"retCode"`
+	RetMsg  string          `json:"retMsg"`
+	Result  json.RawMessage `json:"result"`
+}
+
+// client is a minimal bybit v5 REST client, requests are signed with HMAC SHA256
+type client struct {
+	baseURL    string
+	apiKey     string
+	secretKey  string
+	httpClient *http.Client
+}
+
+func newClient(apiKey, secretKey string, testnet bool) *client {
+	c := &client{
+		baseURL:    baseURL,
+		apiKey:     apiKey,
+		secretKey:  secretKey,
+		httpClient: http.DefaultClient,
+	}
+	if testnet {
+		c.baseURL = testnetBaseURL
+	}
+	return c
+}
+
+// get sends a GET request, public endpoints are not signed
+func (c *client) get(ctx context.Context, path string, params url.Values, signed bool, result any) error {
+	return c.do(ctx, http.MethodGet, path, params.Encode(), nil, signed, result)
+}
+
+// post sends a signed POST request with body encoded as json
+func (c *client) post(ctx context.Context, path string, body any, result any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPost, path, "", data, true, result)
+}
+
+func (c *client) do(ctx context.Context, method, path, query string, body []byte, signed bool, result any) error {
+	u := c.baseURL + path
+	if query != "" {
+		u += "?" + query
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if signed {
+		payload := query
+		if method == http.MethodPost {
+			payload = string(body)
+		}
+		ts := strconv.FormatInt(time.Now().UnixMilli(), 10)
+		req.Header.Set("X-BAPI-API-KEY", c.apiKey)
+		req.Header.Set("X-BAPI-TIMESTAMP", ts)
+		req.Header.Set("X-BAPI-RECV-WINDOW", recvWindow)
+		req.Header.Set("X-BAPI-SIGN", c.sign(ts+c.apiKey+recvWindow+payload))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bybit %s %s: unexpected status %d: %s", method, path, resp.StatusCode, data)
+	}
+
+	r := response{}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return err
+	}
+
+	if r.RetCode != 0 {
+		return &APIError{Code: r.RetCode, Message: r.RetMsg}
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.Unmarshal(r.Result, result)
+}
+
+func (c *client) sign(payload string) string {
+	mac := hmac.New(sha256.New, []byte(c.secretKey))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,355 @@
+package bybit
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/Plotrader/core/outbound"
+	"github.com/H3Cki/Plotrader/infractructure/exchanges/decimal"
+	"go.uber.org/zap"
+)
+
+var (
+	eiFileName = "bybit_ei.json"
+	maxEiAge   = 24 * time.Hour
+	category   = "linear"
+)
+
+type UserConfig struct {
+	Testnet bool `json:"testnet"`
+	// HedgeMode must match the position mode of the account, orders are placed with the position index of their side
+	HedgeMode  bool   `json:"hedgeMode"`
+	API_KEY    string `json:"API_KEY" validate:"required"`
+	SECRET_KEY string `json:"SECRET_KEY" validate:"required"`
+}
+
+type Config struct {
+	ExchangeInfoer outbound.FileLoader[ExchangeInfo]
+	UserConfig     UserConfig
+}
+
+// Exchange trades bybit USDT and USDC linear perpetuals
+type Exchange struct {
+	logger    *zap.SugaredLogger
+	client    *client
+	cache     *infoCache
+	testnet   bool
+	hedgeMode bool
+}
+
+// ExchangeInfo is the cached list of linear instruments
+type ExchangeInfo struct {
+	UpdateTime  int64        `json:"updateTime"`
+	Instruments []Instrument `json:"instruments"`
+}
+
+type Instrument struct {
+	Symbol        string        `json:"symbol"`
+	Status        string        `json:"status"`
+	BaseCoin      string        `json:"baseCoin"`
+	QuoteCoin     string        `json:"quoteCoin"`
+	PriceFilter   PriceFilter   `json:"priceFilter"`
+	LotSizeFilter LotSizeFilter `json:"lotSizeFilter"`
+}
+
+type PriceFilter struct {
+	MinPrice string `json:"minPrice"`
+	MaxPrice string `json:"maxPrice"`
+	TickSize string `json:"tickSize"`
+}
+
+type LotSizeFilter struct {
+	MaxOrderQty      string `json:"maxOrderQty"`
+	MinOrderQty      string `json:"minOrderQty"`
+	QtyStep          string `json:"qtyStep"`
+	MinNotionalValue string `json:"minNotionalValue"`
+}
+
+func New(logger *zap.SugaredLogger, cfg Config) *Exchange {
+	e := &Exchange{
+		logger:    logger,
+		client:    newClient(cfg.UserConfig.API_KEY, cfg.UserConfig.SECRET_KEY, cfg.UserConfig.Testnet),
+		testnet:   cfg.UserConfig.Testnet,
+		hedgeMode: cfg.UserConfig.HedgeMode,
+	}
+	e.cache = infoCaches.Get(e.eiFn(), infoCacheConfig(logger, e.client, cfg.ExchangeInfoer, e.eiFn()))
+	return e
+}
+
+func (e *Exchange) eiFn() string {
+	if e.testnet {
+		return "testnet_" + eiFileName
+	}
+	return eiFileName
+}
+
+func (e *Exchange) Init(ctx context.Context) error {
+	if err := e.client.get(ctx, "/v5/market/time", nil, false, nil); err != nil {
+		return err
+	}
+	return e.cache.Load(ctx)
+}
+
+func (e *Exchange) GetOrder(ctx context.Context, req outbound.GetExchangeOrderRequest) (*domain.ExchangeOrder, error) {
+	eo := req.EO
+	params := url.Values{
+		"category": {category},
+		"symbol":   {eo.Symbol},
+		"orderId":  {fmt.Sprint(eo.ID)},
+	}
+
+	// realtime lists open and recently closed orders, older ones are only in the history
+	for _, path := range []string{"/v5/order/realtime", "/v5/order/history"} {
+		resp := orderList{}
+		if err := e.client.get(ctx, path, params, true, &resp); err != nil {
+			return nil, err
+		}
+		if len(resp.List) > 0 {
+			return orderToOrder(resp.List[0])
+		}
+	}
+
+	return nil, fmt.Errorf("order %v not found", eo.ID)
+}
+
+func (e *Exchange) CreateOrder(ctx context.Context, req outbound.CreateExchangeOrderRequest) (*domain.ExchangeOrder, error) {
+	if req.ClosePosition {
+		return nil, fmt.Errorf("closePosition: %w", outbound.ErrUnsupported)
+	}
+
+	instrument, err := e.cache.Symbol(ctx, pairToSymbol(req.Pair))
+	if err != nil {
+		return nil, err
+	}
+
+	side, err := orderSide(req.Side)
+	if err != nil {
+		return nil, err
+	}
+
+	orderType, err := orderType(req.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	tif, err := timeInForce(req.TimeInForce)
+	if err != nil {
+		return nil, err
+	}
+
+	ov := orderValues{
+		instrument:   instrument,
+		side:         side,
+		domainType:   req.Type,
+		orderType:    orderType,
+		price:        req.Price,
+		triggerPrice: req.StopPrice,
+		qty:          req.BaseQuantity,
+		positionIdx:  e.positionIdx(req.PositionSide),
+		timeInForce:  tif,
+		triggerBy:    triggerBy(req.WorkingType),
+		reduceOnly:   req.ReduceOnly,
+	}
+
+	if err := applyFilters(&ov); err != nil {
+		return nil, fmt.Errorf("filter error: %w", err)
+	}
+
+	params := createOrderParams{
+		Category:    category,
+		Symbol:      instrument.Symbol,
+		Side:        ov.side,
+		OrderType:   ov.orderType,
+		Qty:         decimal.FromFloat(ov.qty).String(),
+		PositionIdx: ov.positionIdx,
+		ReduceOnly:  ov.reduceOnly,
+	}
+
+	if req.Type.Limited() {
+		params.Price = decimal.FromFloat(ov.price).String()
+		params.TimeInForce = ov.timeInForce
+	}
+
+	if req.Type.Triggered() {
+		params.TriggerPrice = decimal.FromFloat(ov.triggerPrice).String()
+		params.TriggerDirection = triggerDirection(req.Type, ov.side)
+		params.TriggerBy = ov.triggerBy
+	}
+
+	resp := orderIDResponse{}
+	if err := e.client.post(ctx, "/v5/order/create", params, &resp); err != nil {
+		return nil, err
+	}
+
+	return ov.toOrder(resp.OrderID), nil
+}
+
+// ModifyOrder amends the price, trigger price and quantity of the order, unchanged orders are not sent
+func (e *Exchange) ModifyOrder(ctx context.Context, req outbound.ModifyExchangeOrderRequest) (*domain.ExchangeOrder, error) {
+	eo := req.EO
+	orderType := domain.OrderType(eo.Type)
+
+	instrument, err := e.cache.Symbol(ctx, eo.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	ov := orderValues{
+		instrument:   instrument,
+		domainType:   orderType,
+		price:        req.Price,
+		triggerPrice: req.StopPrice,
+		qty:          req.BaseQuantity,
+	}
+
+	if err := applyFilters(&ov); err != nil {
+		return nil, fmt.Errorf("filter error: %w", err)
+	}
+
+	if ov.price == eo.Price && ov.triggerPrice == eo.StopPrice && ov.qty == eo.BaseQuantity {
+		e.logger.Debugf("ignoring modification of order %v, prev=%f, new=%f", eo.ID, eo.Price, ov.price)
+		return eo, nil
+	}
+
+	params := amendOrderParams{
+		Category: category,
+		Symbol:   eo.Symbol,
+		OrderID:  fmt.Sprint(eo.ID),
+		Qty:      decimal.FromFloat(ov.qty).String(),
+	}
+
+	if orderType.Limited() {
+		params.Price = decimal.FromFloat(ov.price).String()
+	}
+
+	if orderType.Triggered() {
+		params.TriggerPrice = decimal.FromFloat(ov.triggerPrice).String()
+	}
+
+	if err := e.client.post(ctx, "/v5/order/amend", params, nil); err != nil {
+		return nil, err
+	}
+
+	modified := *eo
+	modified.Price = ov.price
+	modified.StopPrice = ov.triggerPrice
+	modified.BaseQuantity = ov.qty
+	return &modified, nil
+}
+
+func (e *Exchange) CancelOrder(ctx context.Context, req outbound.CancelExchangeOrdersRequest) (*domain.ExchangeOrder, error) {
+	eo := req.EO
+	params := cancelOrderParams{
+		Category: category,
+		Symbol:   eo.Symbol,
+		OrderID:  fmt.Sprint(eo.ID),
+	}
+
+	if err := e.client.post(ctx, "/v5/order/cancel", params, nil); err != nil {
+		return nil, err
+	}
+
+	canceled := *eo
+	canceled.Status = domain.OrderStatusCanceled
+	return &canceled, nil
+}
+
+func (e *Exchange) MarkPrice(ctx context.Context, req outbound.MarkPriceRequest) (float64, error) {
+	params := url.Values{
+		"category": {category},
+		"symbol":   {pairToSymbol(req.Pair)},
+	}
+
+	resp := tickerList{}
+	if err := e.client.get(ctx, "/v5/market/tickers", params, false, &resp); err != nil {
+		return 0, err
+	}
+
+	if len(resp.List) == 0 {
+		return 0, fmt.Errorf("no ticker for symbol %s", pairToSymbol(req.Pair))
+	}
+
+	return strconv.ParseFloat(resp.List[0].MarkPrice, 64)
+}
+
+// positionIdx returns the position index of the order, 0 in one-way mode
+func (e *Exchange) positionIdx(ps domain.PositionSide) int {
+	if !e.hedgeMode {
+		return 0
+	}
+	switch ps {
+	case domain.PositionSideLong:
+		return 1
+	case domain.PositionSideShort:
+		return 2
+	}
+	return 0
+}
+
+func getExchangeInfo(ctx context.Context, c *client) (ExchangeInfo, error) {
+	ei := ExchangeInfo{UpdateTime: time.Now().UnixMilli()}
+	cursor := ""
+
+	for {
+		params := url.Values{
+			"category": {category},
+			"limit":    {"1000"},
+		}
+		if cursor != "" {
+			params.Set("cursor", cursor)
+		}
+
+		resp := instrumentList{}
+		if err := c.get(ctx, "/v5/market/instruments-info", params, false, &resp); err != nil {
+			return ExchangeInfo{}, err
+		}
+
+		ei.Instruments = append(ei.Instruments, resp.List...)
+
+		if resp.NextPageCursor == "" {
+			return ei, nil
+		}
+		cursor = resp.NextPageCursor
+	}
+}
+
+type orderValues struct {
+	instrument   Instrument
+	side         string
+	domainType   domain.OrderType
+	orderType    string
+	price        float64
+	triggerPrice float64
+	qty          float64
+	positionIdx  int
+	timeInForce  string
+	triggerBy    string
+	reduceOnly   bool
+}
+
+// toOrder returns the exchange order of a just placed order
+func (ov orderValues) toOrder(id string) *domain.ExchangeOrder {
+	eo := &domain.ExchangeOrder{
+		ID:           id,
+		Status:       domain.OrderStatusActive,
+		Type:         string(ov.domainType),
+		Symbol:       ov.instrument.Symbol,
+		Side:         ov.side,
+		PositionSide: string(toDomainPositionSide(ov.positionIdx)),
+		StopPrice:    ov.triggerPrice,
+		BaseQuantity: ov.qty,
+		ReduceOnly:   ov.reduceOnly,
+		WorkingType:  string(toDomainWorkingType(ov.triggerBy)),
+	}
+
+	if ov.domainType.Limited() {
+		eo.Price = ov.price
+		eo.TimeInForce = string(toDomainTimeInForce(ov.timeInForce))
+	}
+
+	return eo
+}
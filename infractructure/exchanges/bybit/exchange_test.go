@@ -0,0 +1,224 @@
+package bybit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/Plotrader/core/outbound"
+	"github.com/H3Cki/Plotrader/infractructure/exchanges/infocache"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// replayServer responds with the recorded response returned by route for the request,
+// signed requests are verified and the json bodies of posts are collected by path.
+func replayServer(t *testing.T, route func(r *http.Request) string) (*httptest.Server, map[string]map[string]any) {
+	bodies := map[string]map[string]any{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := r.URL.RawQuery
+		if r.Method == http.MethodPost {
+			data, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			payload = string(data)
+
+			body := map[string]any{}
+			assert.NoError(t, json.Unmarshal(data, &body))
+			bodies[r.URL.Path] = body
+		}
+
+		if sign := r.Header.Get("X-BAPI-SIGN"); sign != "" {
+			mac := hmac.New(sha256.New, []byte("secret"))
+			mac.Write([]byte(r.Header.Get("X-BAPI-TIMESTAMP") + "key" + r.Header.Get("X-BAPI-RECV-WINDOW") + payload))
+			assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), sign, r.URL.Path)
+		}
+
+		name := route(r)
+		if name == "" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(data)
+	}))
+
+	return srv, bodies
+}
+
+func newTestExchange(url string, hedgeMode bool) *Exchange {
+	e := New(zap.NewNop().Sugar(), Config{
+		ExchangeInfoer: &memoryLoader{},
+		UserConfig:     UserConfig{API_KEY: "key", SECRET_KEY: "secret", HedgeMode: hedgeMode},
+	})
+	e.client.baseURL = url
+	e.cache = infocache.New(infoCacheConfig(e.logger, e.client, &memoryLoader{}, e.eiFn()))
+	return e
+}
+
+func TestExchange(t *testing.T) {
+	srv, bodies := replayServer(t, func(r *http.Request) string {
+		switch r.URL.Path {
+		case "/v5/market/time":
+			return "time.json"
+		case "/v5/market/instruments-info":
+			if r.URL.Query().Get("cursor") == "" {
+				return "instruments_1.json"
+			}
+			return "instruments_2.json"
+		case "/v5/order/create":
+			return "order_create.json"
+		case "/v5/order/realtime":
+			return "order_realtime_empty.json"
+		case "/v5/order/history":
+			return "order_history.json"
+		case "/v5/order/amend":
+			return "order_amend.json"
+		case "/v5/order/cancel":
+			return "order_cancel.json"
+		case "/v5/market/tickers":
+			return "tickers.json"
+		}
+		return ""
+	})
+	defer srv.Close()
+
+	e := newTestExchange(srv.URL, true)
+	defer e.cache.Stop()
+	ctx := context.Background()
+
+	assert.NoError(t, e.Init(ctx))
+	// both pages are indexed
+	for _, name := range []string{"BTCUSDT", "ETHUSDT"} {
+		_, err := e.cache.Symbol(ctx, name)
+		assert.NoError(t, err)
+	}
+
+	eo, err := e.CreateOrder(ctx, outbound.CreateExchangeOrderRequest{
+		Pair:         domain.Pair{Base: "BTC", Quote: "USDT"},
+		Type:         domain.OrderTypeStopLoss,
+		Side:         domain.OrderSideSell,
+		PositionSide: domain.PositionSideLong,
+		BaseQuantity: 0.0123,
+		StopPrice:    29000.54,
+		ReduceOnly:   true,
+		WorkingType:  domain.WorkingTypeMarkPrice,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"category":         "linear",
+		"symbol":           "BTCUSDT",
+		"side":             "Sell",
+		"orderType":        "Market",
+		"qty":              "0.012",
+		"triggerPrice":     "29000.5",
+		"triggerDirection": float64(2),
+		"triggerBy":        "MarkPrice",
+		"positionIdx":      float64(1),
+		"reduceOnly":       true,
+	}, bodies["/v5/order/create"])
+	assert.Equal(t, &domain.ExchangeOrder{
+		ID:           "1321003749386327552",
+		Status:       domain.OrderStatusActive,
+		Type:         "STOP_LOSS",
+		Symbol:       "BTCUSDT",
+		Side:         "Sell",
+		PositionSide: "LONG",
+		StopPrice:    29000.5,
+		BaseQuantity: 0.012,
+		ReduceOnly:   true,
+		WorkingType:  "MARK_PRICE",
+	}, eo)
+
+	modified, err := e.ModifyOrder(ctx, outbound.ModifyExchangeOrderRequest{EO: eo, BaseQuantity: 0.012, StopPrice: 29100.01})
+	assert.NoError(t, err)
+	assert.Equal(t, 29100.0, modified.StopPrice)
+	assert.Equal(t, map[string]any{
+		"category":     "linear",
+		"symbol":       "BTCUSDT",
+		"orderId":      "1321003749386327552",
+		"qty":          "0.012",
+		"triggerPrice": "29100",
+	}, bodies["/v5/order/amend"])
+
+	filled, err := e.GetOrder(ctx, outbound.GetExchangeOrderRequest{EO: *modified})
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.ExchangeOrder{
+		ID:           "1321003749386327552",
+		Status:       domain.OrderStatusDone,
+		Type:         "STOP_LOSS",
+		Symbol:       "BTCUSDT",
+		Side:         "Sell",
+		PositionSide: "LONG",
+		StopPrice:    29000.5,
+		BaseQuantity: 0.012,
+		FillPrice:    28995.1,
+		ReduceOnly:   true,
+		WorkingType:  "MARK_PRICE",
+	}, filled)
+
+	canceled, err := e.CancelOrder(ctx, outbound.CancelExchangeOrdersRequest{EO: modified})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.OrderStatusCanceled, canceled.Status)
+
+	price, err := e.MarkPrice(ctx, outbound.MarkPriceRequest{Pair: domain.Pair{Base: "BTC", Quote: "USDT"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 30204.71, price)
+}
+
+func TestExchange_APIError(t *testing.T) {
+	srv, _ := replayServer(t, func(r *http.Request) string {
+		return "error_order_not_exists.json"
+	})
+	defer srv.Close()
+
+	e := newTestExchange(srv.URL, false)
+	_, err := e.CancelOrder(context.Background(), outbound.CancelExchangeOrdersRequest{
+		EO: &domain.ExchangeOrder{ID: "1", Symbol: "BTCUSDT"},
+	})
+
+	apiErr := &APIError{}
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, 110001, apiErr.Code)
+}
+
+func TestExchange_CreateOrder_Unsupported(t *testing.T) {
+	e := &Exchange{}
+	_, err := e.CreateOrder(context.Background(), outbound.CreateExchangeOrderRequest{
+		Type:          domain.OrderTypeStopLoss,
+		ClosePosition: true,
+	})
+	assert.ErrorIs(t, err, outbound.ErrUnsupported)
+}
+
+type memoryLoader struct {
+	ei *ExchangeInfo
+}
+
+func (l *memoryLoader) Exists(name string) bool { return l.ei != nil }
+
+func (l *memoryLoader) Save(name string, data ExchangeInfo) error {
+	l.ei = &data
+	return nil
+}
+
+func (l *memoryLoader) Read(name string) (ExchangeInfo, error) {
+	if l.ei == nil {
+		return ExchangeInfo{}, os.ErrNotExist
+	}
+	return *l.ei, nil
+}
@@ -0,0 +1,113 @@
+package bybit
+
+import (
+	"fmt"
+
+	"github.com/H3Cki/Plotrader/infractructure/exchanges/decimal"
+)
+
+// applyFilters rounds the prices to the tick size and the quantity down to the qty step,
+// prices that don't apply to the order type are zeroed.
+func applyFilters(ov *orderValues) error {
+	pf := ov.instrument.PriceFilter
+	lf := ov.instrument.LotSizeFilter
+
+	if ov.domainType.Limited() {
+		price, err := filterPrice(pf, ov.price)
+		if err != nil {
+			return fmt.Errorf("price: %w", err)
+		}
+		ov.price = price
+	} else {
+		ov.price = 0
+	}
+
+	if ov.domainType.Triggered() {
+		triggerPrice, err := filterPrice(pf, ov.triggerPrice)
+		if err != nil {
+			return fmt.Errorf("trigger price: %w", err)
+		}
+		ov.triggerPrice = triggerPrice
+	} else {
+		ov.triggerPrice = 0
+	}
+
+	qty, err := filterQuantity(lf, ov.qty)
+	if err != nil {
+		return err
+	}
+	ov.qty = qty
+
+	// triggered market orders are valued at the trigger price
+	price := ov.price
+	if price == 0 {
+		price = ov.triggerPrice
+	}
+
+	return filterNotional(lf, price, ov.qty)
+}
+
+func filterPrice(pf PriceFilter, price float64) (float64, error) {
+	tickSize, err := decimal.Parse(pf.TickSize)
+	if err != nil {
+		return 0, err
+	}
+	p := decimal.FromFloat(price).Round(tickSize).Float64()
+
+	min, err := parseOptionalNumber(pf.MinPrice)
+	if err != nil {
+		return 0, err
+	}
+
+	max, err := parseOptionalNumber(pf.MaxPrice)
+	if err != nil {
+		return 0, err
+	}
+
+	if p <= 0 || p < min || (max != 0 && p > max) {
+		return 0, fmt.Errorf("%f out of range [%s, %s]", price, pf.MinPrice, pf.MaxPrice)
+	}
+
+	return p, nil
+}
+
+func filterQuantity(lf LotSizeFilter, qty float64) (float64, error) {
+	qtyStep, err := decimal.Parse(lf.QtyStep)
+	if err != nil {
+		return 0, err
+	}
+	q := decimal.FromFloat(qty).Truncate(qtyStep).Float64()
+
+	min, err := parseOptionalNumber(lf.MinOrderQty)
+	if err != nil {
+		return 0, err
+	}
+
+	max, err := parseOptionalNumber(lf.MaxOrderQty)
+	if err != nil {
+		return 0, err
+	}
+
+	if q <= 0 || q < min {
+		return 0, fmt.Errorf("quantity too small, expected >= %s, got %f", lf.MinOrderQty, qty)
+	}
+
+	if max != 0 && q > max {
+		return 0, fmt.Errorf("quantity too large, expected <= %s, got %f", lf.MaxOrderQty, qty)
+	}
+
+	return q, nil
+}
+
+func filterNotional(lf LotSizeFilter, price, qty float64) error {
+	min, err := parseOptionalNumber(lf.MinNotionalValue)
+	if err != nil {
+		return err
+	}
+
+	if price*qty < min {
+		return fmt.Errorf("notional too small, expected >= %s, got %f", lf.MinNotionalValue, price*qty)
+	}
+
+	return nil
+}
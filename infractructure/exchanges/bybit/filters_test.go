@@ -0,0 +1,87 @@
+package bybit
+
+import (
+	"testing"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_applyFilters(t *testing.T) {
+	instrument := Instrument{
+		Symbol:        "BTCUSDT",
+		PriceFilter:   PriceFilter{MinPrice: "0.10", MaxPrice: "199999.80", TickSize: "0.10"},
+		LotSizeFilter: LotSizeFilter{MaxOrderQty: "100.000", MinOrderQty: "0.001", QtyStep: "0.001", MinNotionalValue: "5"},
+	}
+
+	tests := []struct {
+		name    string
+		ov      orderValues
+		want    orderValues
+		wantErr bool
+	}{
+		{
+			name: "limit",
+			ov:   orderValues{domainType: domain.OrderTypeLimit, price: 30000.06, triggerPrice: 1, qty: 0.0119},
+			want: orderValues{price: 30000.1, qty: 0.011},
+		},
+		{
+			name: "stop market",
+			ov:   orderValues{domainType: domain.OrderTypeStopLoss, price: 1, triggerPrice: 29000.04, qty: 0.01},
+			want: orderValues{triggerPrice: 29000, qty: 0.01},
+		},
+		{
+			name: "take profit limit",
+			ov:   orderValues{domainType: domain.OrderTypeTakeProfitLimit, price: 31000.01, triggerPrice: 30999.99, qty: 0.001},
+			want: orderValues{price: 31000, triggerPrice: 31000, qty: 0.001},
+		},
+		{
+			name:    "quantity too small",
+			ov:      orderValues{domainType: domain.OrderTypeLimit, price: 30000, qty: 0.0009},
+			wantErr: true,
+		},
+		{
+			name:    "notional too small",
+			ov:      orderValues{domainType: domain.OrderTypeLimit, price: 1000, qty: 0.001},
+			wantErr: true,
+		},
+		{
+			name:    "price too large",
+			ov:      orderValues{domainType: domain.OrderTypeLimit, price: 200000, qty: 0.001},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.ov.instrument = instrument
+			err := applyFilters(&tt.ov)
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			if tt.wantErr {
+				return
+			}
+			assert.Equal(t, tt.want.price, tt.ov.price)
+			assert.Equal(t, tt.want.triggerPrice, tt.ov.triggerPrice)
+			assert.Equal(t, tt.want.qty, tt.ov.qty)
+		})
+	}
+}
+
+func TestToDomainOrderType(t *testing.T) {
+	for _, typ := range []domain.OrderType{
+		domain.OrderTypeStopLoss,
+		domain.OrderTypeStopLossLimit,
+		domain.OrderTypeTakeProfit,
+		domain.OrderTypeTakeProfitLimit,
+	} {
+		for _, side := range []string{"Buy", "Sell"} {
+			bt, err := orderType(typ)
+			assert.NoError(t, err)
+
+			o := order{Side: side, OrderType: bt, TriggerDirection: triggerDirection(typ, side)}
+			assert.Equal(t, typ, toDomainOrderType(o), "%s %s", typ, side)
+		}
+	}
+
+	assert.Equal(t, domain.OrderTypeLimit, toDomainOrderType(order{Side: "Buy", OrderType: "Limit"}))
+}
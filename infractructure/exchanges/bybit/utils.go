@@ -0,0 +1,192 @@
+package bybit
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+)
+
+// Parse functions
+
+func pairToSymbol(p domain.Pair) string {
+	return p.Base + p.Quote
+}
+
+func orderSide(side domain.OrderSide) (string, error) {
+	switch side {
+	case domain.OrderSideBuy:
+		return "Buy", nil
+	case domain.OrderSideSell:
+		return "Sell", nil
+	}
+	return "", fmt.Errorf("unsupported order side: %s", side)
+}
+
+// orderType maps the order type to a bybit order type, triggered orders are conditional orders of that type
+func orderType(t domain.OrderType) (string, error) {
+	switch t {
+	case domain.OrderTypeLimit, domain.OrderTypeStopLossLimit, domain.OrderTypeTakeProfitLimit:
+		return "Limit", nil
+	case domain.OrderTypeStopLoss, domain.OrderTypeTakeProfit:
+		return "Market", nil
+	}
+	return "", fmt.Errorf("unsupported order type: %s", t)
+}
+
+func timeInForce(tif domain.TimeInForce) (string, error) {
+	switch tif {
+	case "", domain.TimeInForceGTC:
+		return "GTC", nil
+	case domain.TimeInForceIOC:
+		return "IOC", nil
+	case domain.TimeInForceFOK:
+		return "FOK", nil
+	case domain.TimeInForceGTX:
+		return "PostOnly", nil
+	}
+	return "", fmt.Errorf("unsupported time in force: %s", tif)
+}
+
+// triggerBy returns the price that triggers conditional orders, empty uses the exchange default
+func triggerBy(wt domain.WorkingType) string {
+	switch wt {
+	case domain.WorkingTypeMarkPrice:
+		return "MarkPrice"
+	case domain.WorkingTypeContractPrice:
+		return "LastPrice"
+	}
+	return ""
+}
+
+// triggerDirection returns 1 if the order triggers when the price rises to the trigger price and 2 if it falls to it,
+// stop losses of sells and take profits of buys trigger on a fall.
+func triggerDirection(t domain.OrderType, side string) int {
+	stop := t == domain.OrderTypeStopLoss || t == domain.OrderTypeStopLossLimit
+	if stop == (side == "Sell") {
+		return 2
+	}
+	return 1
+}
+
+// Conversions to domain values
+
+func toDomainOrderType(o order) domain.OrderType {
+	limit := o.OrderType == "Limit"
+
+	if o.TriggerDirection == 0 {
+		if limit {
+			return domain.OrderTypeLimit
+		}
+		return domain.OrderTypeMarket
+	}
+
+	stop := (o.TriggerDirection == 2) == (o.Side == "Sell")
+	switch {
+	case stop && limit:
+		return domain.OrderTypeStopLossLimit
+	case stop:
+		return domain.OrderTypeStopLoss
+	case limit:
+		return domain.OrderTypeTakeProfitLimit
+	}
+	return domain.OrderTypeTakeProfit
+}
+
+func toDomainOrderStatus(status string) (domain.OrderStatus, error) {
+	switch status {
+	case "New", "PartiallyFilled", "Untriggered", "Triggered":
+		return domain.OrderStatusActive, nil
+	case "Filled":
+		return domain.OrderStatusDone, nil
+	case "Cancelled", "Rejected", "Deactivated", "PartiallyFilledCanceled":
+		return domain.OrderStatusCanceled, nil
+	}
+	return "", fmt.Errorf("unknown order status: %s", status)
+}
+
+func toDomainPositionSide(positionIdx int) domain.PositionSide {
+	switch positionIdx {
+	case 1:
+		return domain.PositionSideLong
+	case 2:
+		return domain.PositionSideShort
+	}
+	return ""
+}
+
+func toDomainWorkingType(triggerBy string) domain.WorkingType {
+	switch triggerBy {
+	case "MarkPrice":
+		return domain.WorkingTypeMarkPrice
+	case "LastPrice":
+		return domain.WorkingTypeContractPrice
+	}
+	return ""
+}
+
+func toDomainTimeInForce(tif string) domain.TimeInForce {
+	if tif == "PostOnly" {
+		return domain.TimeInForceGTX
+	}
+	return domain.TimeInForce(tif)
+}
+
+func orderToOrder(o order) (*domain.ExchangeOrder, error) {
+	status, err := toDomainOrderStatus(o.OrderStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	price, err := parseOptionalNumber(o.Price)
+	if err != nil {
+		return nil, err
+	}
+
+	qty, err := parseOptionalNumber(o.Qty)
+	if err != nil {
+		return nil, err
+	}
+
+	fillPrice, err := parseOptionalNumber(o.AvgPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	triggerPrice, err := parseOptionalNumber(o.TriggerPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	orderType := toDomainOrderType(o)
+
+	eo := &domain.ExchangeOrder{
+		ID:           o.OrderID,
+		Status:       status,
+		Type:         string(orderType),
+		Symbol:       o.Symbol,
+		Side:         o.Side,
+		PositionSide: string(toDomainPositionSide(o.PositionIdx)),
+		StopPrice:    triggerPrice,
+		BaseQuantity: qty,
+		FillPrice:    fillPrice,
+		ReduceOnly:   o.ReduceOnly,
+		WorkingType:  string(toDomainWorkingType(o.TriggerBy)),
+	}
+
+	// market orders report the fill price as their price
+	if orderType.Limited() {
+		eo.Price = price
+		eo.TimeInForce = string(toDomainTimeInForce(o.TimeInForce))
+	}
+
+	return eo, nil
+}
+
+// parseOptionalNumber parses numbers that are not set for every order, empty strings are zero
+func parseOptionalNumber(s string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
@@ -0,0 +1,220 @@
+// Package decimal implements exact decimal numbers for prices and quantities sent to exchanges,
+// floats are converted at the exchange boundary, quantized to the tick and step sizes of the symbol
+// and formatted in plain notation with the precision of the step.
+package decimal
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is the number coef * 10^-scale, the zero value is 0
+type Decimal struct {
+	coef  *big.Int
+	scale int32
+}
+
+var ten = big.NewInt(10)
+
+// New returns coef * 10^-scale
+func New(coef int64, scale int32) Decimal {
+	if scale < 0 {
+		return Decimal{coef: new(big.Int).Mul(big.NewInt(coef), pow10(-scale))}
+	}
+	return Decimal{coef: big.NewInt(coef), scale: scale}
+}
+
+// Parse parses a number in plain notation like the ones returned by exchanges, e.g. "0.00100000",
+// the scale is the number of decimal places of s.
+func Parse(s string) (Decimal, error) {
+	digits := s
+	neg := strings.HasPrefix(s, "-")
+	if neg || strings.HasPrefix(s, "+") {
+		digits = s[1:]
+	}
+
+	var scale int32
+	if i := strings.IndexByte(digits, '.'); i > -1 {
+		scale = int32(len(digits) - i - 1)
+		digits = digits[:i] + digits[i+1:]
+	}
+
+	if digits == "" || strings.ContainsFunc(digits, func(r rune) bool { return r < '0' || r > '9' }) {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+
+	coef, _ := new(big.Int).SetString(digits, 10)
+	if neg {
+		coef.Neg(coef)
+	}
+
+	return Decimal{coef: coef, scale: scale}, nil
+}
+
+// FromFloat returns the shortest decimal that converts back to f, so 0.1 is 0.1 and not its binary approximation.
+// NaN and infinities are 0.
+func FromFloat(f float64) Decimal {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Decimal{}
+	}
+	d, _ := Parse(strconv.FormatFloat(f, 'f', -1, 64))
+	return d
+}
+
+// Float64 returns the nearest float to d
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String formats d in plain notation with scale decimal places
+func (d Decimal) String() string {
+	coef := d.int()
+	digits := new(big.Int).Abs(coef).String()
+
+	if d.scale > 0 {
+		if n := int(d.scale) + 1 - len(digits); n > 0 {
+			digits = strings.Repeat("0", n) + digits
+		}
+		i := len(digits) - int(d.scale)
+		digits = digits[:i] + "." + digits[i:]
+	}
+
+	if coef.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// Scale returns the number of decimal places of d
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Cmp compares the values of d and o regardless of their scales
+func (d Decimal) Cmp(o Decimal) int {
+	a, b := align(d, o)
+	return a.Cmp(b)
+}
+
+// Equal returns true if d and o have the same value regardless of their scales
+func (d Decimal) Equal(o Decimal) bool {
+	return d.Cmp(o) == 0
+}
+
+func (d Decimal) Add(o Decimal) Decimal {
+	a, b := align(d, o)
+	return Decimal{coef: a.Add(a, b), scale: max(d.scale, o.scale)}
+}
+
+func (d Decimal) Sub(o Decimal) Decimal {
+	a, b := align(d, o)
+	return Decimal{coef: a.Sub(a, b), scale: max(d.scale, o.scale)}
+}
+
+func (d Decimal) Abs() Decimal {
+	return Decimal{coef: new(big.Int).Abs(d.int()), scale: d.scale}
+}
+
+func (d Decimal) Mul(o Decimal) Decimal {
+	return Decimal{coef: new(big.Int).Mul(d.int(), o.int()), scale: d.scale + o.scale}
+}
+
+// Normalize removes the trailing zeros of the decimal places
+func (d Decimal) Normalize() Decimal {
+	coef := new(big.Int).Set(d.int())
+	scale := d.scale
+	if coef.Sign() == 0 {
+		return Decimal{}
+	}
+
+	r := new(big.Int)
+	for scale > 0 {
+		q, m := new(big.Int).QuoRem(coef, ten, r)
+		if m.Sign() != 0 {
+			break
+		}
+		coef = q
+		scale--
+	}
+
+	return Decimal{coef: coef, scale: scale}
+}
+
+// Round returns the multiple of step nearest to d, halves are rounded away from zero like math.Round.
+// The result has the decimal places of step without trailing zeros, d is returned as is if step is zero.
+func (d Decimal) Round(step Decimal) Decimal {
+	return d.quantize(step, func(r, b *big.Int) bool {
+		return new(big.Int).Lsh(r, 1).Cmp(b) >= 0
+	})
+}
+
+// Truncate returns the multiple of step nearest to d towards zero,
+// the result has the decimal places of step without trailing zeros, d is returned as is if step is zero.
+func (d Decimal) Truncate(step Decimal) Decimal {
+	return d.quantize(step, func(r, b *big.Int) bool {
+		return false
+	})
+}
+
+// quantize divides |d| by |step| and rounds the quotient up if roundUp returns true for the remainder and the step
+func (d Decimal) quantize(step Decimal, roundUp func(r, b *big.Int) bool) Decimal {
+	if step.IsZero() {
+		return d
+	}
+
+	step = step.Normalize()
+	a, b := align(d, step)
+	neg := a.Sign() < 0
+	a.Abs(a)
+	b.Abs(b)
+
+	q, r := new(big.Int).QuoRem(a, b, new(big.Int))
+	if roundUp(r, b) {
+		q.Add(q, big.NewInt(1))
+	}
+
+	coef := q.Mul(q, new(big.Int).Abs(step.coef))
+	if neg {
+		coef.Neg(coef)
+	}
+
+	return Decimal{coef: coef, scale: step.scale}
+}
+
+// align returns the coefficients of d and o at their common scale
+func align(d, o Decimal) (*big.Int, *big.Int) {
+	a := new(big.Int).Set(d.int())
+	b := new(big.Int).Set(o.int())
+
+	switch {
+	case d.scale < o.scale:
+		a.Mul(a, pow10(o.scale-d.scale))
+	case d.scale > o.scale:
+		b.Mul(b, pow10(d.scale-o.scale))
+	}
+
+	return a, b
+}
+
+func (d Decimal) int() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(ten, big.NewInt(int64(n)), nil)
+}
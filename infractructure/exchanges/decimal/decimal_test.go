@@ -0,0 +1,137 @@
+package decimal_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/H3Cki/Plotrader/infractructure/exchanges/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func mustParse(t *testing.T, s string) decimal.Decimal {
+	d, err := decimal.Parse(s)
+	assert.NoError(t, err)
+	return d
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in    string
+		want  string
+		scale int32
+	}{
+		{"0.00100000", "0.00100000", 8},
+		{"-12.5", "-12.5", 1},
+		{"+7", "7", 0},
+		{".5", "0.5", 1},
+		{"1.", "1", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got := mustParse(t, tt.in)
+			assert.Equal(t, tt.want, got.String())
+			assert.Equal(t, tt.scale, got.Scale())
+		})
+	}
+
+	for _, in := range []string{"", "-", ".", "1e-5", "1.2.3", "--1", "0x10", "1,5"} {
+		_, err := decimal.Parse(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestFromFloat(t *testing.T) {
+	a, b := 0.1, 0.2
+	assert.Equal(t, "0.30000000000000004", decimal.FromFloat(a+b).String())
+	assert.Equal(t, "0.00001", decimal.FromFloat(1e-5).String())
+	assert.Equal(t, "100000000000000000000", decimal.FromFloat(1e20).String())
+	assert.Equal(t, "-2.5", decimal.FromFloat(-2.5).String())
+	assert.Equal(t, "0", decimal.FromFloat(math.NaN()).String())
+	assert.Equal(t, "0", decimal.Decimal{}.String())
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		value, step, want string
+	}{
+		{"0.30000000000000004", "0.01000000", "0.30"},
+		{"1.1111119111", "0.00000100", "1.111112"},
+		{"2.5", "1", "3"},
+		{"-2.5", "1", "-3"},
+		{"0.25", "0.5", "0.5"},
+		{"0.24", "0.5", "0.0"},
+		{"123.456", "10", "120"},
+		{"0.123", "0", "0.123"},
+	}
+
+	for _, tt := range tests {
+		got := mustParse(t, tt.value).Round(mustParse(t, tt.step))
+		assert.Equal(t, tt.want, got.String(), "%s round %s", tt.value, tt.step)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		value, step, want string
+	}{
+		{"0.2199", "0.01000000", "0.21"},
+		{"0.01", "0.00001000", "0.01000"},
+		{"2.9999999999999996", "1", "2"},
+		{"-2.9", "1", "-2"},
+		{"0.74", "0.25", "0.50"},
+	}
+
+	for _, tt := range tests {
+		got := mustParse(t, tt.value).Truncate(mustParse(t, tt.step))
+		assert.Equal(t, tt.want, got.String(), "%s truncate %s", tt.value, tt.step)
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	a, b := mustParse(t, "0.1"), mustParse(t, "0.20")
+
+	assert.Equal(t, "0.30", a.Add(b).String())
+	assert.Equal(t, "-0.10", a.Sub(b).String())
+	assert.Equal(t, "0.10", a.Sub(b).Abs().String())
+	assert.Equal(t, "0.020", a.Mul(b).String())
+	assert.Equal(t, -1, a.Cmp(b))
+	assert.True(t, mustParse(t, "0.20000").Equal(b))
+	assert.Equal(t, "0.2", b.Normalize().String())
+	assert.Equal(t, "100", mustParse(t, "100.00").Normalize().String())
+	assert.True(t, decimal.New(0, 3).IsZero())
+	assert.Equal(t, "1000", decimal.New(1, -3).String())
+}
+
+func TestFromFloat_RoundTrip(t *testing.T) {
+	f := func(x float64) bool {
+		d := decimal.FromFloat(x)
+		s := d.String()
+		parsed, err := decimal.Parse(s)
+		return d.Float64() == x && !strings.ContainsAny(s, "eE") && err == nil && parsed.Equal(d)
+	}
+
+	assert.NoError(t, quick.Check(f, nil))
+}
+
+func TestRound_Properties(t *testing.T) {
+	steps := []decimal.Decimal{decimal.New(1, 8), decimal.New(5, 1), decimal.New(25, 2), decimal.New(10, 0)}
+
+	f := func(x float64, i uint8) bool {
+		d := decimal.FromFloat(x)
+		step := steps[int(i)%len(steps)]
+
+		rounded, truncated := d.Round(step), d.Truncate(step)
+		two := decimal.New(2, 0)
+
+		return rounded.Round(step).Equal(rounded) &&
+			truncated.Truncate(step).Equal(truncated) &&
+			d.Sub(rounded).Abs().Mul(two).Cmp(step) <= 0 &&
+			d.Sub(truncated).Abs().Cmp(step) < 0 &&
+			truncated.Abs().Cmp(d.Abs()) <= 0
+	}
+
+	assert.NoError(t, quick.Check(f, nil))
+}
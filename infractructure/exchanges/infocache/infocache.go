@@ -0,0 +1,221 @@
+// Package infocache keeps the exchange info of an exchange endpoint in memory, shared by every exchange of the endpoint.
+package infocache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/outbound"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+var (
+	// refreshAt is the part of MaxAge after which the exchange info is refreshed in the background
+	refreshAt = 0.9
+	// retryRefreshAfter is the delay before a failed background refresh is retried
+	retryRefreshAfter = time.Minute
+	// minForcedRefreshAge limits how often lookups of unknown symbols refetch the exchange info
+	minForcedRefreshAge = time.Minute
+	fetchTimeout        = 30 * time.Second
+)
+
+// Config of a Cache of the exchange info I with symbols S
+type Config[I, S any] struct {
+	Logger   *zap.SugaredLogger
+	Loader   outbound.FileLoader[I]
+	FileName string
+	MaxAge   time.Duration
+	// Fetch fetches the exchange info from the exchange
+	Fetch func(ctx context.Context) (I, error)
+	// Index returns the symbols of the exchange info by name
+	Index func(ei I) map[string]S
+	// FetchedAt returns the time the exchange info in the file was fetched at
+	FetchedAt func(ei I) time.Time
+}
+
+// Cache is the exchange info of an endpoint, symbols are indexed by name.
+// The info is fetched once at a time and refreshed in the background before it's outdated,
+// the file is only a snapshot to warm start from.
+type Cache[I, S any] struct {
+	cfg Config[I, S]
+
+	mu        sync.RWMutex
+	symbols   map[string]S
+	fetchedAt time.Time
+	timer     *time.Timer
+	stopped   bool
+
+	warm  sync.Once
+	group singleflight.Group
+}
+
+func New[I, S any](cfg Config[I, S]) *Cache[I, S] {
+	return &Cache[I, S]{cfg: cfg}
+}
+
+// Symbol returns the symbol by name, the exchange info is refetched if the symbol is unknown
+// as it might have been listed after the info was fetched.
+func (c *Cache[I, S]) Symbol(ctx context.Context, name string) (S, error) {
+	var zero S
+	if err := c.Load(ctx); err != nil {
+		return zero, err
+	}
+
+	if s, ok := c.lookup(name); ok {
+		return s, nil
+	}
+
+	if c.age() < minForcedRefreshAge {
+		return zero, fmt.Errorf("unknown symbol: %s", name)
+	}
+
+	if err := c.refresh(ctx); err != nil {
+		return zero, err
+	}
+
+	if s, ok := c.lookup(name); ok {
+		return s, nil
+	}
+
+	return zero, fmt.Errorf("unknown symbol: %s", name)
+}
+
+// Load makes sure the cache holds a fresh exchange info, it starts from the file snapshot if it's fresh
+func (c *Cache[I, S]) Load(ctx context.Context) error {
+	c.warm.Do(c.warmStart)
+
+	if c.loaded() && c.age() < c.cfg.MaxAge {
+		return nil
+	}
+
+	return c.refresh(ctx)
+}
+
+// Set replaces the exchange info fetched at fetchedAt and schedules its refresh
+func (c *Cache[I, S]) Set(ei I, fetchedAt time.Time) {
+	symbols := c.cfg.Index(ei)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.symbols = symbols
+	c.fetchedAt = fetchedAt
+	c.schedule(time.Duration(float64(c.cfg.MaxAge)*refreshAt) - time.Since(fetchedAt))
+}
+
+// Stop stops the background refresh
+func (c *Cache[I, S]) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stopped = true
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+}
+
+// refresh fetches the exchange info, concurrent calls share a single fetch
+func (c *Cache[I, S]) refresh(ctx context.Context) error {
+	_, err, _ := c.group.Do("fetch", func() (any, error) {
+		// the fetch is shared, it must not be canceled by the caller that happened to start it
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), fetchTimeout)
+		defer cancel()
+
+		ei, err := c.cfg.Fetch(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching exchange info: %w", err)
+		}
+
+		c.Set(ei, time.Now())
+
+		// Ignore save error
+		if err := c.cfg.Loader.Save(c.cfg.FileName, ei); err != nil {
+			c.cfg.Logger.Errorf("error saving exchange info: %v", err)
+		}
+
+		return nil, nil
+	})
+	return err
+}
+
+// warmStart fills the cache from the file snapshot if it's not outdated
+func (c *Cache[I, S]) warmStart() {
+	ei, err := c.cfg.Loader.Read(c.cfg.FileName)
+	if err != nil {
+		return
+	}
+
+	fetchedAt := c.cfg.FetchedAt(ei)
+	if time.Since(fetchedAt) >= c.cfg.MaxAge {
+		return
+	}
+
+	c.Set(ei, fetchedAt)
+}
+
+// schedule refreshes the exchange info in the background after d, it must be called with mu locked
+func (c *Cache[I, S]) schedule(d time.Duration) {
+	if c.stopped {
+		return
+	}
+
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+
+	c.timer = time.AfterFunc(d, func() {
+		if err := c.refresh(context.Background()); err != nil {
+			c.cfg.Logger.Errorf("error refreshing exchange info: %v", err)
+
+			c.mu.Lock()
+			c.schedule(retryRefreshAfter)
+			c.mu.Unlock()
+		}
+	})
+}
+
+func (c *Cache[I, S]) lookup(name string) (S, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	s, ok := c.symbols[name]
+	return s, ok
+}
+
+func (c *Cache[I, S]) loaded() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.symbols != nil
+}
+
+func (c *Cache[I, S]) age() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return time.Since(c.fetchedAt)
+}
+
+// Pool shares the caches of the endpoints in the process
+type Pool[I, S any] struct {
+	mu     sync.Mutex
+	caches map[string]*Cache[I, S]
+}
+
+func NewPool[I, S any]() *Pool[I, S] {
+	return &Pool[I, S]{caches: map[string]*Cache[I, S]{}}
+}
+
+// Get returns the cache of the endpoint, it's created with the config of the first exchange of the endpoint
+func (p *Pool[I, S]) Get(endpoint string, cfg Config[I, S]) *Cache[I, S] {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if c, ok := p.caches[endpoint]; ok {
+		return c
+	}
+
+	c := New(cfg)
+	p.caches[endpoint] = c
+	return c
+}
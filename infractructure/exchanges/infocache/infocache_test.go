@@ -0,0 +1,163 @@
+package infocache
+
+import (
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type testInfo struct {
+	FetchedAt time.Time
+	Symbols   []string
+}
+
+// countingLoader keeps the files in memory and counts the reads
+type countingLoader struct {
+	mu    sync.Mutex
+	files map[string]testInfo
+	reads atomic.Int32
+}
+
+func (l *countingLoader) Exists(name string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, ok := l.files[name]
+	return ok
+}
+
+func (l *countingLoader) Save(name string, data testInfo) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.files[name] = data
+	return nil
+}
+
+func (l *countingLoader) Read(name string) (testInfo, error) {
+	l.reads.Add(1)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	ei, ok := l.files[name]
+	if !ok {
+		return testInfo{}, os.ErrNotExist
+	}
+	return ei, nil
+}
+
+// newTestCache creates a cache fetching an info with the symbols and counting the fetches
+func newTestCache(loader *countingLoader, fetches *atomic.Int32, symbols ...string) *Cache[testInfo, string] {
+	return New(Config[testInfo, string]{
+		Logger:   zap.NewNop().Sugar(),
+		Loader:   loader,
+		FileName: "ei.json",
+		MaxAge:   24 * time.Hour,
+		Fetch: func(ctx context.Context) (testInfo, error) {
+			fetches.Add(1)
+			// slow enough for concurrent lookups to wait for the same fetch
+			time.Sleep(10 * time.Millisecond)
+			return testInfo{FetchedAt: time.Now(), Symbols: symbols}, nil
+		},
+		Index: func(ei testInfo) map[string]string {
+			symbols := map[string]string{}
+			for _, s := range ei.Symbols {
+				symbols[s] = s
+			}
+			return symbols
+		},
+		FetchedAt: func(ei testInfo) time.Time { return ei.FetchedAt },
+	})
+}
+
+func TestCache_SingleFetch(t *testing.T) {
+	fetches := atomic.Int32{}
+	loader := &countingLoader{files: map[string]testInfo{}}
+	c := newTestCache(loader, &fetches, "BTCUSDT", "ETHUSDT")
+	defer c.Stop()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s, err := c.Symbol(context.Background(), "ETHUSDT")
+			assert.NoError(t, err)
+			assert.Equal(t, "ETHUSDT", s)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), fetches.Load())
+	assert.Equal(t, int32(1), loader.reads.Load())
+	assert.True(t, loader.Exists("ei.json"))
+
+	// unknown symbols don't refetch a just fetched info
+	_, err := c.Symbol(context.Background(), "XRPUSDT")
+	assert.EqualError(t, err, "unknown symbol: XRPUSDT")
+	assert.Equal(t, int32(1), fetches.Load())
+
+	// an older info is refetched once for an unknown symbol
+	c.mu.Lock()
+	c.fetchedAt = time.Now().Add(-2 * minForcedRefreshAge)
+	c.mu.Unlock()
+	_, err = c.Symbol(context.Background(), "XRPUSDT")
+	assert.Error(t, err)
+	assert.Equal(t, int32(2), fetches.Load())
+}
+
+func TestCache_WarmStart(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		age         time.Duration
+		wantFetches int32
+	}{
+		{"fresh snapshot", time.Hour, 0},
+		{"outdated snapshot", 25 * time.Hour, 1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fetches := atomic.Int32{}
+			loader := &countingLoader{files: map[string]testInfo{
+				"ei.json": {FetchedAt: time.Now().Add(-tt.age), Symbols: []string{"BTCUSDT"}},
+			}}
+			c := newTestCache(loader, &fetches, "BTCUSDT")
+			defer c.Stop()
+
+			for i := 0; i < 3; i++ {
+				_, err := c.Symbol(context.Background(), "BTCUSDT")
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.wantFetches, fetches.Load())
+			assert.Equal(t, int32(1), loader.reads.Load())
+		})
+	}
+}
+
+func TestCache_BackgroundRefresh(t *testing.T) {
+	fetches := atomic.Int32{}
+	loader := &countingLoader{files: map[string]testInfo{}}
+	c := newTestCache(loader, &fetches, "BTCUSDT")
+	c.cfg.MaxAge = 200 * time.Millisecond
+	defer c.Stop()
+
+	assert.NoError(t, c.Load(context.Background()))
+	assert.Equal(t, int32(1), fetches.Load())
+
+	// refreshed before it's outdated, without any lookups
+	assert.Eventually(t, func() bool { return fetches.Load() >= 3 }, 2*time.Second, 10*time.Millisecond)
+	assert.Less(t, c.age(), c.cfg.MaxAge)
+}
+
+func TestPool_Get(t *testing.T) {
+	fetches := atomic.Int32{}
+	loader := &countingLoader{files: map[string]testInfo{}}
+	p := NewPool[testInfo, string]()
+
+	a := p.Get("live", newTestCache(loader, &fetches).cfg)
+	assert.Same(t, a, p.Get("live", newTestCache(loader, &fetches).cfg))
+	assert.NotSame(t, a, p.Get("testnet", newTestCache(loader, &fetches).cfg))
+}
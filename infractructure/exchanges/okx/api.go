@@ -0,0 +1,89 @@
+package okx
+
+// Request and response bodies of the v5 endpoints, numbers are passed as strings
+
+type placeOrderParams struct {
+	InstID     string `json:"instId"`
+	TdMode     string `json:"tdMode"`
+	Side       string `json:"side"`
+	PosSide    string `json:"posSide,omitempty"`
+	OrdType    string `json:"ordType"`
+	Sz         string `json:"sz"`
+	Px         string `json:"px,omitempty"`
+	ReduceOnly bool   `json:"reduceOnly,omitempty"`
+}
+
+type placeAlgoParams struct {
+	InstID        string `json:"instId"`
+	TdMode        string `json:"tdMode"`
+	Side          string `json:"side"`
+	PosSide       string `json:"posSide,omitempty"`
+	OrdType       string `json:"ordType"`
+	Sz            string `json:"sz"`
+	TriggerPx     string `json:"triggerPx"`
+	OrderPx       string `json:"orderPx"`
+	TriggerPxType string `json:"triggerPxType,omitempty"`
+	ReduceOnly    bool   `json:"reduceOnly,omitempty"`
+}
+
+type amendOrderParams struct {
+	InstID string `json:"instId"`
+	OrdID  string `json:"ordId"`
+	NewSz  string `json:"newSz,omitempty"`
+	NewPx  string `json:"newPx,omitempty"`
+}
+
+type amendAlgoParams struct {
+	InstID       string `json:"instId"`
+	AlgoID       string `json:"algoId"`
+	NewSz        string `json:"newSz,omitempty"`
+	NewTriggerPx string `json:"newTriggerPx,omitempty"`
+	NewOrdPx     string `json:"newOrdPx,omitempty"`
+}
+
+type cancelOrderParams struct {
+	InstID string `json:"instId"`
+	OrdID  string `json:"ordId"`
+}
+
+type cancelAlgoParams struct {
+	InstID string `json:"instId"`
+	AlgoID string `json:"algoId"`
+}
+
+type order struct {
+	OrdID      string `json:"ordId"`
+	InstID     string `json:"instId"`
+	Side       string `json:"side"`
+	PosSide    string `json:"posSide"`
+	OrdType    string `json:"ordType"`
+	State      string `json:"state"`
+	Px         string `json:"px"`
+	Sz         string `json:"sz"`
+	AvgPx      string `json:"avgPx"`
+	ReduceOnly string `json:"reduceOnly"`
+}
+
+type algoOrder struct {
+	AlgoID        string   `json:"algoId"`
+	InstID        string   `json:"instId"`
+	Side          string   `json:"side"`
+	PosSide       string   `json:"posSide"`
+	OrdType       string   `json:"ordType"`
+	State         string   `json:"state"`
+	Sz            string   `json:"sz"`
+	TriggerPx     string   `json:"triggerPx"`
+	TriggerPxType string   `json:"triggerPxType"`
+	OrdPx         string   `json:"ordPx"`
+	OrdIDList     []string `json:"ordIdList"`
+	ReduceOnly    string   `json:"reduceOnly"`
+}
+
+type markPrice struct {
+	InstID string `json:"instId"`
+	MarkPx string `json:"markPx"`
+}
+
+type accountConfig struct {
+	PosMode string `json:"posMode"`
+}
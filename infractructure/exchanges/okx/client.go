@@ -0,0 +1,149 @@
+package okx
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const baseURL = "https://www.okx.com"
+
+// APIError is returned when okx responds with a non zero code,
+// the code and message of the first failed order are used for trade endpoints.
+type APIError struct {
+	Code    string
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("okx error %s: %s", e.Code, e.Message)
+}
+
+type response struct {
+	Code string          `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+// orderResult is the per order result of trade endpoints
+type orderResult struct {
+	OrdID  string `json:"ordId"`
+	AlgoID string `json:"algoId"`
+	SCode  string `json:"sCode"`
+	SMsg   string `json:"sMsg"`
+}
+
+// client is a minimal okx v5 REST client, requests are signed with HMAC SHA256
+type client struct {
+	baseURL    string
+	apiKey     string
+	secretKey  string
+	passphrase string
+	// demo sends the requests to the demo trading environment
+	demo       bool
+	httpClient *http.Client
+}
+
+func newClient(apiKey, secretKey, passphrase string, demo bool) *client {
+	return &client{
+		baseURL:    baseURL,
+		apiKey:     apiKey,
+		secretKey:  secretKey,
+		passphrase: passphrase,
+		demo:       demo,
+		httpClient: http.DefaultClient,
+	}
+}
+
+// get sends a GET request, public endpoints are not signed
+func (c *client) get(ctx context.Context, path string, params url.Values, signed bool, result any) error {
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+	return c.do(ctx, http.MethodGet, path, nil, signed, result)
+}
+
+// post sends a signed POST request with body encoded as json
+func (c *client) post(ctx context.Context, path string, body any, result any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPost, path, data, true, result)
+}
+
+// do sends the request, path includes the query string as it's a part of the signature
+func (c *client) do(ctx context.Context, method, path string, body []byte, signed bool, result any) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.demo {
+		req.Header.Set("x-simulated-trading", "1")
+	}
+
+	if signed {
+		ts := time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
+		req.Header.Set("OK-ACCESS-KEY", c.apiKey)
+		req.Header.Set("OK-ACCESS-PASSPHRASE", c.passphrase)
+		req.Header.Set("OK-ACCESS-TIMESTAMP", ts)
+		req.Header.Set("OK-ACCESS-SIGN", c.sign(ts+method+path+string(body)))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	r := response{}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return fmt.Errorf("okx %s %s: unexpected response with status %d: %s", method, path, resp.StatusCode, data)
+	}
+
+	if r.Code != "0" {
+		return responseError(r)
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.Unmarshal(r.Data, result)
+}
+
+func (c *client) sign(payload string) string {
+	mac := hmac.New(sha256.New, []byte(c.secretKey))
+	mac.Write([]byte(payload))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func responseError(r response) error {
+	results := []orderResult{}
+	if err := json.Unmarshal(r.Data, &results); err == nil {
+		for _, res := range results {
+			if res.SCode != "" && res.SCode != "0" {
+				return &APIError{Code: res.SCode, Message: res.SMsg}
+			}
+		}
+	}
+	return &APIError{Code: r.Code, Message: r.Msg}
+}
@@ -0,0 +1,451 @@
+package okx
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/Plotrader/core/outbound"
+	"github.com/H3Cki/Plotrader/infractructure/exchanges/decimal"
+	"go.uber.org/zap"
+)
+
+var (
+	eiFileName = "okx_ei.json"
+	maxEiAge   = 24 * time.Hour
+	instType   = "SWAP"
+)
+
+type UserConfig struct {
+	// Testnet places the orders in the demo trading environment
+	Testnet    bool   `json:"testnet"`
+	API_KEY    string `json:"API_KEY" validate:"required"`
+	SECRET_KEY string `json:"SECRET_KEY" validate:"required"`
+	PASSPHRASE string `json:"PASSPHRASE" validate:"required"`
+	// TradeMode is the margin mode of the orders, cross if empty
+	TradeMode string `json:"tradeMode" validate:"omitempty,oneof=cross isolated"`
+}
+
+type Config struct {
+	ExchangeInfoer outbound.FileLoader[ExchangeInfo]
+	UserConfig     UserConfig
+}
+
+// Exchange trades okx perpetual swaps, order sizes are converted between base quantity and contracts
+type Exchange struct {
+	logger    *zap.SugaredLogger
+	client    *client
+	cache     *infoCache
+	testnet   bool
+	tradeMode string
+	// hedgeMode is true if the account uses long/short position mode, orders have to specify the position side
+	hedgeMode bool
+}
+
+// ExchangeInfo is the cached list of swap instruments
+type ExchangeInfo struct {
+	UpdateTime  int64        `json:"updateTime"`
+	Instruments []Instrument `json:"instruments"`
+}
+
+type Instrument struct {
+	InstID    string `json:"instId"`
+	State     string `json:"state"`
+	CtVal     string `json:"ctVal"` // CtVal is the base quantity of one contract
+	CtValCcy  string `json:"ctValCcy"`
+	SettleCcy string `json:"settleCcy"`
+	TickSz    string `json:"tickSz"`
+	LotSz     string `json:"lotSz"`
+	MinSz     string `json:"minSz"`
+	MaxLmtSz  string `json:"maxLmtSz"`
+	MaxMktSz  string `json:"maxMktSz"`
+}
+
+func New(logger *zap.SugaredLogger, cfg Config) *Exchange {
+	ucfg := cfg.UserConfig
+	e := &Exchange{
+		logger:    logger,
+		client:    newClient(ucfg.API_KEY, ucfg.SECRET_KEY, ucfg.PASSPHRASE, ucfg.Testnet),
+		testnet:   ucfg.Testnet,
+		tradeMode: ucfg.TradeMode,
+	}
+	if e.tradeMode == "" {
+		e.tradeMode = "cross"
+	}
+	e.cache = infoCaches.Get(e.eiFn(), infoCacheConfig(logger, e.client, cfg.ExchangeInfoer, e.eiFn()))
+	return e
+}
+
+func (e *Exchange) eiFn() string {
+	if e.testnet {
+		return "testnet_" + eiFileName
+	}
+	return eiFileName
+}
+
+func (e *Exchange) Init(ctx context.Context) error {
+	if err := e.client.get(ctx, "/api/v5/public/time", nil, false, nil); err != nil {
+		return err
+	}
+
+	configs := []accountConfig{}
+	if err := e.client.get(ctx, "/api/v5/account/config", nil, true, &configs); err != nil {
+		return fmt.Errorf("error getting position mode: %w", err)
+	}
+	if len(configs) > 0 {
+		e.hedgeMode = configs[0].PosMode == "long_short_mode"
+	}
+
+	return e.cache.Load(ctx)
+}
+
+func (e *Exchange) GetOrder(ctx context.Context, req outbound.GetExchangeOrderRequest) (*domain.ExchangeOrder, error) {
+	eo := req.EO
+
+	instrument, err := e.cache.Symbol(ctx, eo.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	if domain.OrderType(eo.Type).Triggered() {
+		return e.getAlgo(ctx, instrument, eo)
+	}
+
+	o, err := e.getOrder(ctx, eo.Symbol, fmt.Sprint(eo.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	return orderToOrder(o, instrument)
+}
+
+func (e *Exchange) CreateOrder(ctx context.Context, req outbound.CreateExchangeOrderRequest) (*domain.ExchangeOrder, error) {
+	if req.ClosePosition {
+		return nil, fmt.Errorf("closePosition: %w", outbound.ErrUnsupported)
+	}
+
+	instrument, err := e.cache.Symbol(ctx, pairToInstID(req.Pair))
+	if err != nil {
+		return nil, err
+	}
+
+	side, err := orderSide(req.Side)
+	if err != nil {
+		return nil, err
+	}
+
+	ordType, err := ordType(req.Type, req.TimeInForce)
+	if err != nil {
+		return nil, err
+	}
+
+	ov := orderValues{
+		instrument:    instrument,
+		side:          side,
+		domainType:    req.Type,
+		ordType:       ordType,
+		price:         req.Price,
+		triggerPrice:  req.StopPrice,
+		baseQuantity:  req.BaseQuantity,
+		posSide:       e.posSide(req.PositionSide),
+		triggerPxType: triggerPxType(req.WorkingType),
+		reduceOnly:    req.ReduceOnly,
+	}
+
+	if err := applyFilters(&ov); err != nil {
+		return nil, fmt.Errorf("filter error: %w", err)
+	}
+
+	if req.Type.Triggered() {
+		return e.createAlgo(ctx, ov)
+	}
+
+	params := placeOrderParams{
+		InstID:     instrument.InstID,
+		TdMode:     e.tradeMode,
+		Side:       ov.side,
+		PosSide:    ov.posSide,
+		OrdType:    ov.ordType,
+		Sz:         decimal.FromFloat(ov.size).String(),
+		Px:         decimal.FromFloat(ov.price).String(),
+		ReduceOnly: ov.reduceOnly,
+	}
+
+	results := []orderResult{}
+	if err := e.client.post(ctx, "/api/v5/trade/order", params, &results); err != nil {
+		return nil, err
+	}
+
+	id, err := firstResult(results, func(r orderResult) string { return r.OrdID })
+	if err != nil {
+		return nil, err
+	}
+
+	return ov.toOrder(id), nil
+}
+
+// ModifyOrder amends the price, trigger price and size of the order, unchanged orders are not sent
+func (e *Exchange) ModifyOrder(ctx context.Context, req outbound.ModifyExchangeOrderRequest) (*domain.ExchangeOrder, error) {
+	eo := req.EO
+	orderType := domain.OrderType(eo.Type)
+
+	instrument, err := e.cache.Symbol(ctx, eo.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	ov := orderValues{
+		instrument:   instrument,
+		domainType:   orderType,
+		price:        req.Price,
+		triggerPrice: req.StopPrice,
+		baseQuantity: req.BaseQuantity,
+	}
+
+	if err := applyFilters(&ov); err != nil {
+		return nil, fmt.Errorf("filter error: %w", err)
+	}
+
+	if ov.price == eo.Price && ov.triggerPrice == eo.StopPrice && ov.baseQuantity == eo.BaseQuantity {
+		e.logger.Debugf("ignoring modification of order %v, prev=%f, new=%f", eo.ID, eo.Price, ov.price)
+		return eo, nil
+	}
+
+	results := []orderResult{}
+	if orderType.Triggered() {
+		params := amendAlgoParams{
+			InstID:       eo.Symbol,
+			AlgoID:       fmt.Sprint(eo.ID),
+			NewSz:        decimal.FromFloat(ov.size).String(),
+			NewTriggerPx: decimal.FromFloat(ov.triggerPrice).String(),
+		}
+		if orderType.Limited() {
+			params.NewOrdPx = decimal.FromFloat(ov.price).String()
+		}
+		err = e.client.post(ctx, "/api/v5/trade/amend-algos", params, &results)
+	} else {
+		params := amendOrderParams{
+			InstID: eo.Symbol,
+			OrdID:  fmt.Sprint(eo.ID),
+			NewSz:  decimal.FromFloat(ov.size).String(),
+			NewPx:  decimal.FromFloat(ov.price).String(),
+		}
+		err = e.client.post(ctx, "/api/v5/trade/amend-order", params, &results)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	modified := *eo
+	modified.Price = ov.price
+	modified.StopPrice = ov.triggerPrice
+	modified.BaseQuantity = ov.baseQuantity
+	return &modified, nil
+}
+
+func (e *Exchange) CancelOrder(ctx context.Context, req outbound.CancelExchangeOrdersRequest) (*domain.ExchangeOrder, error) {
+	eo := req.EO
+
+	var err error
+	if domain.OrderType(eo.Type).Triggered() {
+		params := []cancelAlgoParams{{InstID: eo.Symbol, AlgoID: fmt.Sprint(eo.ID)}}
+		err = e.client.post(ctx, "/api/v5/trade/cancel-algos", params, nil)
+	} else {
+		params := cancelOrderParams{InstID: eo.Symbol, OrdID: fmt.Sprint(eo.ID)}
+		err = e.client.post(ctx, "/api/v5/trade/cancel-order", params, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	canceled := *eo
+	canceled.Status = domain.OrderStatusCanceled
+	return &canceled, nil
+}
+
+func (e *Exchange) MarkPrice(ctx context.Context, req outbound.MarkPriceRequest) (float64, error) {
+	params := url.Values{
+		"instType": {instType},
+		"instId":   {pairToInstID(req.Pair)},
+	}
+
+	prices := []markPrice{}
+	if err := e.client.get(ctx, "/api/v5/public/mark-price", params, false, &prices); err != nil {
+		return 0, err
+	}
+
+	if len(prices) == 0 {
+		return 0, fmt.Errorf("no mark price for instrument %s", pairToInstID(req.Pair))
+	}
+
+	return strconv.ParseFloat(prices[0].MarkPx, 64)
+}
+
+// createAlgo places a trigger order, the order is placed at market price if it's not limited
+func (e *Exchange) createAlgo(ctx context.Context, ov orderValues) (*domain.ExchangeOrder, error) {
+	params := placeAlgoParams{
+		InstID:        ov.instrument.InstID,
+		TdMode:        e.tradeMode,
+		Side:          ov.side,
+		PosSide:       ov.posSide,
+		OrdType:       "trigger",
+		Sz:            decimal.FromFloat(ov.size).String(),
+		TriggerPx:     decimal.FromFloat(ov.triggerPrice).String(),
+		OrderPx:       "-1",
+		TriggerPxType: ov.triggerPxType,
+		ReduceOnly:    ov.reduceOnly,
+	}
+
+	if ov.domainType.Limited() {
+		params.OrderPx = decimal.FromFloat(ov.price).String()
+	}
+
+	results := []orderResult{}
+	if err := e.client.post(ctx, "/api/v5/trade/order-algo", params, &results); err != nil {
+		return nil, err
+	}
+
+	id, err := firstResult(results, func(r orderResult) string { return r.AlgoID })
+	if err != nil {
+		return nil, err
+	}
+
+	return ov.toOrder(id), nil
+}
+
+// getAlgo gets the trigger order, once it's triggered the status and fill price are taken from the placed order
+func (e *Exchange) getAlgo(ctx context.Context, instrument Instrument, eo domain.ExchangeOrder) (*domain.ExchangeOrder, error) {
+	params := url.Values{"algoId": {fmt.Sprint(eo.ID)}}
+
+	algos := []algoOrder{}
+	if err := e.client.get(ctx, "/api/v5/trade/order-algo", params, true, &algos); err != nil {
+		return nil, err
+	}
+
+	if len(algos) == 0 {
+		return nil, fmt.Errorf("algo order %v not found", eo.ID)
+	}
+	algo := algos[0]
+
+	triggered, err := algoToOrder(algo, instrument, domain.OrderType(eo.Type))
+	if err != nil {
+		return nil, err
+	}
+
+	if algo.State != "effective" || len(algo.OrdIDList) == 0 {
+		return triggered, nil
+	}
+
+	o, err := e.getOrder(ctx, algo.InstID, algo.OrdIDList[0])
+	if err != nil {
+		return nil, err
+	}
+
+	placed, err := orderToOrder(o, instrument)
+	if err != nil {
+		return nil, err
+	}
+
+	triggered.Status = placed.Status
+	triggered.FillPrice = placed.FillPrice
+	return triggered, nil
+}
+
+func (e *Exchange) getOrder(ctx context.Context, instID, ordID string) (order, error) {
+	params := url.Values{
+		"instId": {instID},
+		"ordId":  {ordID},
+	}
+
+	orders := []order{}
+	if err := e.client.get(ctx, "/api/v5/trade/order", params, true, &orders); err != nil {
+		return order{}, err
+	}
+
+	if len(orders) == 0 {
+		return order{}, fmt.Errorf("order %s not found", ordID)
+	}
+
+	return orders[0], nil
+}
+
+// posSide returns the position side of the order, empty in net mode
+func (e *Exchange) posSide(ps domain.PositionSide) string {
+	if !e.hedgeMode {
+		return ""
+	}
+	switch ps {
+	case domain.PositionSideLong:
+		return "long"
+	case domain.PositionSideShort:
+		return "short"
+	}
+	return ""
+}
+
+func getExchangeInfo(ctx context.Context, c *client) (ExchangeInfo, error) {
+	instruments := []Instrument{}
+	params := url.Values{"instType": {instType}}
+	if err := c.get(ctx, "/api/v5/public/instruments", params, false, &instruments); err != nil {
+		return ExchangeInfo{}, err
+	}
+
+	return ExchangeInfo{
+		UpdateTime:  time.Now().UnixMilli(),
+		Instruments: instruments,
+	}, nil
+}
+
+type orderValues struct {
+	instrument    Instrument
+	side          string
+	domainType    domain.OrderType
+	ordType       string
+	price         float64
+	triggerPrice  float64
+	baseQuantity  float64
+	size          float64 // size is the number of contracts, set by applyFilters
+	posSide       string
+	triggerPxType string
+	reduceOnly    bool
+}
+
+// toOrder returns the exchange order of a just placed order
+func (ov orderValues) toOrder(id string) *domain.ExchangeOrder {
+	eo := &domain.ExchangeOrder{
+		ID:           id,
+		Status:       domain.OrderStatusActive,
+		Type:         string(ov.domainType),
+		Symbol:       ov.instrument.InstID,
+		Side:         ov.side,
+		PositionSide: string(toDomainPositionSide(ov.posSide)),
+		Price:        ov.price,
+		StopPrice:    ov.triggerPrice,
+		BaseQuantity: ov.baseQuantity,
+		ReduceOnly:   ov.reduceOnly,
+		WorkingType:  string(toDomainWorkingType(ov.triggerPxType)),
+	}
+
+	if !ov.domainType.Triggered() {
+		eo.TimeInForce = string(toDomainTimeInForce(ov.ordType))
+	}
+
+	return eo
+}
+
+// firstResult returns the id of the first order result, okx reports per order errors with a zero response code
+func firstResult(results []orderResult, id func(orderResult) string) (string, error) {
+	if len(results) == 0 {
+		return "", fmt.Errorf("empty order response")
+	}
+
+	r := results[0]
+	if r.SCode != "" && r.SCode != "0" {
+		return "", &APIError{Code: r.SCode, Message: r.SMsg}
+	}
+
+	return id(r), nil
+}
@@ -0,0 +1,264 @@
+package okx
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/Plotrader/core/outbound"
+	"github.com/H3Cki/Plotrader/infractructure/exchanges/infocache"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// replayServer responds with the recorded response returned by route for the request,
+// signed requests are verified and the json bodies of posts are collected by path.
+func replayServer(t *testing.T, route func(r *http.Request) string) (*httptest.Server, map[string]any) {
+	bodies := map[string]any{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		if r.Method == http.MethodPost {
+			var body any
+			assert.NoError(t, json.Unmarshal(data, &body))
+			bodies[r.URL.Path] = body
+		}
+
+		if sign := r.Header.Get("OK-ACCESS-SIGN"); sign != "" {
+			assert.Equal(t, "key", r.Header.Get("OK-ACCESS-KEY"))
+			assert.Equal(t, "pass", r.Header.Get("OK-ACCESS-PASSPHRASE"))
+			mac := hmac.New(sha256.New, []byte("secret"))
+			mac.Write([]byte(r.Header.Get("OK-ACCESS-TIMESTAMP") + r.Method + r.URL.RequestURI() + string(data)))
+			assert.Equal(t, base64.StdEncoding.EncodeToString(mac.Sum(nil)), sign, r.URL.Path)
+		}
+
+		name := route(r)
+		if name == "" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		resp, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(resp)
+	}))
+
+	return srv, bodies
+}
+
+func newTestExchange(url string) *Exchange {
+	e := New(zap.NewNop().Sugar(), Config{
+		ExchangeInfoer: &memoryLoader{},
+		UserConfig:     UserConfig{API_KEY: "key", SECRET_KEY: "secret", PASSPHRASE: "pass"},
+	})
+	e.client.baseURL = url
+	e.cache = infocache.New(infoCacheConfig(e.logger, e.client, &memoryLoader{}, e.eiFn()))
+	return e
+}
+
+func TestExchange(t *testing.T) {
+	srv, bodies := replayServer(t, func(r *http.Request) string {
+		switch r.URL.Path {
+		case "/api/v5/public/time":
+			return "time.json"
+		case "/api/v5/account/config":
+			return "account_config.json"
+		case "/api/v5/public/instruments":
+			return "instruments.json"
+		case "/api/v5/trade/order":
+			if r.Method == http.MethodPost {
+				return "order_place.json"
+			}
+			if r.URL.Query().Get("ordId") == "629874359328219136" {
+				return "order_limit.json"
+			}
+			return "order_triggered.json"
+		case "/api/v5/trade/order-algo":
+			if r.Method == http.MethodPost {
+				return "order_algo_place.json"
+			}
+			return "order_algo_effective.json"
+		case "/api/v5/trade/amend-order":
+			return "order_amend.json"
+		case "/api/v5/trade/cancel-algos":
+			return "order_algo_cancel.json"
+		case "/api/v5/public/mark-price":
+			return "mark_price.json"
+		}
+		return ""
+	})
+	defer srv.Close()
+
+	e := newTestExchange(srv.URL)
+	defer e.cache.Stop()
+	ctx := context.Background()
+
+	assert.NoError(t, e.Init(ctx))
+	assert.True(t, e.hedgeMode)
+	for _, name := range []string{"BTC-USDT-SWAP", "ETH-USDT-SWAP"} {
+		_, err := e.cache.Symbol(ctx, name)
+		assert.NoError(t, err)
+	}
+
+	// limit orders are sized in whole ETH-USDT-SWAP contracts of 0.1 ETH
+	limit, err := e.CreateOrder(ctx, outbound.CreateExchangeOrderRequest{
+		Pair:         domain.Pair{Base: "ETH", Quote: "USDT"},
+		Type:         domain.OrderTypeLimit,
+		Side:         domain.OrderSideBuy,
+		PositionSide: domain.PositionSideLong,
+		BaseQuantity: 1.25,
+		Price:        1500.123,
+		TimeInForce:  domain.TimeInForceGTX,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"instId":  "ETH-USDT-SWAP",
+		"tdMode":  "cross",
+		"side":    "buy",
+		"posSide": "long",
+		"ordType": "post_only",
+		"sz":      "12",
+		"px":      "1500.12",
+	}, bodies["/api/v5/trade/order"])
+	assert.Equal(t, &domain.ExchangeOrder{
+		ID:           "629874359328219136",
+		Status:       domain.OrderStatusActive,
+		Type:         "LIMIT",
+		Symbol:       "ETH-USDT-SWAP",
+		Side:         "buy",
+		PositionSide: "LONG",
+		Price:        1500.12,
+		BaseQuantity: 1.2,
+		TimeInForce:  "GTX",
+	}, limit)
+
+	got, err := e.GetOrder(ctx, outbound.GetExchangeOrderRequest{EO: *limit})
+	assert.NoError(t, err)
+	assert.Equal(t, limit, got)
+
+	modified, err := e.ModifyOrder(ctx, outbound.ModifyExchangeOrderRequest{EO: limit, BaseQuantity: 1.2, Price: 1490.001})
+	assert.NoError(t, err)
+	assert.Equal(t, 1490.0, modified.Price)
+	assert.Equal(t, map[string]any{
+		"instId": "ETH-USDT-SWAP",
+		"ordId":  "629874359328219136",
+		"newSz":  "12",
+		"newPx":  "1490",
+	}, bodies["/api/v5/trade/amend-order"])
+
+	stop, err := e.CreateOrder(ctx, outbound.CreateExchangeOrderRequest{
+		Pair:         domain.Pair{Base: "BTC", Quote: "USDT"},
+		Type:         domain.OrderTypeStopLoss,
+		Side:         domain.OrderSideSell,
+		PositionSide: domain.PositionSideLong,
+		BaseQuantity: 0.0509,
+		StopPrice:    29000.06,
+		ReduceOnly:   true,
+		WorkingType:  domain.WorkingTypeMarkPrice,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"instId":        "BTC-USDT-SWAP",
+		"tdMode":        "cross",
+		"side":          "sell",
+		"posSide":       "long",
+		"ordType":       "trigger",
+		"sz":            "5.09",
+		"triggerPx":     "29000.1",
+		"orderPx":       "-1",
+		"triggerPxType": "mark",
+		"reduceOnly":    true,
+	}, bodies["/api/v5/trade/order-algo"])
+
+	filled, err := e.GetOrder(ctx, outbound.GetExchangeOrderRequest{EO: *stop})
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.ExchangeOrder{
+		ID:           "629874511841161216",
+		Status:       domain.OrderStatusDone,
+		Type:         "STOP_LOSS",
+		Symbol:       "BTC-USDT-SWAP",
+		Side:         "sell",
+		PositionSide: "LONG",
+		StopPrice:    29000.1,
+		BaseQuantity: 0.05,
+		FillPrice:    28994.7,
+		ReduceOnly:   true,
+		WorkingType:  "MARK_PRICE",
+	}, filled)
+
+	canceled, err := e.CancelOrder(ctx, outbound.CancelExchangeOrdersRequest{EO: stop})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.OrderStatusCanceled, canceled.Status)
+	assert.Equal(t, []any{map[string]any{"instId": "BTC-USDT-SWAP", "algoId": "629874511841161216"}}, bodies["/api/v5/trade/cancel-algos"])
+
+	price, err := e.MarkPrice(ctx, outbound.MarkPriceRequest{Pair: domain.Pair{Base: "BTC", Quote: "USDT"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 30204.6, price)
+}
+
+func TestExchange_APIError(t *testing.T) {
+	srv, _ := replayServer(t, func(r *http.Request) string {
+		switch r.URL.Path {
+		case "/api/v5/public/instruments":
+			return "instruments.json"
+		case "/api/v5/trade/order":
+			return "error_order_place.json"
+		}
+		return ""
+	})
+	defer srv.Close()
+
+	e := newTestExchange(srv.URL)
+	_, err := e.CreateOrder(context.Background(), outbound.CreateExchangeOrderRequest{
+		Pair:         domain.Pair{Base: "BTC", Quote: "USDT"},
+		Type:         domain.OrderTypeLimit,
+		Side:         domain.OrderSideBuy,
+		BaseQuantity: 1,
+		Price:        30000,
+	})
+
+	apiErr := &APIError{}
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "51008", apiErr.Code)
+}
+
+func TestExchange_CreateOrder_Unsupported(t *testing.T) {
+	e := &Exchange{}
+	_, err := e.CreateOrder(context.Background(), outbound.CreateExchangeOrderRequest{
+		Type:          domain.OrderTypeStopLoss,
+		ClosePosition: true,
+	})
+	assert.ErrorIs(t, err, outbound.ErrUnsupported)
+}
+
+type memoryLoader struct {
+	ei *ExchangeInfo
+}
+
+func (l *memoryLoader) Exists(name string) bool { return l.ei != nil }
+
+func (l *memoryLoader) Save(name string, data ExchangeInfo) error {
+	l.ei = &data
+	return nil
+}
+
+func (l *memoryLoader) Read(name string) (ExchangeInfo, error) {
+	if l.ei == nil {
+		return ExchangeInfo{}, os.ErrNotExist
+	}
+	return *l.ei, nil
+}
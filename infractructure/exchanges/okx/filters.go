@@ -0,0 +1,117 @@
+package okx
+
+import (
+	"fmt"
+
+	"github.com/H3Cki/Plotrader/infractructure/exchanges/decimal"
+)
+
+// applyFilters rounds the prices to the tick size and converts the base quantity to a size in contracts
+// rounded down to the lot size, the base quantity is set to the value of the rounded size.
+// Prices that don't apply to the order type are zeroed.
+func applyFilters(ov *orderValues) error {
+	ins := ov.instrument
+
+	if ov.domainType.Limited() {
+		price, err := filterPrice(ins, ov.price)
+		if err != nil {
+			return fmt.Errorf("price: %w", err)
+		}
+		ov.price = price
+	} else {
+		ov.price = 0
+	}
+
+	if ov.domainType.Triggered() {
+		triggerPrice, err := filterPrice(ins, ov.triggerPrice)
+		if err != nil {
+			return fmt.Errorf("trigger price: %w", err)
+		}
+		ov.triggerPrice = triggerPrice
+	} else {
+		ov.triggerPrice = 0
+	}
+
+	maxSz := ins.MaxMktSz
+	if ov.domainType.Limited() {
+		maxSz = ins.MaxLmtSz
+	}
+
+	size, err := filterSize(ins, maxSz, ov.baseQuantity)
+	if err != nil {
+		return err
+	}
+
+	baseQuantity, err := toBaseQuantity(ins, size)
+	if err != nil {
+		return err
+	}
+
+	ov.size = size
+	ov.baseQuantity = baseQuantity
+	return nil
+}
+
+func filterPrice(ins Instrument, price float64) (float64, error) {
+	tickSz, err := decimal.Parse(ins.TickSz)
+	if err != nil {
+		return 0, err
+	}
+	p := decimal.FromFloat(price).Round(tickSz).Float64()
+
+	if p <= 0 {
+		return 0, fmt.Errorf("%f rounds to zero with tick size %s", price, ins.TickSz)
+	}
+
+	return p, nil
+}
+
+func filterSize(ins Instrument, maxSz string, baseQuantity float64) (float64, error) {
+	ctVal, err := decimal.Parse(ins.CtVal)
+	if err != nil {
+		return 0, err
+	}
+
+	if ctVal.IsZero() {
+		return 0, fmt.Errorf("instrument %s has no contract value", ins.InstID)
+	}
+
+	lotSz, err := decimal.Parse(ins.LotSz)
+	if err != nil {
+		return 0, err
+	}
+
+	// the base quantity is rounded down to the value of a lot, the size is then an exact multiple of the lot size
+	base := decimal.FromFloat(baseQuantity).Truncate(lotSz.Mul(ctVal))
+	size := decimal.FromFloat(base.Float64() / ctVal.Float64()).Round(lotSz).Float64()
+
+	min, err := parseOptionalNumber(ins.MinSz)
+	if err != nil {
+		return 0, err
+	}
+
+	max, err := parseOptionalNumber(maxSz)
+	if err != nil {
+		return 0, err
+	}
+
+	if size <= 0 || size < min {
+		return 0, fmt.Errorf("size too small, expected >= %s contracts, got %f", ins.MinSz, baseQuantity/ctVal.Float64())
+	}
+
+	if max != 0 && size > max {
+		return 0, fmt.Errorf("size too large, expected <= %s contracts, got %f", maxSz, baseQuantity/ctVal.Float64())
+	}
+
+	return size, nil
+}
+
+// toBaseQuantity returns the base quantity of size contracts
+func toBaseQuantity(ins Instrument, size float64) (float64, error) {
+	ctVal, err := decimal.Parse(ins.CtVal)
+	if err != nil {
+		return 0, err
+	}
+
+	return decimal.FromFloat(size).Mul(ctVal).Float64(), nil
+}
@@ -0,0 +1,71 @@
+package okx
+
+import (
+	"testing"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_applyFilters(t *testing.T) {
+	eth := Instrument{InstID: "ETH-USDT-SWAP", CtVal: "0.1", TickSz: "0.01", LotSz: "1", MinSz: "1", MaxLmtSz: "1000000", MaxMktSz: "10000"}
+	btc := Instrument{InstID: "BTC-USDT-SWAP", CtVal: "0.01", TickSz: "0.1", LotSz: "0.01", MinSz: "0.01", MaxLmtSz: "100000000", MaxMktSz: "12000"}
+
+	tests := []struct {
+		name     string
+		ov       orderValues
+		wantSize float64
+		want     orderValues
+		wantErr  bool
+	}{
+		{
+			name:     "whole contracts",
+			ov:       orderValues{instrument: eth, domainType: domain.OrderTypeLimit, price: 1500.005, baseQuantity: 0.39},
+			wantSize: 3,
+			want:     orderValues{price: 1500.01, baseQuantity: 0.3},
+		},
+		{
+			name:     "fractional contracts",
+			ov:       orderValues{instrument: btc, domainType: domain.OrderTypeTakeProfitLimit, price: 31000.04, triggerPrice: 30999.96, baseQuantity: 0.012345},
+			wantSize: 1.23,
+			want:     orderValues{price: 31000, triggerPrice: 31000, baseQuantity: 0.0123},
+		},
+		{
+			name:     "market trigger",
+			ov:       orderValues{instrument: btc, domainType: domain.OrderTypeStopLoss, price: 1, triggerPrice: 29000, baseQuantity: 0.0001},
+			wantSize: 0.01,
+			want:     orderValues{triggerPrice: 29000, baseQuantity: 0.0001},
+		},
+		{
+			// 0.3 / 0.1 is 2.9999999999999996 in floats
+			name:     "exact multiple of the contract value",
+			ov:       orderValues{instrument: eth, domainType: domain.OrderTypeLimit, price: 1500, baseQuantity: 0.3},
+			wantSize: 3,
+			want:     orderValues{price: 1500, baseQuantity: 0.3},
+		},
+		{
+			name:    "below one contract",
+			ov:      orderValues{instrument: eth, domainType: domain.OrderTypeLimit, price: 1500, baseQuantity: 0.09},
+			wantErr: true,
+		},
+		{
+			name:    "above max market size",
+			ov:      orderValues{instrument: eth, domainType: domain.OrderTypeStopLoss, triggerPrice: 1500, baseQuantity: 1000.1},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := applyFilters(&tt.ov)
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			if tt.wantErr {
+				return
+			}
+			assert.Equal(t, tt.wantSize, tt.ov.size)
+			assert.Equal(t, tt.want.price, tt.ov.price)
+			assert.Equal(t, tt.want.triggerPrice, tt.ov.triggerPrice)
+			assert.Equal(t, tt.want.baseQuantity, tt.ov.baseQuantity)
+		})
+	}
+}
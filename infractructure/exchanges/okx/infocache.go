@@ -0,0 +1,37 @@
+package okx
+
+import (
+	"context"
+	"time"
+
+	"github.com/H3Cki/Plotrader/core/outbound"
+	"github.com/H3Cki/Plotrader/infractructure/exchanges/infocache"
+	"go.uber.org/zap"
+)
+
+// infoCache is the instrument index of an endpoint shared by the exchanges of the endpoint
+type infoCache = infocache.Cache[ExchangeInfo, Instrument]
+
+// infoCaches holds a cache per exchange info file, live and demo instruments are cached separately
+var infoCaches = infocache.NewPool[ExchangeInfo, Instrument]()
+
+func infoCacheConfig(logger *zap.SugaredLogger, c *client, loader outbound.FileLoader[ExchangeInfo], fileName string) infocache.Config[ExchangeInfo, Instrument] {
+	return infocache.Config[ExchangeInfo, Instrument]{
+		Logger:   logger,
+		Loader:   loader,
+		FileName: fileName,
+		MaxAge:   maxEiAge,
+		Fetch: func(ctx context.Context) (ExchangeInfo, error) {
+			return getExchangeInfo(ctx, c)
+		},
+		Index: func(ei ExchangeInfo) map[string]Instrument {
+			instruments := make(map[string]Instrument, len(ei.Instruments))
+			for _, i := range ei.Instruments {
+				instruments[i.InstID] = i
+			}
+			return instruments
+		},
+		// UpdateTime is the fetch time in ms
+		FetchedAt: func(ei ExchangeInfo) time.Time { return time.UnixMilli(ei.UpdateTime) },
+	}
+}
@@ -0,0 +1,220 @@
+package okx
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/H3Cki/Plotrader/core/domain"
+	"github.com/H3Cki/Plotrader/core/outbound"
+)
+
+// Parse functions
+
+func pairToInstID(p domain.Pair) string {
+	return p.Base + "-" + p.Quote + "-" + instType
+}
+
+func orderSide(side domain.OrderSide) (string, error) {
+	switch side {
+	case domain.OrderSideBuy:
+		return "buy", nil
+	case domain.OrderSideSell:
+		return "sell", nil
+	}
+	return "", fmt.Errorf("unsupported order side: %s", side)
+}
+
+// ordType returns the okx order type, the time in force of limit orders is a part of it,
+// triggered orders are placed as trigger algo orders which only support GTC.
+func ordType(t domain.OrderType, tif domain.TimeInForce) (string, error) {
+	switch t {
+	case domain.OrderTypeLimit:
+		switch tif {
+		case "", domain.TimeInForceGTC:
+			return "limit", nil
+		case domain.TimeInForceIOC:
+			return "ioc", nil
+		case domain.TimeInForceFOK:
+			return "fok", nil
+		case domain.TimeInForceGTX:
+			return "post_only", nil
+		}
+		return "", fmt.Errorf("unsupported time in force: %s", tif)
+	case domain.OrderTypeStopLoss, domain.OrderTypeTakeProfit:
+		return "trigger", nil
+	case domain.OrderTypeStopLossLimit, domain.OrderTypeTakeProfitLimit:
+		if tif != "" && tif != domain.TimeInForceGTC {
+			return "", fmt.Errorf("time in force %s of %s orders: %w", tif, t, outbound.ErrUnsupported)
+		}
+		return "trigger", nil
+	}
+	return "", fmt.Errorf("unsupported order type: %s", t)
+}
+
+// triggerPxType returns the price that triggers algo orders, empty uses the exchange default
+func triggerPxType(wt domain.WorkingType) string {
+	switch wt {
+	case domain.WorkingTypeMarkPrice:
+		return "mark"
+	case domain.WorkingTypeContractPrice:
+		return "last"
+	}
+	return ""
+}
+
+// Conversions to domain values
+
+func toDomainOrderStatus(state string) (domain.OrderStatus, error) {
+	switch state {
+	case "live", "partially_filled":
+		return domain.OrderStatusActive, nil
+	case "filled":
+		return domain.OrderStatusDone, nil
+	case "canceled", "mmp_canceled":
+		return domain.OrderStatusCanceled, nil
+	}
+	return "", fmt.Errorf("unknown order state: %s", state)
+}
+
+// toDomainAlgoStatus returns the status of a trigger order, effective orders are active until the placed order is done
+func toDomainAlgoStatus(state string) (domain.OrderStatus, error) {
+	switch state {
+	case "live", "pause", "partially_effective", "effective":
+		return domain.OrderStatusActive, nil
+	case "canceled", "order_failed":
+		return domain.OrderStatusCanceled, nil
+	}
+	return "", fmt.Errorf("unknown algo order state: %s", state)
+}
+
+func toDomainPositionSide(posSide string) domain.PositionSide {
+	switch posSide {
+	case "long":
+		return domain.PositionSideLong
+	case "short":
+		return domain.PositionSideShort
+	}
+	return ""
+}
+
+func toDomainWorkingType(triggerPxType string) domain.WorkingType {
+	switch triggerPxType {
+	case "mark":
+		return domain.WorkingTypeMarkPrice
+	case "last":
+		return domain.WorkingTypeContractPrice
+	}
+	return ""
+}
+
+func toDomainTimeInForce(ordType string) domain.TimeInForce {
+	switch ordType {
+	case "limit":
+		return domain.TimeInForceGTC
+	case "ioc":
+		return domain.TimeInForceIOC
+	case "fok":
+		return domain.TimeInForceFOK
+	case "post_only":
+		return domain.TimeInForceGTX
+	}
+	return ""
+}
+
+func orderToOrder(o order, ins Instrument) (*domain.ExchangeOrder, error) {
+	status, err := toDomainOrderStatus(o.State)
+	if err != nil {
+		return nil, err
+	}
+
+	price, err := parseOptionalNumber(o.Px)
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := parseOptionalNumber(o.Sz)
+	if err != nil {
+		return nil, err
+	}
+
+	baseQuantity, err := toBaseQuantity(ins, size)
+	if err != nil {
+		return nil, err
+	}
+
+	fillPrice, err := parseOptionalNumber(o.AvgPx)
+	if err != nil {
+		return nil, err
+	}
+
+	orderType := domain.OrderTypeLimit
+	if o.OrdType == "market" {
+		orderType = domain.OrderTypeMarket
+	}
+
+	return &domain.ExchangeOrder{
+		ID:           o.OrdID,
+		Status:       status,
+		Type:         string(orderType),
+		Symbol:       o.InstID,
+		Side:         o.Side,
+		PositionSide: string(toDomainPositionSide(o.PosSide)),
+		Price:        price,
+		BaseQuantity: baseQuantity,
+		FillPrice:    fillPrice,
+		ReduceOnly:   o.ReduceOnly == "true",
+		TimeInForce:  string(toDomainTimeInForce(o.OrdType)),
+	}, nil
+}
+
+// algoToOrder converts a trigger order of type t, its price is -1 if the triggered order is a market order
+func algoToOrder(a algoOrder, ins Instrument, t domain.OrderType) (*domain.ExchangeOrder, error) {
+	status, err := toDomainAlgoStatus(a.State)
+	if err != nil {
+		return nil, err
+	}
+
+	triggerPrice, err := parseOptionalNumber(a.TriggerPx)
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := parseOptionalNumber(a.Sz)
+	if err != nil {
+		return nil, err
+	}
+
+	baseQuantity, err := toBaseQuantity(ins, size)
+	if err != nil {
+		return nil, err
+	}
+
+	price := 0.0
+	if a.OrdPx != "-1" {
+		if price, err = parseOptionalNumber(a.OrdPx); err != nil {
+			return nil, err
+		}
+	}
+
+	return &domain.ExchangeOrder{
+		ID:           a.AlgoID,
+		Status:       status,
+		Type:         string(t),
+		Symbol:       a.InstID,
+		Side:         a.Side,
+		PositionSide: string(toDomainPositionSide(a.PosSide)),
+		Price:        price,
+		StopPrice:    triggerPrice,
+		BaseQuantity: baseQuantity,
+		ReduceOnly:   a.ReduceOnly == "true",
+		WorkingType:  string(toDomainWorkingType(a.TriggerPxType)),
+	}, nil
+}
+
+// parseOptionalNumber parses numbers that are not set for every order, empty strings are zero
+func parseOptionalNumber(s string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
@@ -22,29 +22,42 @@ type Follow struct {
 	Interval     time.Duration
 	WebhookURL   string
 	OrderIDs     []string
+	Leverage     int
+	MarginType   domain.MarginType
+	// RiskViolation is the limit that blocked orders of the follow
+	RiskViolation *domain.RiskViolation     `gorm:"serializer:json"`
+	Transitions   []domain.FollowTransition `gorm:"serializer:json"`
 }
 
 func followFromDomain(follow domain.Follow) *Follow {
 	return &Follow{
-		ID:           follow.ID,
-		Status:       follow.Status,
-		ExchangeHash: follow.ExchangeHash,
-		Pair:         Pair{Base: follow.Pair.Base, Quote: follow.Pair.Quote},
-		Interval:     follow.Interval,
-		WebhookURL:   follow.WebhookURL,
-		OrderIDs:     follow.OrderIDs,
+		ID:            follow.ID,
+		Status:        follow.Status,
+		ExchangeHash:  follow.ExchangeHash,
+		Pair:          Pair{Base: follow.Pair.Base, Quote: follow.Pair.Quote},
+		Interval:      follow.Interval,
+		WebhookURL:    follow.WebhookURL,
+		OrderIDs:      follow.OrderIDs,
+		Leverage:      follow.Leverage,
+		MarginType:    follow.MarginType,
+		RiskViolation: follow.RiskViolation,
+		Transitions:   follow.Transitions,
 	}
 }
 
 func (f *Follow) domain() domain.Follow {
 	return domain.Follow{
-		ID:           f.ID,
-		Status:       f.Status,
-		ExchangeHash: f.ExchangeHash,
-		Pair:         domain.Pair{Base: f.Pair.Base, Quote: f.Pair.Quote},
-		Interval:     f.Interval,
-		WebhookURL:   f.WebhookURL,
-		OrderIDs:     f.OrderIDs,
+		ID:            f.ID,
+		Status:        f.Status,
+		ExchangeHash:  f.ExchangeHash,
+		Pair:          domain.Pair{Base: f.Pair.Base, Quote: f.Pair.Quote},
+		Interval:      f.Interval,
+		WebhookURL:    f.WebhookURL,
+		OrderIDs:      f.OrderIDs,
+		Leverage:      f.Leverage,
+		MarginType:    f.MarginType,
+		RiskViolation: f.RiskViolation,
+		Transitions:   f.Transitions,
 	}
 }
 
@@ -60,15 +73,20 @@ type Order struct {
 	Status        domain.OrderStatus
 	Type          domain.OrderType
 	Side          domain.OrderSide
+	PositionSide  domain.PositionSide
 	QuoteQuantity float64
 	BaseQuantity  float64
 	ClosePosition bool
 	ReduceOnly    bool
+	TimeInForce   domain.TimeInForce
+	WorkingType   domain.WorkingType
 	Relations     []domain.StatusRelation
 	Plot          geometry.Plot
+	PlotState     geometry.PlotState
 
 	ExchangeHash  string
 	ExchangeOrder *domain.ExchangeOrder
+	Placements    int
 }
 
 func orderFromDomain(order domain.Order) *Order {
@@ -79,14 +97,19 @@ func orderFromDomain(order domain.Order) *Order {
 		Status:        order.Status,
 		Type:          order.Type,
 		Side:          order.Side,
+		PositionSide:  order.PositionSide,
 		QuoteQuantity: order.BaseQuantity,
 		BaseQuantity:  order.QuoteQuantity,
 		ClosePosition: order.ClosePosition,
 		ReduceOnly:    order.ReduceOnly,
+		TimeInForce:   order.TimeInForce,
+		WorkingType:   order.WorkingType,
 		Relations:     order.Relations,
 		Plot:          order.Plot,
+		PlotState:     order.PlotState,
 		ExchangeHash:  order.ExchangeHash,
 		ExchangeOrder: order.ExchangeOrder,
+		Placements:    order.Placements,
 	}
 }
 
@@ -98,13 +121,18 @@ func (o *Order) domain() domain.Order {
 		Status:        o.Status,
 		Type:          o.Type,
 		Side:          o.Side,
+		PositionSide:  o.PositionSide,
 		QuoteQuantity: o.QuoteQuantity,
 		BaseQuantity:  o.BaseQuantity,
 		ClosePosition: o.ClosePosition,
 		ReduceOnly:    o.ReduceOnly,
+		TimeInForce:   o.TimeInForce,
+		WorkingType:   o.WorkingType,
 		Relations:     o.Relations,
 		Plot:          o.Plot,
+		PlotState:     o.PlotState,
 		ExchangeHash:  o.ExchangeHash,
 		ExchangeOrder: o.ExchangeOrder,
+		Placements:    o.Placements,
 	}
 }
@@ -65,7 +65,7 @@ func (r *Repository) GetFollow(ctx context.Context, req outbound.GetFollowReques
 
 func (r *Repository) UpdateFollow(ctx context.Context, req outbound.UpdateFollowRequest) error {
 	col := r.followsCol()
-	_, err := col.ReplaceOne(ctx, bson.D{{Key: "id", Value: req.Follow}}, req.Follow)
+	_, err := col.ReplaceOne(ctx, bson.D{{Key: "id", Value: req.Follow.ID}}, req.Follow)
 	return err
 }
 
@@ -23,6 +23,7 @@ func main() {
 		DefaultCommand: cmd.RESTCommand.Name,
 		Commands: []*cli.Command{
 			cmd.RESTCommand,
+			cmd.CrossingsCommand,
 		},
 	}
 
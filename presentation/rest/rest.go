@@ -16,20 +16,47 @@ var (
 	exchangEnvVarHeader  = "X-Exchange-EnvVar"
 )
 
-func New(svc inbound.FollowService, addr string) http.Server {
+var (
+	plotTypesPath     = "/plots/types"
+	plotCrossingsPath = "/plots/crossings"
+	// followTransitionsPath is /follows/{followID}/transitions
+	followsPath           = "/follows/"
+	followTransitionsPath = "/transitions"
+)
+
+func New(svc inbound.FollowService, plotSvc inbound.PlotService, addr string) http.Server {
 	return http.Server{
 		Addr:    addr,
-		Handler: &handler{svc: svc},
+		Handler: &handler{svc: svc, plotSvc: plotSvc},
 	}
 }
 
 type handler struct {
-	svc inbound.FollowService
+	svc     inbound.FollowService
+	plotSvc inbound.PlotService
 }
 
 func (h *handler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 	switch r.Method {
+	case http.MethodGet:
+		switch r.URL.Path {
+		case plotTypesPath:
+			h.listPlotTypes(rw, r)
+		default:
+			followID, ok := strings.CutPrefix(r.URL.Path, followsPath)
+			followID, transitions := strings.CutSuffix(followID, followTransitionsPath)
+			if !ok || !transitions || followID == "" || strings.Contains(followID, "/") {
+				rw.WriteHeader(http.StatusNotFound)
+				return
+			}
+			h.listFollowTransitions(rw, r, followID)
+		}
 	case http.MethodPost:
+		if r.URL.Path == plotCrossingsPath {
+			h.findCrossings(rw, r)
+			return
+		}
+
 		req := inbound.CreateFollowRequest{}
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			rw.WriteHeader(http.StatusInternalServerError)
@@ -58,6 +85,51 @@ func (h *handler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (h *handler) listPlotTypes(rw http.ResponseWriter, r *http.Request) {
+	resp, err := h.plotSvc.ListPlotTypes(r.Context(), inbound.ListPlotTypesRequest{})
+	if err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		rw.Write([]byte(err.Error()))
+		return
+	}
+	respBytes, _ := json.Marshal(resp)
+	rw.Header().Set("Content-Type", "application/json")
+	rw.Write(respBytes)
+}
+
+func (h *handler) listFollowTransitions(rw http.ResponseWriter, r *http.Request, followID string) {
+	resp, err := h.svc.ListFollowTransitions(r.Context(), inbound.ListFollowTransitionsRequest{
+		FollowID: followID,
+	})
+	if err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		rw.Write([]byte(err.Error()))
+		return
+	}
+	respBytes, _ := json.Marshal(resp)
+	rw.Header().Set("Content-Type", "application/json")
+	rw.Write(respBytes)
+}
+
+func (h *handler) findCrossings(rw http.ResponseWriter, r *http.Request) {
+	req := inbound.FindCrossingsRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(err.Error()))
+		return
+	}
+
+	resp, err := h.plotSvc.FindCrossings(r.Context(), req)
+	if err != nil {
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(err.Error()))
+		return
+	}
+	respBytes, _ := json.Marshal(resp)
+	rw.Header().Set("Content-Type", "application/json")
+	rw.Write(respBytes)
+}
+
 func exchangeFromReq(r *http.Request) (inbound.Exchange, error) {
 	cfgStr := r.Header.Get(exchangeConfigHeader)
 	cfgStr = strings.Trim(cfgStr, "\"")